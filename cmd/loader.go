@@ -25,9 +25,12 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/vhive-serverless/loader/pkg/generator"
@@ -54,6 +57,7 @@ var (
 	iatGeneration = flag.Bool("iatGeneration", false, "Generate IATs only or run invocations as well")
 	iatFromFile   = flag.Bool("generated", false, "True if iats were already generated")
 	dryRun        = flag.Bool("dryRun", false, "Dry run mode - do not deploy functions or generate invocations")
+	resume        = flag.Bool("resume", false, "Resume the experiment from the last checkpoint in the output directory")
 )
 
 func init() {
@@ -96,20 +100,95 @@ func main() {
 		"AWSLambda",
 		"Dirigent",
 		"Dirigent-Dandelion",
+		"Local",
+		"GenericHTTP",
 	}
 
 	if !slices.Contains(supportedPlatforms, cfg.Platform) {
 		log.Fatal("Unsupported platform!")
 	}
+	// the responses of asynchronous invocations are polled from Dirigent, while OpenWhisk activations are collected
+	// after the experiment regardless of AsyncMode
+	if cfg.AsyncMode && cfg.Platform != "Dirigent" && cfg.Platform != "Dirigent-Dandelion" {
+		log.Fatal("AsyncMode is supported on Dirigent only.")
+	}
+
+	supportedSchedulers := []string{
+		"",
+		common.PerFunctionScheduler,
+		common.CentralizedScheduler,
+	}
+
+	if !slices.Contains(supportedSchedulers, cfg.InvocationScheduler) {
+		log.Fatal("Unsupported invocation scheduler!")
+	}
+	if cfg.SchedulerSpinMicroseconds < 0 {
+		log.Fatal("SchedulerSpinMicroseconds cannot be negative.")
+	}
+
+	supportedLoadModes := []string{
+		"",
+		common.OpenLoopMode,
+		common.ClosedLoopMode,
+	}
+
+	if !slices.Contains(supportedLoadModes, cfg.LoadMode) {
+		log.Fatal("Unsupported load mode!")
+	}
+
+	if cfg.TimeScale < 0 {
+		log.Fatal("Time scale has to be positive.")
+	}
+	if (cfg.TracePath == "RPS" || cfg.ReplayPath != "") && cfg.TimeScale != 0 && cfg.TimeScale != 1 {
+		log.Fatal("Time scale is supported in trace mode only.")
+	}
+	if cfg.ReplayPath != "" && cfg.DAGMode {
+		log.Fatal("Replay mode does not support DAG mode.")
+	}
+	if cfg.EnableCheckpointing && cfg.LoadMode == common.ClosedLoopMode {
+		log.Fatal("Checkpointing is not supported in closed-loop mode.")
+	}
+	if *resume && (*iatGeneration || *iatFromFile) {
+		log.Fatal("Resuming an experiment cannot be combined with generating or reading IATs from a file.")
+	}
+
+	supportedDandelionKernels := []string{
+		"",
+		common.DandelionBusyLoopKernel,
+		common.DandelionMatMulKernel,
+		common.DandelionEchoKernel,
+	}
+
+	if cfg.Platform == "Dirigent-Dandelion" && !slices.Contains(supportedDandelionKernels, cfg.DandelionKernel) {
+		log.Fatal("Unsupported Dandelion kernel!")
+	}
+	if cfg.DandelionFunctionName != "" && cfg.DandelionCompositionName != "" {
+		log.Fatal("DandelionFunctionName and DandelionCompositionName cannot be set at the same time.")
+	}
+
+	if cfg.Platform == "Local" && cfg.EnableTLS {
+		log.Fatal("The Local platform does not support TLS.")
+	}
 
 	if cfg.Platform == "Knative" {
 		common.CheckCPULimit(cfg.CPULimit)
 	}
 
-	if cfg.TracePath == "RPS" {
-		runRPSMode(&cfg, *iatFromFile, *iatGeneration)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	go func() {
+		<-ctx.Done()
+		// restore the default behaviour so that a second signal terminates the loader immediately
+		stop()
+	}()
+
+	if cfg.ReplayPath != "" {
+		runReplayMode(ctx, &cfg)
+	} else if cfg.TracePath == "RPS" {
+		runRPSMode(ctx, &cfg, *iatFromFile, *iatGeneration)
 	} else {
-		runTraceMode(&cfg, *iatFromFile, *iatGeneration)
+		runTraceMode(ctx, &cfg, *iatFromFile, *iatGeneration)
 	}
 }
 
@@ -151,7 +230,7 @@ func parseYAMLSpecification(cfg *config.LoaderConfiguration) string {
 	case "firecracker":
 		return "workloads/firecracker/trace_func_go.yaml"
 	default:
-		if cfg.Platform != "Dirigent" && cfg.Platform != "Dirigent-Dandelion" {
+		if cfg.Platform != "Dirigent" && cfg.Platform != "Dirigent-Dandelion" && cfg.Platform != "Local" && cfg.Platform != "GenericHTTP" {
 			log.Fatal("Invalid 'YAMLSelector' parameter.")
 		}
 	}
@@ -172,13 +251,14 @@ func parseTraceGranularity(cfg *config.LoaderConfiguration) common.TraceGranular
 	return common.MinuteGranularity
 }
 
-func runTraceMode(cfg *config.LoaderConfiguration, readIATFromFile bool, writeIATsToFile bool) {
+func runTraceMode(ctx context.Context, cfg *config.LoaderConfiguration, readIATFromFile bool, writeIATsToFile bool) {
 	durationToParse := determineDurationToParse(cfg.ExperimentDuration, cfg.WarmupDuration)
 	yamlPath := parseYAMLSpecification(cfg)
 
 	// Azure trace parsing
 	traceParser := trace.NewAzureParser(cfg.TracePath, durationToParse)
 	functions := traceParser.Parse()
+	trace.ApplyPayloadSizes(functions, cfg.PayloadSizes)
 
 	// Dirigent metadata parsing
 	dirigentMetadataParser := trace.NewDirigentMetadataParser(cfg.TracePath, functions, yamlPath, cfg.Platform)
@@ -213,12 +293,60 @@ func runTraceMode(cfg *config.LoaderConfiguration, readIATFromFile bool, writeIA
 
 	log.Infof("Using %s as a service YAML specification file.\n", experimentDriver.Configuration.YAMLPath)
 
-	experimentDriver.GenerateSpecification()
-	experimentDriver.ReadOrWriteFileSpecification(writeIATsToFile, readIATFromFile)
-	experimentDriver.RunExperiment()
+	if *resume {
+		experimentDriver.ResumeFromCheckpoint()
+	} else {
+		experimentDriver.GenerateSpecification()
+		experimentDriver.ReadOrWriteFileSpecification(writeIATsToFile, readIATFromFile)
+	}
+	experimentDriver.RunExperiment(ctx)
+}
+
+// runReplayMode issues the invocations of a previous experiment as they are, bypassing the trace parser and the
+// specification generator
+func runReplayMode(ctx context.Context, cfg *config.LoaderConfiguration) {
+	durationToParse := determineDurationToParse(cfg.ExperimentDuration, cfg.WarmupDuration)
+	yamlPath := parseYAMLSpecification(cfg)
+
+	replayParser := trace.NewReplayParser(cfg.ReplayPath, durationToParse)
+	functions := replayParser.Parse()
+
+	// Dirigent metadata parsing
+	dirigentMetadataParser := trace.NewDirigentMetadataParser(cfg.TracePath, functions, yamlPath, cfg.Platform)
+	dirigentMetadataParser.Parse()
+
+	log.Infof("Replay schedule contains the following %d functions:\n", len(functions))
+	for _, function := range functions {
+		fmt.Printf("\t%s\n", function.Name)
+	}
+
+	experimentDriver := driver.NewDriver(&config.Configuration{
+		LoaderConfiguration:  cfg,
+		FailureConfiguration: config.ReadFailureConfiguration(*failurePath),
+
+		TraceGranularity: common.MinuteGranularity,
+		TraceDuration:    durationToParse,
+
+		YAMLPath: yamlPath,
+		TestMode: false,
+
+		Functions: functions,
+	})
+
+	// Skip experiments execution during dry run mode
+	if *dryRun {
+		return
+	}
+
+	log.Infof("Using %s as a service YAML specification file.\n", experimentDriver.Configuration.YAMLPath)
+
+	if *resume {
+		experimentDriver.ResumeFromCheckpoint()
+	}
+	experimentDriver.RunExperiment(ctx)
 }
 
-func runRPSMode(cfg *config.LoaderConfiguration, readIATFromFile bool, writeIATsToFile bool) {
+func runRPSMode(ctx context.Context, cfg *config.LoaderConfiguration, readIATFromFile bool, writeIATsToFile bool) {
 	experimentDuration := determineDurationToParse(cfg.ExperimentDuration, cfg.WarmupDuration)
 
 	rpsTarget := cfg.RpsTarget
@@ -227,8 +355,18 @@ func runRPSMode(cfg *config.LoaderConfiguration, readIATFromFile bool, writeIATs
 	warmStartRPS := rpsTarget * (100 - coldStartPercentage) / 100
 	coldStartRPS := rpsTarget * coldStartPercentage / 100
 
-	warmFunction, warmStartCount := generator.GenerateWarmStartFunction(experimentDuration, warmStartRPS)
-	coldFunctions, coldStartCount := generator.GenerateColdStartFunctions(experimentDuration, coldStartRPS, cfg.RpsCooldownSeconds)
+	var warmFunction common.IATArray
+	var warmStartCount []int
+	var coldFunctions []common.IATArray
+	var coldStartCount [][]int
+
+	if len(cfg.RpsStages) > 0 {
+		warmFunction, warmStartCount = generator.GenerateStagedWarmStartFunction(experimentDuration, cfg.RpsStages, (100-coldStartPercentage)/100)
+		coldFunctions, coldStartCount = generator.GenerateStagedColdStartFunctions(experimentDuration, cfg.RpsStages, coldStartPercentage/100, cfg.RpsCooldownSeconds)
+	} else {
+		warmFunction, warmStartCount = generator.GenerateWarmStartFunction(experimentDuration, warmStartRPS)
+		coldFunctions, coldStartCount = generator.GenerateColdStartFunctions(experimentDuration, coldStartRPS, cfg.RpsCooldownSeconds)
+	}
 
 	experimentDriver := driver.NewDriver(&config.Configuration{
 		LoaderConfiguration: cfg,
@@ -244,6 +382,10 @@ func runRPSMode(cfg *config.LoaderConfiguration, readIATFromFile bool, writeIATs
 		return
 	}
 
-	experimentDriver.ReadOrWriteFileSpecification(writeIATsToFile, readIATFromFile)
-	experimentDriver.RunExperiment()
+	if *resume {
+		experimentDriver.ResumeFromCheckpoint()
+	} else {
+		experimentDriver.ReadOrWriteFileSpecification(writeIATsToFile, readIATFromFile)
+	}
+	experimentDriver.RunExperiment(ctx)
 }
@@ -48,6 +48,10 @@ const (
 	OvercommitmentRatio = 10
 )
 
+// MaxPayloadSizeBytes Payloads are kept below the 4 MiB default message size limit of gRPC, leaving room for the other
+// fields of the message
+const MaxPayloadSizeBytes = 4_000_000
+
 type IatDistribution int
 
 const (
@@ -56,6 +60,41 @@ const (
 	Equidistant
 )
 
+// Dandelion compute kernels
+const (
+	// DandelionBusyLoopKernel spins for the requested runtime
+	DandelionBusyLoopKernel string = "busyloop"
+	// DandelionMatMulKernel multiplies a square matrix of the requested size
+	DandelionMatMulKernel string = "matmul"
+	// DandelionEchoKernel returns its input of the requested size
+	DandelionEchoKernel string = "echo"
+
+	DefaultDandelionInvocationPath = "/hot/matmul"
+	// DefaultDandelionRuntimeMultiplier Factor the runtime passed to the busy loop kernel is multiplied with, which
+	// the images predating the fix of the input parsing expect
+	DefaultDandelionRuntimeMultiplier = 10
+)
+
+// Polling of the responses of asynchronous invocations
+const (
+	DefaultAsyncPollConcurrency      = 50
+	DefaultAsyncPollInitialBackoffMs = 100
+	DefaultAsyncPollMaxBackoffMs     = 5_000
+)
+
+// Arrival models of the invocations of a function, selected by the trigger of the function
+const (
+	// PeriodicArrivals spreads the invocations of every minute evenly, starting at the beginning of the minute
+	PeriodicArrivals string = "periodic"
+	// DistributionArrivals draws the invocations from the configured IAT distribution
+	DistributionArrivals string = "distribution"
+	// BurstArrivals draws bursts of invocations from the configured IAT distribution
+	BurstArrivals string = "burst"
+
+	// DefaultBurstSize Maximum number of invocations of a burst, as in the batches of Azure Functions queue triggers
+	DefaultBurstSize = 16
+)
+
 type TraceGranularity int
 
 const (
@@ -70,6 +109,17 @@ const (
 	ExecutionPhase ExperimentPhase = 2
 )
 
+func (p ExperimentPhase) String() string {
+	switch p {
+	case WarmupPhase:
+		return "warmup"
+	case ExecutionPhase:
+		return "execution"
+	default:
+		return "unknown"
+	}
+}
+
 const (
 	// RequestedVsIssuedWarnThreshold Print warning on stdout if the relative difference between requested
 	// and issued number of invocations is higher than this threshold
@@ -86,6 +136,88 @@ const (
 	FailedTerminateThreshold = 0.5
 )
 
+// Invocation schedulers
+const (
+	// PerFunctionScheduler runs one goroutine per function that sleeps between consecutive invocations
+	PerFunctionScheduler string = "per_function"
+	// CentralizedScheduler dispatches invocations of all functions from a single timer heap onto a worker pool
+	CentralizedScheduler string = "centralized"
+
+	// DefaultSchedulerWorkerPoolSize Number of workers issuing invocations with the centralized scheduler
+	DefaultSchedulerWorkerPoolSize = 4096
+)
+
+// RPS load-profile stages
+const (
+	RpsStageConstant string = "constant"
+	RpsStageRamp     string = "ramp"
+	RpsStageStep     string = "step"
+	RpsStageSpike    string = "spike"
+	RpsStageSine     string = "sine"
+)
+
+// Load modes
+const (
+	// OpenLoopMode issues invocations at the times given by the IATs regardless of how many of them are outstanding
+	OpenLoopMode string = "open_loop"
+	// ClosedLoopMode issues a new invocation only once the previous one of the same virtual user has returned
+	ClosedLoopMode string = "closed_loop"
+
+	// DefaultClosedLoopVirtualUsers Number of virtual users per function in closed-loop mode
+	DefaultClosedLoopVirtualUsers = 1
+)
+
+// Failure classes an invocation can be retried on
+const (
+	RetryOnConnectionTimeout string = "connection_timeout"
+	RetryOnFunctionTimeout   string = "function_timeout"
+	RetryOnServerError       string = "http_5xx"
+	RetryOnTooManyRequests   string = "http_429"
+)
+
+// gRPC connection modes
+const (
+	// GRPCConnectionPerCall establishes a fresh connection for every invocation
+	GRPCConnectionPerCall string = "per_call"
+	// GRPCConnectionPooled reuses connections to the same endpoint across invocations
+	GRPCConnectionPooled string = "pooled"
+)
+
+// Authentication schemes
+const (
+	AuthSchemeBearer string = "bearer"
+	AuthSchemeAPIKey string = "api_key"
+
+	// DefaultAPIKeyHeader Header carrying the API key unless configured otherwise
+	DefaultAPIKeyHeader = "X-API-Key"
+)
+
+// OpenWhisk invocation modes
+const (
+	// OpenWhiskBlocking waits for the activation to complete within the invocation
+	OpenWhiskBlocking string = "blocking"
+	// OpenWhiskNonBlocking only submits the activation, whose record is fetched once all the invocations are issued
+	OpenWhiskNonBlocking string = "non_blocking"
+
+	// OpenWhiskDefaultNamespace Namespace of the user the credentials belong to
+	OpenWhiskDefaultNamespace = "_"
+	// OpenWhiskActionKind Runtime of the actions deployed by the loader
+	OpenWhiskActionKind = "go:1.17"
+)
+
+// Invoker middlewares
+const (
+	LoggingMiddleware        string = "logging"
+	RateLimitMiddleware      string = "rate_limit"
+	TracingMiddleware        string = "tracing"
+	ValidationMiddleware     string = "validation"
+	FaultInjectionMiddleware string = "fault_injection"
+	RetryMiddleware          string = "retry"
+)
+
+// DefaultGracefulShutdownTimeoutSeconds Time to wait for the invocations in flight once the experiment gets cancelled
+const DefaultGracefulShutdownTimeoutSeconds = 60
+
 type RuntimeAssertType int
 
 const (
@@ -35,6 +35,10 @@ type ProbabilisticDuration []float64
 type RuntimeSpecification struct {
 	Runtime int
 	Memory  int
+
+	// RequestSize and ResponseSize Payload sizes in bytes, which are zero unless payloads are modelled
+	RequestSize  int
+	ResponseSize int
 }
 
 type RuntimeSpecificationArray []RuntimeSpecification
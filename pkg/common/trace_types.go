@@ -35,6 +35,12 @@ type FunctionInvocationStats struct {
 	Invocations []int
 }
 
+// ArrivalModel describes how the invocations of the functions with a given trigger arrive within a minute
+type ArrivalModel struct {
+	Model     string `json:"Model"`
+	BurstSize int    `json:"BurstSize"`
+}
+
 type FunctionRuntimeStats struct {
 	HashOwner    string `csv:"HashOwner"`
 	HashApp      string `csv:"HashApp"`
@@ -72,6 +78,25 @@ type FunctionMemoryStats struct {
 	Percentile100 float64 `csv:"AverageAllocatedMb_pct100"`
 }
 
+// FunctionPayloadStats Percentiles of the request and response payload sizes of a function in bytes
+type FunctionPayloadStats struct {
+	HashOwner    string `csv:"HashOwner" json:"HashOwner"`
+	HashApp      string `csv:"HashApp" json:"HashApp"`
+	HashFunction string `csv:"HashFunction" json:"HashFunction"`
+
+	RequestPercentile0   float64 `csv:"RequestBytes_pct0" json:"RequestBytes_pct0"`
+	RequestPercentile25  float64 `csv:"RequestBytes_pct25" json:"RequestBytes_pct25"`
+	RequestPercentile50  float64 `csv:"RequestBytes_pct50" json:"RequestBytes_pct50"`
+	RequestPercentile75  float64 `csv:"RequestBytes_pct75" json:"RequestBytes_pct75"`
+	RequestPercentile100 float64 `csv:"RequestBytes_pct100" json:"RequestBytes_pct100"`
+
+	ResponsePercentile0   float64 `csv:"ResponseBytes_pct0" json:"ResponseBytes_pct0"`
+	ResponsePercentile25  float64 `csv:"ResponseBytes_pct25" json:"ResponseBytes_pct25"`
+	ResponsePercentile50  float64 `csv:"ResponseBytes_pct50" json:"ResponseBytes_pct50"`
+	ResponsePercentile75  float64 `csv:"ResponseBytes_pct75" json:"ResponseBytes_pct75"`
+	ResponsePercentile100 float64 `csv:"ResponseBytes_pct100" json:"ResponseBytes_pct100"`
+}
+
 type DirigentMetadata struct {
 	HashFunction        string   `json:"HashFunction"`
 	Image               string   `json:"Image"`
@@ -95,6 +120,7 @@ type Function struct {
 	InvocationStats  *FunctionInvocationStats
 	RuntimeStats     *FunctionRuntimeStats
 	MemoryStats      *FunctionMemoryStats
+	PayloadStats     *FunctionPayloadStats
 	DirigentMetadata *DirigentMetadata
 
 	ColdStartBusyLoopMs int
@@ -1,6 +1,8 @@
 package config
 
 import (
+	"time"
+
 	"github.com/vhive-serverless/loader/pkg/common"
 )
 
@@ -27,3 +29,17 @@ func (c *Configuration) WithWarmup() bool {
 		return false
 	}
 }
+
+// TimeScale returns the factor the timeline of the trace is multiplied by, where values below one replay the trace faster
+func (c *Configuration) TimeScale() float64 {
+	if c.LoaderConfiguration.TimeScale > 0 {
+		return c.LoaderConfiguration.TimeScale
+	}
+
+	return 1
+}
+
+// ScaledDuration converts a duration of the trace to the wall-clock duration it takes in the experiment
+func (c *Configuration) ScaledDuration(duration time.Duration) time.Duration {
+	return time.Duration(float64(duration) * c.TimeScale())
+}
@@ -29,6 +29,7 @@ import (
 	"os"
 
 	log "github.com/sirupsen/logrus"
+	"github.com/vhive-serverless/loader/pkg/common"
 )
 
 type FailureConfiguration struct {
@@ -39,6 +40,30 @@ type FailureConfiguration struct {
 	FailNode      string `json:"FailNode"`
 }
 
+// RpsStage describes how the request rate evolves over a part of an RPS experiment
+type RpsStage struct {
+	Type            string  `json:"Type"`
+	DurationSeconds int     `json:"DurationSeconds"`
+	StartRps        float64 `json:"StartRps"`
+	TargetRps       float64 `json:"TargetRps"`
+
+	// Steps Number of levels of a step stage, from StartRps to TargetRps
+	Steps int `json:"Steps"`
+	// SpikeStartSeconds and SpikeDurationSeconds Part of a spike stage during which TargetRps is issued
+	SpikeStartSeconds    int `json:"SpikeStartSeconds"`
+	SpikeDurationSeconds int `json:"SpikeDurationSeconds"`
+	// PeriodSeconds Period of a sine stage
+	PeriodSeconds int `json:"PeriodSeconds"`
+}
+
+// GRPCMethodConfiguration describes the gRPC method invoked dynamically and the request it is invoked with
+type GRPCMethodConfiguration struct {
+	// Method Fully qualified name of the method, e.g., helloworld.Greeter/SayHello
+	Method string `json:"Method"`
+	// RequestTemplate Go template of the request message in the JSON mapping of protobuf
+	RequestTemplate string `json:"RequestTemplate"`
+}
+
 type LoaderConfiguration struct {
 	Seed int64 `json:"Seed"`
 
@@ -50,17 +75,21 @@ type LoaderConfiguration struct {
 	DirigentControlPlaneIP   string `json:"DirigentControlPlaneIP"`
 	BusyLoopOnSandboxStartup bool   `json:"BusyLoopOnSandboxStartup"`
 
-	AsyncMode             bool   `json:"AsyncMode"`
-	AsyncResponseURL      string `json:"AsyncResponseURL"`
-	AsyncWaitToCollectMin int    `json:"AsyncWaitToCollectMin"`
-
-	RpsTarget                   float64 `json:"RpsTarget"`
-	RpsColdStartRatioPercentage float64 `json:"RpsColdStartRatioPercentage"`
-	RpsCooldownSeconds          int     `json:"RpsCooldownSeconds"`
-	RpsImage                    string  `json:"RpsImage"`
-	RpsRuntimeMs                int     `json:"RpsRuntimeMs"`
-	RpsMemoryMB                 int     `json:"RpsMemoryMB"`
-	RpsIterationMultiplier      int     `json:"RpsIterationMultiplier"`
+	AsyncMode                   bool   `json:"AsyncMode"`
+	AsyncResponseURL            string `json:"AsyncResponseURL"`
+	AsyncResponseTimeoutSeconds int    `json:"AsyncResponseTimeoutSeconds"`
+	AsyncPollConcurrency        int    `json:"AsyncPollConcurrency"`
+	AsyncPollInitialBackoffMs   int    `json:"AsyncPollInitialBackoffMs"`
+	AsyncPollMaxBackoffMs       int    `json:"AsyncPollMaxBackoffMs"`
+
+	RpsTarget                   float64    `json:"RpsTarget"`
+	RpsColdStartRatioPercentage float64    `json:"RpsColdStartRatioPercentage"`
+	RpsCooldownSeconds          int        `json:"RpsCooldownSeconds"`
+	RpsImage                    string     `json:"RpsImage"`
+	RpsRuntimeMs                int        `json:"RpsRuntimeMs"`
+	RpsMemoryMB                 int        `json:"RpsMemoryMB"`
+	RpsIterationMultiplier      int        `json:"RpsIterationMultiplier"`
+	RpsStages                   []RpsStage `json:"RpsStages"`
 
 	TracePath          string `json:"TracePath"`
 	Granularity        string `json:"Granularity"`
@@ -71,19 +100,117 @@ type LoaderConfiguration struct {
 	WarmupDuration     int    `json:"WarmupDuration"`
 	PrepullMode        string `json:"PrepullMode"`
 
+	InvocationScheduler       string `json:"InvocationScheduler"`
+	SchedulerWorkerPoolSize   int    `json:"SchedulerWorkerPoolSize"`
+	SchedulerSpinMicroseconds int    `json:"SchedulerSpinMicroseconds"`
+
+	LoadMode               string `json:"LoadMode"`
+	ClosedLoopVirtualUsers int    `json:"ClosedLoopVirtualUsers"`
+	ClosedLoopThinkTimeMs  int    `json:"ClosedLoopThinkTimeMs"`
+	MaxInFlightPerFunction int    `json:"MaxInFlightPerFunction"`
+	MaxInFlightGlobal      int    `json:"MaxInFlightGlobal"`
+
+	EnableRuntimeAssertions             bool    `json:"EnableRuntimeAssertions"`
+	RequestedVsIssuedWarnThreshold      float64 `json:"RequestedVsIssuedWarnThreshold"`
+	RequestedVsIssuedTerminateThreshold float64 `json:"RequestedVsIssuedTerminateThreshold"`
+	FailedWarnThreshold                 float64 `json:"FailedWarnThreshold"`
+	FailedTerminateThreshold            float64 `json:"FailedTerminateThreshold"`
+
+	GracefulShutdownTimeoutSeconds int    `json:"GracefulShutdownTimeoutSeconds"`
+	ControlServerAddress           string `json:"ControlServerAddress"`
+	LoaderMetricsAddress           string `json:"LoaderMetricsAddress"`
+
+	RetryMaxAttempts            int      `json:"RetryMaxAttempts"`
+	RetryInitialBackoffMs       int      `json:"RetryInitialBackoffMs"`
+	RetryMaxBackoffMs           int      `json:"RetryMaxBackoffMs"`
+	RetryJitter                 float64  `json:"RetryJitter"`
+	RetryOn                     []string `json:"RetryOn"`
+	RetriesCountAgainstSchedule bool     `json:"RetriesCountAgainstSchedule"`
+
+	SchedulingLagBoundMs int  `json:"SchedulingLagBoundMs"`
+	AbortOnSchedulingLag bool `json:"AbortOnSchedulingLag"`
+
+	TimeScale    float64 `json:"TimeScale"`
+	ScaleRuntime bool    `json:"ScaleRuntime"`
+
+	ReplayPath string `json:"ReplayPath"`
+
+	EnableCheckpointing bool `json:"EnableCheckpointing"`
+
+	InvokerMiddlewares        []string `json:"InvokerMiddlewares"`
+	RateLimitRps              float64  `json:"RateLimitRps"`
+	FaultInjectionProbability float64  `json:"FaultInjectionProbability"`
+	FaultInjectionDelayMs     int      `json:"FaultInjectionDelayMs"`
+
+	LocalColdStartDelayMs     int `json:"LocalColdStartDelayMs"`
+	LocalContainerConcurrency int `json:"LocalContainerConcurrency"`
+	LocalScaleToZeroAfterMs   int `json:"LocalScaleToZeroAfterMs"`
+
+	EnableTLS             bool   `json:"EnableTLS"`
+	TLSCACertPath         string `json:"TLSCACertPath"`
+	TLSClientCertPath     string `json:"TLSClientCertPath"`
+	TLSClientKeyPath      string `json:"TLSClientKeyPath"`
+	TLSServerName         string `json:"TLSServerName"`
+	TLSInsecureSkipVerify bool   `json:"TLSInsecureSkipVerify"`
+
+	AuthScheme      string `json:"AuthScheme"`
+	AuthHeader      string `json:"AuthHeader"`
+	AuthToken       string `json:"AuthToken"`
+	AuthTokenEnv    string `json:"AuthTokenEnv"`
+	AuthSecretsPath string `json:"AuthSecretsPath"`
+
+	PayloadSizes []common.FunctionPayloadStats `json:"PayloadSizes"`
+
+	TriggerArrivalModels map[string]common.ArrivalModel `json:"TriggerArrivalModels"`
+
+	DandelionKernel            string `json:"DandelionKernel"`
+	DandelionFunctionName      string `json:"DandelionFunctionName"`
+	DandelionCompositionName   string `json:"DandelionCompositionName"`
+	DandelionInputSetName      string `json:"DandelionInputSetName"`
+	DandelionInvocationPath    string `json:"DandelionInvocationPath"`
+	DandelionInputSizeBytes    int    `json:"DandelionInputSizeBytes"`
+	DandelionRuntimeMultiplier int    `json:"DandelionRuntimeMultiplier"`
+
+	OpenWhiskAPIHost        string `json:"OpenWhiskAPIHost"`
+	OpenWhiskAuth           string `json:"OpenWhiskAuth"`
+	OpenWhiskNamespace      string `json:"OpenWhiskNamespace"`
+	OpenWhiskInvocationMode string `json:"OpenWhiskInvocationMode"`
+	OpenWhiskVerifyTLS      bool   `json:"OpenWhiskVerifyTLS"`
+
+	GenericHTTPURL           string            `json:"GenericHTTPURL"`
+	GenericHTTPMethod        string            `json:"GenericHTTPMethod"`
+	GenericHTTPHeaders       map[string]string `json:"GenericHTTPHeaders"`
+	GenericHTTPBody          string            `json:"GenericHTTPBody"`
+	GenericHTTPDurationPath  string            `json:"GenericHTTPDurationPath"`
+	GenericHTTPInstancePath  string            `json:"GenericHTTPInstancePath"`
+	GenericHTTPMemoryPath    string            `json:"GenericHTTPMemoryPath"`
+	GenericHTTPDeployWebhook string            `json:"GenericHTTPDeployWebhook"`
+	GenericHTTPCleanWebhook  string            `json:"GenericHTTPCleanWebhook"`
+
 	IsPartiallyPanic            bool   `json:"IsPartiallyPanic"`
 	EnableZipkinTracing         bool   `json:"EnableZipkinTracing"`
 	EnableMetricsScrapping      bool   `json:"EnableMetricsScrapping"`
 	MetricScrapingPeriodSeconds int    `json:"MetricScrapingPeriodSeconds"`
 	AutoscalingMetric           string `json:"AutoscalingMetric"`
 
-	GRPCConnectionTimeoutSeconds int  `json:"GRPCConnectionTimeoutSeconds"`
-	GRPCFunctionTimeoutSeconds   int  `json:"GRPCFunctionTimeoutSeconds"`
-	DAGMode                      bool `json:"DAGMode"`
-	EnableDAGDataset             bool `json:"EnableDAGDataset"`
-	Width                        int  `json:"Width"`
-	Depth                        int  `json:"Depth"`
-	VSwarm                       bool `json:"VSwarm"`
+	GRPCConnectionTimeoutSeconds int    `json:"GRPCConnectionTimeoutSeconds"`
+	GRPCFunctionTimeoutSeconds   int    `json:"GRPCFunctionTimeoutSeconds"`
+	GRPCConnectionMode           string `json:"GRPCConnectionMode"`
+	GRPCPoolSize                 int    `json:"GRPCPoolSize"`
+	GRPCPoolIdleTimeoutSeconds   int    `json:"GRPCPoolIdleTimeoutSeconds"`
+	GRPCKeepaliveSeconds         int    `json:"GRPCKeepaliveSeconds"`
+	DAGMode                      bool   `json:"DAGMode"`
+	EnableDAGDataset             bool   `json:"EnableDAGDataset"`
+	Width                        int    `json:"Width"`
+	Depth                        int    `json:"Depth"`
+	VSwarm                       bool   `json:"VSwarm"`
+
+	GRPCDescriptorSetPath  string                             `json:"GRPCDescriptorSetPath"`
+	GRPCReflectionEndpoint string                             `json:"GRPCReflectionEndpoint"`
+	GRPCDynamicMethod      GRPCMethodConfiguration            `json:"GRPCDynamicMethod"`
+	GRPCFunctionMethods    map[string]GRPCMethodConfiguration `json:"GRPCFunctionMethods"`
+	GRPCInstanceField      string                             `json:"GRPCInstanceField"`
+	GRPCDurationField      string                             `json:"GRPCDurationField"`
 }
 
 func ReadConfigurationFile(path string) LoaderConfiguration {
@@ -2,93 +2,186 @@ package driver
 
 import (
 	"bytes"
-	log "github.com/sirupsen/logrus"
-	"github.com/vhive-serverless/loader/pkg/driver/clients"
-	"github.com/vhive-serverless/loader/pkg/metric"
+	"context"
 	"io"
-	"math"
 	"net"
 	"net/http"
 	"strconv"
 	"sync"
 	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/vhive-serverless/loader/pkg/common"
+	"github.com/vhive-serverless/loader/pkg/config"
+	"github.com/vhive-serverless/loader/pkg/driver/clients"
+	"github.com/vhive-serverless/loader/pkg/metric"
 )
 
-func (d *Driver) writeAsyncRecordsToLog(logCh chan *metric.ExecutionRecord) {
-	const batchSize = 50
-
-	client := &http.Client{
-		Timeout: 5 * time.Second,
-		Transport: &http.Transport{
-			DialContext: (&net.Dialer{
-				Timeout: 2 * time.Second,
-			}).DialContext,
-			IdleConnTimeout:     time.Second,
-			MaxIdleConns:        batchSize,
-			MaxIdleConnsPerHost: batchSize,
+// asyncCollector fetches the responses of asynchronous invocations while the experiment is running. Every invocation
+// is polled with exponential backoff, starting once its requested runtime has elapsed, until its response is available
+// or its deadline has passed.
+type asyncCollector struct {
+	client      *http.Client
+	responseURL string
+
+	timeout        time.Duration
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+
+	// polls Bounds the number of responses requested concurrently
+	polls chan struct{}
+	// done Stops waiting between polls, after which every invocation is polled one last time
+	done <-chan struct{}
+
+	pending sync.WaitGroup
+}
+
+func newAsyncCollector(cfg *config.LoaderConfiguration, done <-chan struct{}) *asyncCollector {
+	concurrency := cfg.AsyncPollConcurrency
+	if concurrency <= 0 {
+		concurrency = common.DefaultAsyncPollConcurrency
+	}
+
+	timeout := cfg.AsyncResponseTimeoutSeconds
+	if timeout <= 0 {
+		timeout = cfg.GRPCFunctionTimeoutSeconds
+	}
+
+	initialBackoff := cfg.AsyncPollInitialBackoffMs
+	if initialBackoff <= 0 {
+		initialBackoff = common.DefaultAsyncPollInitialBackoffMs
+	}
+
+	maxBackoff := cfg.AsyncPollMaxBackoffMs
+	if maxBackoff <= 0 {
+		maxBackoff = common.DefaultAsyncPollMaxBackoffMs
+	}
+
+	return &asyncCollector{
+		client: &http.Client{
+			Timeout: 5 * time.Second,
+			Transport: &http.Transport{
+				DialContext: (&net.Dialer{
+					Timeout: 2 * time.Second,
+				}).DialContext,
+				IdleConnTimeout:     time.Second,
+				MaxIdleConns:        concurrency,
+				MaxIdleConnsPerHost: concurrency,
+			},
 		},
+		responseURL: cfg.AsyncResponseURL,
+
+		timeout:        time.Duration(timeout) * time.Second,
+		initialBackoff: time.Duration(initialBackoff) * time.Millisecond,
+		maxBackoff:     time.Duration(common.MaxOf(initialBackoff, maxBackoff)) * time.Millisecond,
+
+		polls: make(chan struct{}, concurrency),
+		done:  done,
 	}
+}
+
+// collect starts polling the response of the submitted invocation, whose record is delivered to the log once the
+// response has been fetched or the deadline has passed
+func (c *asyncCollector) collect(record *metric.ExecutionRecord, deliver func(*metric.ExecutionRecord)) {
+	c.pending.Add(1)
 
-	currentBatch := 0
-	totalBatches := int(math.Ceil(float64(d.AsyncRecords.Length()) / float64(batchSize)))
+	go func() {
+		defer c.pending.Done()
 
-	log.Infof("Gathering functions responses...")
-	for d.AsyncRecords.Length() > 0 {
-		currentBatch++
+		c.poll(record)
+		deliver(record)
+	}()
+}
 
-		toProcess := batchSize
-		if d.AsyncRecords.Length() < batchSize {
-			toProcess = d.AsyncRecords.Length()
+// wait blocks until the records of all the submitted invocations have been written to the log
+func (c *asyncCollector) wait() {
+	log.Infof("Waiting for the responses of asynchronous invocations...")
+	c.pending.Wait()
+	log.Infof("Finished gathering async response answers")
+}
+
+func (c *asyncCollector) poll(record *metric.ExecutionRecord) {
+	submitted := time.UnixMicro(record.StartTime)
+	deadline := submitted.Add(c.timeout)
+
+	// the response cannot be available before the function has run for the requested time
+	nextPoll := submitted.Add(time.Duration(record.ResponseTime+int64(record.RequestedDuration)) * time.Microsecond)
+	backoff := c.initialBackoff
+
+	for finalAttempt := false; ; {
+		wait := time.Until(nextPoll)
+		if remaining := time.Until(deadline); wait >= remaining {
+			wait, finalAttempt = remaining, true
+		}
+		if !c.sleep(wait) {
+			finalAttempt = true
+		}
+
+		start := time.Now()
+		response, e2e := c.fetch(record.AsyncResponseID)
+
+		if len(response) != 0 {
+			c.complete(record, response, e2e, start)
+			return
 		}
 
-		wg := sync.WaitGroup{}
-		wg.Add(toProcess)
-
-		for i := 0; i < toProcess; i++ {
-			go func() {
-				defer wg.Done()
-
-				start := time.Now()
-
-				record := d.AsyncRecords.Dequeue()
-				response, e2e := d.getAsyncResponseData(
-					client,
-					d.Configuration.LoaderConfiguration.AsyncResponseURL,
-					record.AsyncResponseID,
-				)
-
-				if string(response) != "" {
-					err := clients.DeserializeDirigentResponse(response, record)
-					if err != nil {
-						log.Errorf("Failed to deserialize Dirigent response - %v - %v", string(response), err)
-					}
-				} else {
-					record.FunctionTimeout = true
-					record.AsyncResponseID = ""
-					log.Errorf("Failed to fetch response. The function has probably not yet completed.")
-				}
-
-				// loader send request + request e2e + loader get response
-				timeToFetchResponse := time.Since(start).Microseconds()
-				record.UserCodeExecutionMs = int64(e2e)
-				record.TimeToGetResponseMs = timeToFetchResponse
-				record.ResponseTime += int64(e2e)
-				record.ResponseTime += timeToFetchResponse
-
-				logCh <- record
-			}()
+		if finalAttempt {
+			log.Errorf("Failed to fetch the response of %s within %v.", record.AsyncResponseID, c.timeout)
+
+			record.FunctionTimeout = true
+			record.AsyncResponseID = ""
+
+			return
 		}
 
-		wg.Wait()
+		nextPoll = time.Now().Add(backoff)
+		backoff = min(2*backoff, c.maxBackoff)
+	}
+}
+
+// complete fills the record from the fetched response. The response time spans from issuing the invocation until
+// the function has completed, as reported by the platform, or until the response has been fetched otherwise.
+func (c *asyncCollector) complete(record *metric.ExecutionRecord, response []byte, e2e int, fetchStart time.Time) {
+	if err := clients.DeserializeDirigentResponse(response, record); err != nil {
+		log.Errorf("Failed to deserialize Dirigent response - %v - %v", string(response), err)
+	}
+
+	record.UserCodeExecutionMs = int64(e2e)
+	record.TimeToGetResponseMs = time.Since(fetchStart).Microseconds()
+
+	if e2e > 0 {
+		record.ResponseTime = record.TimeToSubmitMs + int64(e2e)
+	} else {
+		record.ResponseTime = time.Now().UnixMicro() - record.StartTime
+	}
+	record.CompletionTime = record.StartTime + record.ResponseTime
+	record.AsyncResponseID = ""
+}
 
-		log.Infof("Processed %d/%d batches of async response gatherings", currentBatch, totalBatches)
+// sleep returns false if the collector has been stopped before the given time has elapsed
+func (c *asyncCollector) sleep(duration time.Duration) bool {
+	if duration <= 0 {
+		return true
 	}
 
-	log.Infof("Finished gathering async reponse answers")
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-c.done:
+		return false
+	}
 }
 
-func (d *Driver) getAsyncResponseData(client *http.Client, endpoint string, guid string) ([]byte, int) {
-	req, err := http.NewRequest("GET", "http://"+endpoint, bytes.NewReader([]byte(guid)))
+// fetch returns the response of the invocation with the given ID along with its end-to-end latency in microseconds,
+// or an empty response if the invocation has not completed yet, i.e., unless the status code is 200
+func (c *asyncCollector) fetch(guid string) ([]byte, int) {
+	c.polls <- struct{}{}
+	defer func() { <-c.polls }()
+
+	req, err := http.NewRequestWithContext(context.Background(), "GET", "http://"+c.responseURL, bytes.NewReader([]byte(guid)))
 	if err != nil {
 		log.Errorf("Failed to retrieve Dirigent response for %s - %v", guid, err)
 		return []byte{}, 0
@@ -97,13 +190,18 @@ func (d *Driver) getAsyncResponseData(client *http.Client, endpoint string, guid
 	// TODO: set function name for load-balancing purpose
 	//req.Header.Set("function", function.Name)
 
-	resp, err := client.Do(req)
+	resp, err := c.client.Do(req)
 	if err != nil {
 		log.Errorf("Failed to retrieve Dirigent response for %s - %v", guid, err)
 		return []byte{}, 0
 	}
 
 	defer clients.HandleBodyClosing(resp)
+	if resp.StatusCode != http.StatusOK {
+		log.Debugf("Dirigent response for %s is not available yet - status code %d.", guid, resp.StatusCode)
+		return []byte{}, 0
+	}
+
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
 		log.Errorf("Failed to read Dirigent response body for %s - %v", guid, err)
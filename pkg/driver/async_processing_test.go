@@ -0,0 +1,135 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2023 EASL and the vHive community
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package driver
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/vhive-serverless/loader/pkg/config"
+	mc "github.com/vhive-serverless/loader/pkg/metric"
+)
+
+func TestAsyncCollector(t *testing.T) {
+	var polls sync.Map
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		guid := string(body)
+
+		count, _ := polls.LoadOrStore(guid, new(int))
+		*count.(*int)++
+
+		// the first invocation completes after its third poll, the second one never does
+		if guid == "completed" && *count.(*int) >= 3 {
+			w.Header().Set("Duration-Microseconds", "250000")
+			_, _ = w.Write([]byte(`{"Function": "test-instance", "ExecutionTime": 200000}`))
+		} else {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte("response not available"))
+		}
+	}))
+	defer server.Close()
+
+	collector := newAsyncCollector(&config.LoaderConfiguration{
+		AsyncResponseURL:            strings.TrimPrefix(server.URL, "http://"),
+		AsyncResponseTimeoutSeconds: 1,
+		AsyncPollConcurrency:        1,
+		AsyncPollInitialBackoffMs:   10,
+		AsyncPollMaxBackoffMs:       100,
+	}, make(chan struct{}))
+
+	now := time.Now().UnixMicro()
+	logCh := make(chan *mc.ExecutionRecord, 2)
+	deliver := func(record *mc.ExecutionRecord) { logCh <- record }
+
+	completed := &mc.ExecutionRecord{
+		ExecutionRecordBase: mc.ExecutionRecordBase{StartTime: now, RequestedDuration: 50_000, ResponseTime: 1_000},
+		AsyncResponseID:     "completed",
+		TimeToSubmitMs:      1_000,
+	}
+	running := &mc.ExecutionRecord{
+		ExecutionRecordBase: mc.ExecutionRecordBase{StartTime: now, ResponseTime: 1_000},
+		AsyncResponseID:     "running",
+		TimeToSubmitMs:      1_000,
+	}
+
+	start := time.Now()
+	collector.collect(completed, deliver)
+	collector.collect(running, deliver)
+	collector.wait()
+	close(logCh)
+
+	if len(logCh) != 2 {
+		t.Fatalf("Expected both records to be logged, got %d.", len(logCh))
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Collection has not respected the deadline - took %v.", elapsed)
+	}
+
+	if completed.FunctionTimeout || completed.Instance != "test-instance" || completed.ActualDuration != 200_000 {
+		t.Errorf("Unexpected record of the completed invocation %+v", completed)
+	}
+	if completed.ResponseTime != 251_000 || completed.CompletionTime != now+251_000 || completed.AsyncResponseID != "" {
+		t.Errorf("Unexpected response time %d and completion time %d.", completed.ResponseTime, completed.CompletionTime)
+	}
+
+	if count, _ := polls.Load("running"); !running.FunctionTimeout || *count.(*int) < 5 {
+		t.Errorf("Expected the running invocation to be polled until its deadline, got %+v", running)
+	}
+}
+
+func TestAsyncCollectorStopped(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	done := make(chan struct{})
+	collector := newAsyncCollector(&config.LoaderConfiguration{
+		AsyncResponseURL:            strings.TrimPrefix(server.URL, "http://"),
+		AsyncResponseTimeoutSeconds: 60,
+	}, done)
+
+	logCh := make(chan *mc.ExecutionRecord, 1)
+	collector.collect(&mc.ExecutionRecord{
+		ExecutionRecordBase: mc.ExecutionRecordBase{StartTime: time.Now().UnixMicro(), RequestedDuration: 10_000_000},
+		AsyncResponseID:     "running",
+	}, func(record *mc.ExecutionRecord) { logCh <- record })
+
+	close(done)
+
+	select {
+	case record := <-logCh:
+		if !record.FunctionTimeout {
+			t.Errorf("Expected the invocation to time out, got %+v", record)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Stopping the collector has not ended the polling.")
+	}
+}
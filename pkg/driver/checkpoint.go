@@ -0,0 +1,305 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2023 EASL and the vHive community
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package driver
+
+import (
+	"encoding/json"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/vhive-serverless/loader/pkg/common"
+	mc "github.com/vhive-serverless/loader/pkg/metric"
+)
+
+// checkpoint captures the progress of the experiment at a minute boundary, so that an interrupted experiment can be
+// resumed from it with the same function specifications
+type checkpoint struct {
+	// Minute Number of minutes of the trace that have been completed
+	Minute int `json:"minute"`
+	// IatIndex Index of the next invocation of each function (or DAG) to issue, keyed by the function name
+	IatIndex map[string]int `json:"iatIndex"`
+	// Pending Indices of the invocations of each function that were in flight, none of whose records are part of the
+	// output files, hence they are issued again once the experiment is resumed
+	Pending map[string][]int `json:"pending,omitempty"`
+
+	Issued    int64 `json:"issued"`
+	Succeeded int64 `json:"succeeded"`
+	Failed    int64 `json:"failed"`
+
+	// OutputOffsets Size in bytes up to which the output files contain complete records, keyed by the output name
+	OutputOffsets map[string]int64 `json:"outputOffsets"`
+}
+
+// trackedInvocation holds back the records of an invocation issued from the trace until the invocation has completed
+// along with its DAG branches, retries and asynchronous responses, so that a checkpoint either contains all its
+// records or lists it as pending
+type trackedInvocation struct {
+	cursor   *invocationCursor
+	iatIndex int
+
+	mutex sync.Mutex
+	// refs Number of parts of the invocation that have not completed yet
+	refs    int
+	records []*mc.ExecutionRecord
+	counts  invocationCounts
+}
+
+// invocationCounts Number of invocations issued, succeeded and failed, which are only added to a checkpoint for the
+// invocations that have completed, as the others are issued again once the experiment is resumed
+type invocationCounts struct {
+	issued    int64
+	succeeded int64
+	failed    int64
+}
+
+func (t *trackedInvocation) acquire() {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.refs++
+}
+
+func (t *trackedInvocation) add(record *mc.ExecutionRecord) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.records = append(t.records, record)
+}
+
+// count adds to the counters of the invocation, if it is tracked
+func (t *trackedInvocation) count(issued int64, succeeded int64, failed int64) {
+	if t == nil {
+		return
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.counts.issued += issued
+	t.counts.succeeded += succeeded
+	t.counts.failed += failed
+}
+
+type specificationEntry struct {
+	Name          string                        `json:"name"`
+	Specification *common.FunctionSpecification `json:"specification"`
+}
+
+// writeSpecifications stores the generated specifications, as they cannot be regenerated for a resumed experiment
+// with different function names and seeds
+func (d *Driver) writeSpecifications() {
+	entries := make([]specificationEntry, 0, len(d.Configuration.Functions))
+	for _, function := range d.Configuration.Functions {
+		entries = append(entries, specificationEntry{
+			Name:          function.Name,
+			Specification: function.Specification,
+		})
+	}
+
+	data, err := json.Marshal(entries)
+	if err != nil {
+		log.Fatalf("Failed to marshal function specifications - %v", err)
+	}
+
+	err = os.WriteFile(d.outputFilenameWithExtension("specification", "json"), data, 0644)
+	if err != nil {
+		log.Fatalf("Failed to write function specifications - %v", err)
+	}
+}
+
+// ResumeFromCheckpoint restores the function specifications and the progress of a previous run of the experiment, which
+// continues from the last minute boundary it has reached
+func (d *Driver) ResumeFromCheckpoint() {
+	data, err := os.ReadFile(d.outputFilenameWithExtension("specification", "json"))
+	if err != nil {
+		log.Fatalf("Failed to read function specifications of the experiment to resume - %v", err)
+	}
+
+	var entries []specificationEntry
+	if err = json.Unmarshal(data, &entries); err != nil {
+		log.Fatalf("Failed to unmarshal function specifications - %v", err)
+	}
+
+	if len(entries) != len(d.Configuration.Functions) {
+		log.Fatalf("The experiment to resume has %d functions, while the trace contains %d.", len(entries), len(d.Configuration.Functions))
+	}
+
+	// function names are generated randomly when the trace is parsed, hence the original ones are restored
+	for i, function := range d.Configuration.Functions {
+		function.Name = entries[i].Name
+		function.Specification = entries[i].Specification
+	}
+
+	data, err = os.ReadFile(d.outputFilenameWithExtension("checkpoint", "json"))
+	if err != nil {
+		log.Fatalf("Failed to read the checkpoint of the experiment to resume - %v", err)
+	}
+
+	d.resumeFrom = &checkpoint{}
+	if err = json.Unmarshal(data, d.resumeFrom); err != nil {
+		log.Fatalf("Failed to unmarshal the checkpoint - %v", err)
+	}
+
+	if d.resumeFrom.Minute >= d.Configuration.TraceDuration {
+		log.Fatal("The experiment to resume has already completed.")
+	}
+
+	d.clock.resumeAt(d.Configuration.ScaledDuration(time.Duration(d.resumeFrom.Minute) * time.Minute).Microseconds())
+	atomic.StoreInt64(&d.currentMinute, int64(d.resumeFrom.Minute))
+
+	log.Infof("Resuming the experiment from minute %d - %d invocations have been issued, %d succeeded and %d failed so far.",
+		d.resumeFrom.Minute, d.resumeFrom.Issued, d.resumeFrom.Succeeded, d.resumeFrom.Failed)
+}
+
+// resumeOffset returns the size of the given output file at the checkpoint, or zero if the experiment is not resumed
+func (d *Driver) resumeOffset(output string) int64 {
+	if d.resumeFrom == nil {
+		return 0
+	}
+
+	return d.resumeFrom.OutputOffsets[output]
+}
+
+func (d *Driver) registerCursor(cursor *invocationCursor) {
+	d.countersMutex.Lock()
+	defer d.countersMutex.Unlock()
+
+	d.cursors = append(d.cursors, cursor)
+}
+
+// logRecord writes the record of the invocation once the invocation has completed if it is tracked, and right away
+// otherwise
+func (d *Driver) logRecord(metadata *InvocationMetadata, record *mc.ExecutionRecord) {
+	if metadata.tracked != nil {
+		metadata.tracked.add(record)
+	} else {
+		metadata.RecordOutputChannel <- record
+	}
+}
+
+// releaseInvocation marks a part of the invocation as completed and writes the records of the invocation once all
+// of its parts have completed
+func (d *Driver) releaseInvocation(metadata *InvocationMetadata) {
+	t := metadata.tracked
+	if t == nil {
+		return
+	}
+
+	t.mutex.Lock()
+	t.refs--
+	completed, records, counts := t.refs == 0, t.records, t.counts
+	t.mutex.Unlock()
+
+	if !completed {
+		return
+	}
+
+	// a checkpoint is not taken while the records are handed over, so that none or all of them are in the output
+	d.recordGate.RLock()
+	defer d.recordGate.RUnlock()
+
+	for _, record := range records {
+		metadata.RecordOutputChannel <- record
+	}
+	atomic.AddInt64(&d.completed.issued, counts.issued)
+	atomic.AddInt64(&d.completed.succeeded, counts.succeeded)
+	atomic.AddInt64(&d.completed.failed, counts.failed)
+	t.cursor.complete(t.iatIndex)
+}
+
+// flushDurationFile waits for the collector to write the records handed over so far and returns the size of the file
+func (d *Driver) flushDurationFile() int64 {
+	reply := make(chan int64, 1)
+
+	select {
+	case d.flushRequests <- reply:
+		return <-reply
+	case <-d.collectorDone:
+		// all the records have been written and the file has been closed
+		return d.durationFile.Flush()
+	}
+}
+
+// writeCheckpoint is called at the end of each minute and replaces the previous checkpoint
+func (d *Driver) writeCheckpoint(minute int) {
+	state := checkpoint{
+		Minute:   minute,
+		IatIndex: make(map[string]int),
+		Pending:  make(map[string][]int),
+	}
+
+	// the records of completed invocations are handed over while holding the gate, hence once it is taken, the
+	// records received by the collector are exactly those of the invocations that are not pending
+	d.recordGate.Lock()
+
+	state.OutputOffsets = map[string]int64{
+		"duration":       d.flushDurationFile(),
+		"scheduling_lag": d.schedulingLag.flush(),
+	}
+
+	state.Issued = atomic.LoadInt64(&d.completed.issued)
+	state.Succeeded = atomic.LoadInt64(&d.completed.succeeded)
+	state.Failed = atomic.LoadInt64(&d.completed.failed)
+
+	d.countersMutex.Lock()
+	for _, cursor := range d.cursors {
+		progress, pending := cursor.snapshot()
+
+		state.IatIndex[cursor.functionName()] = progress
+		if len(pending) > 0 {
+			state.Pending[cursor.functionName()] = pending
+		}
+	}
+	d.countersMutex.Unlock()
+
+	d.recordGate.Unlock()
+
+	if d.resumeFrom != nil {
+		state.Issued += d.resumeFrom.Issued
+		state.Succeeded += d.resumeFrom.Succeeded
+		state.Failed += d.resumeFrom.Failed
+	}
+
+	data, err := json.Marshal(state)
+	if err != nil {
+		log.Errorf("Failed to marshal the checkpoint - %v", err)
+		return
+	}
+
+	// the checkpoint is replaced atomically, so that a crash while writing it does not corrupt the previous one
+	filename := d.outputFilenameWithExtension("checkpoint", "json")
+	if err = os.WriteFile(filename+".tmp", data, 0644); err == nil {
+		err = os.Rename(filename+".tmp", filename)
+	}
+	if err != nil {
+		log.Errorf("Failed to write the checkpoint - %v", err)
+		return
+	}
+
+	log.Debugf("Checkpoint written at the end of minute %d.", minute-1)
+}
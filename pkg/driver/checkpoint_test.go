@@ -0,0 +1,309 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2023 EASL and the vHive community
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package driver
+
+import (
+	"encoding/json"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gocarina/gocsv"
+	"github.com/vhive-serverless/loader/pkg/common"
+	mc "github.com/vhive-serverless/loader/pkg/metric"
+)
+
+func TestInvocationCursorSkipTo(t *testing.T) {
+	tests := []struct {
+		testName      string
+		iatIndex      int
+		expectedDueAt int64
+		expectedID    string
+	}{
+		{
+			testName:      "within_minute",
+			iatIndex:      1,
+			expectedDueAt: 30_000_000,
+			expectedID:    "min0.inv1",
+		},
+		{
+			testName:      "following_minute",
+			iatIndex:      2,
+			expectedDueAt: 150_000_000,
+			expectedID:    "min2.inv0",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.testName, func(t *testing.T) {
+			driver := createTestDriver([]int{2, 0, 1})
+			driver.Configuration.TraceGranularity = common.MinuteGranularity
+
+			cursor := driver.newInvocationCursor(InvocationMetadata{
+				RootFunction: createFunctionLinkedList("f", common.IATArray{0, 30_000_000, 120_000_000}, []int{2, 0, 1}),
+			})
+			cursor.skipTo(test.iatIndex)
+
+			if cursor.dueAt != test.expectedDueAt || cursor.progress() != test.iatIndex {
+				t.Errorf("Unexpected cursor state - due at %d, progress %d.", cursor.dueAt, cursor.progress())
+			}
+
+			if metadata := cursor.next(driver.Configuration.TraceGranularity); metadata.InvocationID != test.expectedID || metadata.IatIndex != test.iatIndex {
+				t.Errorf("Unexpected invocation metadata - ID = %s, IAT index = %d.", metadata.InvocationID, metadata.IatIndex)
+			}
+		})
+	}
+}
+
+// startTestCollector writes the records sent to the returned channel to the duration file until the returned function
+// is called with the number of records sent
+func startTestCollector(driver *Driver, filename string) (chan *mc.ExecutionRecord, func(int64)) {
+	recordOutputChannel, totalIssuedChannel := make(chan *mc.ExecutionRecord), make(chan int64)
+	collectorReady, collectorFinished := &sync.WaitGroup{}, &sync.WaitGroup{}
+
+	collectorReady.Add(1)
+	collectorFinished.Add(1)
+
+	driver.durationFile = mc.OpenCSVFile(filename, 0)
+	driver.startCollector(recordOutputChannel, collectorReady, collectorFinished, totalIssuedChannel)
+	collectorReady.Wait()
+
+	return recordOutputChannel, func(total int64) {
+		totalIssuedChannel <- total
+		collectorFinished.Wait()
+	}
+}
+
+func TestCheckpointAndResume(t *testing.T) {
+	driver := createTestDriver([]int{1, 1})
+	driver.Configuration.TraceDuration = 2
+	driver.Configuration.LoaderConfiguration.EnableCheckpointing = true
+	driver.Configuration.Functions[0].Specification.IAT = common.IATArray{0, 60_000_000}
+
+	durationFilename := driver.outputFilename("duration")
+	defer os.Remove(durationFilename)
+	defer os.Remove(driver.outputFilenameWithExtension("specification", "json"))
+	defer os.Remove(driver.outputFilenameWithExtension("checkpoint", "json"))
+
+	driver.writeSpecifications()
+	recordOutputChannel, stopCollector := startTestCollector(driver, durationFilename)
+
+	cursor := driver.newInvocationCursor(InvocationMetadata{RootFunction: createFunctionLinkedList("test-function", common.IATArray{0, 60_000_000}, []int{1, 1}), RecordOutputChannel: recordOutputChannel})
+	metadata := cursor.next(driver.Configuration.TraceGranularity)
+	metadata.tracked.count(1, 1, 0)
+	driver.logRecord(metadata, &mc.ExecutionRecord{ExecutionRecordBase: mc.ExecutionRecordBase{InvocationID: "min0.inv0"}})
+	driver.releaseInvocation(metadata)
+
+	driver.writeCheckpoint(1)
+
+	// records written after the checkpoint are discarded once the experiment is resumed
+	recordOutputChannel <- &mc.ExecutionRecord{ExecutionRecordBase: mc.ExecutionRecordBase{InvocationID: "min1.inv0"}}
+	stopCollector(2)
+
+	resumed := createTestDriver([]int{1, 1})
+	resumed.Configuration.TraceDuration = 2
+	resumed.Configuration.Functions[0].Name = "renamed-function"
+	resumed.ResumeFromCheckpoint()
+
+	function := resumed.Configuration.Functions[0]
+	if function.Name != "test-function" || len(function.Specification.IAT) != 2 {
+		t.Errorf("Function specification has not been restored - %s, %v.", function.Name, function.Specification.IAT)
+	}
+
+	state := resumed.resumeFrom
+	if state.Minute != 1 || state.IatIndex["test-function"] != 1 || state.Issued != 1 || state.Succeeded != 1 {
+		t.Errorf("Unexpected checkpoint - %+v.", state)
+	}
+
+	resumed.clock.start()
+	if now := resumed.clock.now(); now < 60_000_000 || now > 61_000_000 {
+		t.Errorf("Experiment clock has not been resumed at the checkpoint - %d.", now)
+	}
+
+	resumedCursor := resumed.newInvocationCursor(InvocationMetadata{RootFunction: createFunctionLinkedList("test-function", common.IATArray{0, 60_000_000}, []int{1, 1})})
+	if resumedCursor.dueAt != 60_000_000 || resumedCursor.minuteIndex != 1 {
+		t.Errorf("Cursor has not been resumed at the checkpoint - due at %d, minute %d.", resumedCursor.dueAt, resumedCursor.minuteIndex)
+	}
+
+	resumed.durationFile = mc.OpenCSVFile(durationFilename, resumed.resumeOffset("duration"))
+	resumed.durationFile.Write(&mc.ExecutionRecord{ExecutionRecordBase: mc.ExecutionRecordBase{InvocationID: "min1.inv0"}})
+	resumed.durationFile.Close()
+
+	file, err := os.Open(durationFilename)
+	if err != nil {
+		t.Fatalf("Failed to open the output file - %v", err)
+	}
+	defer file.Close()
+
+	var records []mc.ExecutionRecord
+	if err = gocsv.UnmarshalFile(file, &records); err != nil {
+		t.Fatalf("Failed to parse the output file - %v", err)
+	}
+
+	if len(records) != 2 || records[0].InvocationID != "min0.inv0" || records[1].InvocationID != "min1.inv0" {
+		t.Errorf("Unexpected records in the output file of the resumed experiment - %+v.", records)
+	}
+}
+
+func TestCheckpointWithInvocationsInFlight(t *testing.T) {
+	driver := createTestDriver([]int{2, 1})
+	driver.Configuration.TraceDuration = 2
+	driver.Configuration.LoaderConfiguration.EnableCheckpointing = true
+	driver.Configuration.Functions[0].Specification.IAT = common.IATArray{0, 10_000_000, 50_000_000}
+
+	durationFilename := driver.outputFilename("duration")
+	defer os.Remove(durationFilename)
+	defer os.Remove(driver.outputFilenameWithExtension("specification", "json"))
+	defer os.Remove(driver.outputFilenameWithExtension("checkpoint", "json"))
+
+	driver.writeSpecifications()
+	recordOutputChannel, stopCollector := startTestCollector(driver, durationFilename)
+
+	functionLinkedList := createFunctionLinkedList("test-function", common.IATArray{0, 10_000_000, 50_000_000}, []int{2, 1})
+	cursor := driver.newInvocationCursor(InvocationMetadata{RootFunction: functionLinkedList, RecordOutputChannel: recordOutputChannel})
+
+	completed := cursor.next(driver.Configuration.TraceGranularity)
+	inFlight := cursor.next(driver.Configuration.TraceGranularity)
+
+	completed.tracked.count(1, 1, 0)
+	driver.logRecord(completed, &mc.ExecutionRecord{ExecutionRecordBase: mc.ExecutionRecordBase{InvocationID: completed.InvocationID}})
+	driver.releaseInvocation(completed)
+	inFlight.tracked.count(1, 1, 0)
+	driver.logRecord(inFlight, &mc.ExecutionRecord{ExecutionRecordBase: mc.ExecutionRecordBase{InvocationID: inFlight.InvocationID}})
+
+	driver.writeCheckpoint(1)
+
+	// the record of the invocation in flight is written after the checkpoint and hence discarded on resume
+	driver.releaseInvocation(inFlight)
+	stopCollector(2)
+
+	resumed := createTestDriver([]int{2, 1})
+	resumed.Configuration.TraceDuration = 2
+	resumed.Configuration.LoaderConfiguration.EnableCheckpointing = true
+	resumed.ResumeFromCheckpoint()
+
+	state := resumed.resumeFrom
+	if state.IatIndex["test-function"] != 2 || len(state.Pending["test-function"]) != 1 || state.Pending["test-function"][0] != 1 {
+		t.Errorf("Unexpected checkpoint - %+v.", state)
+	}
+	// the invocation in flight is counted once it has been reissued
+	if state.Issued != 1 || state.Succeeded != 1 || state.Failed != 0 {
+		t.Errorf("Invocations in flight are counted in the checkpoint - %+v.", state)
+	}
+
+	resumedCursor := resumed.newInvocationCursor(InvocationMetadata{RootFunction: functionLinkedList})
+	if resumedCursor.dueAt != 60_000_000 {
+		t.Errorf("Invocation in flight is not reissued when resuming - due at %d.", resumedCursor.dueAt)
+	}
+
+	expected := []struct {
+		iatIndex     int
+		invocationID string
+		dueAt        int64
+	}{
+		{iatIndex: 1, invocationID: "min0.inv1", dueAt: 60_000_000},
+		{iatIndex: 2, invocationID: "min1.inv0", dueAt: 60_000_000},
+	}
+	for _, invocation := range expected {
+		if !resumedCursor.hasNext() || resumedCursor.dueAt != invocation.dueAt {
+			t.Fatalf("Unexpected cursor state - due at %d.", resumedCursor.dueAt)
+		}
+
+		metadata := resumedCursor.next(resumed.Configuration.TraceGranularity)
+		if metadata.IatIndex != invocation.iatIndex || metadata.InvocationID != invocation.invocationID {
+			t.Errorf("Unexpected invocation metadata - ID = %s, IAT index = %d.", metadata.InvocationID, metadata.IatIndex)
+		}
+	}
+	if resumedCursor.hasNext() {
+		t.Error("Cursor has invocations left after reissuing the invocation in flight.")
+	}
+
+	resumed.durationFile = mc.OpenCSVFile(durationFilename, resumed.resumeOffset("duration"))
+	resumed.durationFile.Close()
+
+	file, err := os.Open(durationFilename)
+	if err != nil {
+		t.Fatalf("Failed to open the output file - %v", err)
+	}
+	defer file.Close()
+
+	var records []mc.ExecutionRecord
+	if err = gocsv.UnmarshalFile(file, &records); err != nil {
+		t.Fatalf("Failed to parse the output file - %v", err)
+	}
+
+	if len(records) != 1 || records[0].InvocationID != "min0.inv0" {
+		t.Errorf("Unexpected records in the output file of the resumed experiment - %+v.", records)
+	}
+}
+
+func TestCheckpointMinuteInTraceTime(t *testing.T) {
+	driver := createTestDriver([]int{1, 1})
+	driver.Configuration.TraceDuration = 2
+	driver.Configuration.LoaderConfiguration.EnableCheckpointing = true
+	// a minute of the trace takes 100 ms
+	driver.Configuration.LoaderConfiguration.TimeScale = 0.1 / 60
+
+	durationFilename := driver.outputFilename("duration")
+	defer os.Remove(durationFilename)
+	defer os.Remove(driver.outputFilenameWithExtension("checkpoint", "json"))
+
+	_, stopCollector := startTestCollector(driver, durationFilename)
+	defer stopCollector(0)
+
+	timekeeperReady, timekeeperDone := &sync.WaitGroup{}, make(chan struct{})
+	timekeeperReady.Add(1)
+	go func() {
+		defer close(timekeeperDone)
+
+		driver.globalTimekeeper(driver.Configuration.TraceDuration, timekeeperReady)
+	}()
+	timekeeperReady.Wait()
+
+	driver.clock.setPaused(true)
+	time.Sleep(250 * time.Millisecond)
+
+	if minute := atomic.LoadInt64(&driver.currentMinute); minute != 0 {
+		t.Errorf("Minute has advanced while the load is paused - %d.", minute)
+	}
+	if _, err := os.Stat(driver.outputFilenameWithExtension("checkpoint", "json")); err == nil {
+		t.Error("Checkpoint has been written while the load is paused.")
+	}
+
+	driver.clock.setPaused(false)
+	<-timekeeperDone
+
+	data, err := os.ReadFile(driver.outputFilenameWithExtension("checkpoint", "json"))
+	if err != nil {
+		t.Fatalf("Failed to read the checkpoint - %v", err)
+	}
+
+	var state checkpoint
+	if err = json.Unmarshal(data, &state); err != nil || state.Minute != 2 {
+		t.Errorf("Unexpected checkpoint at the end of the experiment - %+v, %v.", state, err)
+	}
+}
@@ -0,0 +1,127 @@
+package clients
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"os"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"github.com/vhive-serverless/loader/pkg/common"
+	"github.com/vhive-serverless/loader/pkg/config"
+)
+
+// createTLSConfig returns the TLS configuration used by the HTTP and gRPC invokers, or nil if TLS is disabled
+func createTLSConfig(cfg *config.LoaderConfiguration) *tls.Config {
+	if !cfg.EnableTLS {
+		return nil
+	}
+
+	tlsConfig := &tls.Config{
+		ServerName:         cfg.TLSServerName,
+		InsecureSkipVerify: cfg.TLSInsecureSkipVerify,
+	}
+
+	if cfg.TLSCACertPath != "" {
+		pem, err := os.ReadFile(cfg.TLSCACertPath)
+		if err != nil {
+			logrus.Fatalf("Failed to read the CA bundle - %v", err)
+		}
+
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(pem) {
+			logrus.Fatalf("No certificate found in the CA bundle %s.", cfg.TLSCACertPath)
+		}
+	}
+
+	if cfg.TLSClientCertPath != "" || cfg.TLSClientKeyPath != "" {
+		certificate, err := tls.LoadX509KeyPair(cfg.TLSClientCertPath, cfg.TLSClientKeyPath)
+		if err != nil {
+			logrus.Fatalf("Failed to load the client certificate - %v", err)
+		}
+
+		tlsConfig.Certificates = []tls.Certificate{certificate}
+	}
+
+	return tlsConfig
+}
+
+// credentialsProvider returns the authentication headers of each function. All the methods are safe to call on a nil
+// provider, which is the case when authentication is disabled.
+type credentialsProvider struct {
+	header string
+	prefix string
+
+	token       string
+	perFunction map[string]string
+}
+
+func newCredentialsProvider(cfg *config.LoaderConfiguration) *credentialsProvider {
+	provider := &credentialsProvider{}
+
+	switch cfg.AuthScheme {
+	case "":
+		return nil
+	case common.AuthSchemeBearer:
+		provider.header, provider.prefix = "Authorization", "Bearer "
+	case common.AuthSchemeAPIKey:
+		provider.header = cfg.AuthHeader
+		if provider.header == "" {
+			provider.header = common.DefaultAPIKeyHeader
+		}
+	default:
+		logrus.Fatalf("Unsupported authentication scheme %s.", cfg.AuthScheme)
+	}
+
+	provider.token = cfg.AuthToken
+	if cfg.AuthTokenEnv != "" {
+		provider.token = os.Getenv(cfg.AuthTokenEnv)
+	}
+
+	if cfg.AuthSecretsPath != "" {
+		provider.perFunction = readSecrets(cfg.AuthSecretsPath)
+	}
+
+	return provider
+}
+
+// readSecrets reads a JSON object mapping function names to their tokens, where a token of the form env:NAME is read
+// from the environment variable NAME
+func readSecrets(path string) map[string]string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		logrus.Fatalf("Failed to read the secrets file - %v", err)
+	}
+
+	var secrets map[string]string
+	if err = json.Unmarshal(data, &secrets); err != nil {
+		logrus.Fatalf("Failed to parse the secrets file - %v", err)
+	}
+
+	for function, secret := range secrets {
+		if variable, ok := strings.CutPrefix(secret, "env:"); ok {
+			secrets[function] = os.Getenv(variable)
+		}
+	}
+
+	return secrets
+}
+
+func (p *credentialsProvider) headers(functionName string) map[string]string {
+	if p == nil {
+		return nil
+	}
+
+	token, ok := p.perFunction[functionName]
+	if !ok {
+		token = p.token
+	}
+
+	if token == "" {
+		logrus.Debugf("No credentials found for function %s.", functionName)
+		return nil
+	}
+
+	return map[string]string{p.header: p.prefix + token}
+}
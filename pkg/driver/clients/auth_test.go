@@ -0,0 +1,210 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2023 EASL and the vHive community
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package clients
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/pem"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/vhive-serverless/loader/pkg/common"
+	"github.com/vhive-serverless/loader/pkg/config"
+	"github.com/vhive-serverless/loader/pkg/workload/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestCredentialsProvider(t *testing.T) {
+	secretsPath := filepath.Join(t.TempDir(), "secrets.json")
+	err := os.WriteFile(secretsPath, []byte(`{"f1": "token-f1", "f2": "env:TEST_TOKEN_F2"}`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("TEST_TOKEN_F2", "token-f2")
+	t.Setenv("TEST_TOKEN", "token-global")
+
+	tests := []struct {
+		testName        string
+		cfg             config.LoaderConfiguration
+		function        string
+		expectedHeaders map[string]string
+	}{
+		{
+			testName:        "disabled",
+			cfg:             config.LoaderConfiguration{},
+			function:        "f1",
+			expectedHeaders: nil,
+		},
+		{
+			testName:        "bearer_global_token",
+			cfg:             config.LoaderConfiguration{AuthScheme: common.AuthSchemeBearer, AuthToken: "token"},
+			function:        "f1",
+			expectedHeaders: map[string]string{"Authorization": "Bearer token"},
+		},
+		{
+			testName:        "api_key_from_environment",
+			cfg:             config.LoaderConfiguration{AuthScheme: common.AuthSchemeAPIKey, AuthTokenEnv: "TEST_TOKEN"},
+			function:        "f1",
+			expectedHeaders: map[string]string{common.DefaultAPIKeyHeader: "token-global"},
+		},
+		{
+			testName:        "per_function_secret",
+			cfg:             config.LoaderConfiguration{AuthScheme: common.AuthSchemeBearer, AuthToken: "token", AuthSecretsPath: secretsPath},
+			function:        "f1",
+			expectedHeaders: map[string]string{"Authorization": "Bearer token-f1"},
+		},
+		{
+			testName:        "per_function_secret_from_environment",
+			cfg:             config.LoaderConfiguration{AuthScheme: common.AuthSchemeAPIKey, AuthHeader: "X-Key", AuthSecretsPath: secretsPath},
+			function:        "f2",
+			expectedHeaders: map[string]string{"X-Key": "token-f2"},
+		},
+		{
+			testName:        "no_secret",
+			cfg:             config.LoaderConfiguration{AuthScheme: common.AuthSchemeBearer, AuthSecretsPath: secretsPath},
+			function:        "f3",
+			expectedHeaders: nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.testName, func(t *testing.T) {
+			headers := newCredentialsProvider(&test.cfg).headers(test.function)
+
+			if len(headers) != len(test.expectedHeaders) {
+				t.Fatalf("Unexpected headers %v.", headers)
+			}
+			for key, value := range test.expectedHeaders {
+				if headers[key] != value {
+					t.Errorf("Unexpected value %q of header %s.", headers[key], key)
+				}
+			}
+		})
+	}
+}
+
+// writeCertificate writes the certificate of the test server to a PEM file, to be used as a CA bundle
+func writeCertificate(t *testing.T, server *httptest.Server) string {
+	path := filepath.Join(t.TempDir(), "ca.pem")
+
+	data := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func TestHTTPClientWithTLS(t *testing.T) {
+	for _, invokeProtocol := range []string{"http1", "http2"} {
+		t.Run(invokeProtocol, func(t *testing.T) {
+			server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get("Authorization") != "Bearer secret" {
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+
+				_ = json.NewEncoder(w).Encode(FunctionResponse{Status: "OK", Function: "instance", ExecutionTime: 1000})
+			}))
+			server.EnableHTTP2 = invokeProtocol == "http2"
+			server.StartTLS()
+			defer server.Close()
+
+			cfg := createFakeLoaderConfiguration()
+			cfg.Platform = "Dirigent"
+			cfg.InvokeProtocol = invokeProtocol
+			cfg.EnableTLS = true
+			cfg.TLSCACertPath = writeCertificate(t, server)
+			cfg.TLSServerName = "example.com"
+			cfg.AuthScheme = common.AuthSchemeBearer
+			cfg.AuthToken = "secret"
+
+			function := &common.Function{Name: "f", Endpoint: server.Listener.Addr().String()}
+			success, record := CreateInvoker(cfg, nil, nil).Invoke(context.Background(), function, &testRuntimeSpecs)
+
+			if !success || record.Instance != "instance" {
+				t.Errorf("Invocation over TLS has failed - status code %d.", record.HttpStatusCode)
+			}
+		})
+	}
+}
+
+type authenticatedServer struct {
+	proto.UnimplementedExecutorServer
+}
+
+func (s *authenticatedServer) Execute(ctx context.Context, _ *proto.FaasRequest) (*proto.FaasReply, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if values := md.Get("authorization"); len(values) != 1 || values[0] != "Bearer secret" {
+		return nil, status.Error(codes.Unauthenticated, "invalid token")
+	}
+
+	return &proto.FaasReply{Message: "instance", DurationInMicroSec: 1000, MemoryUsageInKb: 1024}, nil
+}
+
+func TestGRPCClientWithTLS(t *testing.T) {
+	// the test server provides a certificate for example.com, which is reused by the gRPC server
+	certificateServer := httptest.NewTLSServer(http.NotFoundHandler())
+	defer certificateServer.Close()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	grpcServer := grpc.NewServer(grpc.Creds(credentials.NewTLS(certificateServer.TLS)))
+	proto.RegisterExecutorServer(grpcServer, &authenticatedServer{})
+	go func() { _ = grpcServer.Serve(listener) }()
+	defer grpcServer.Stop()
+
+	cfg := createFakeLoaderConfiguration()
+	cfg.EnableZipkinTracing = false
+	cfg.EnableTLS = true
+	cfg.TLSCACertPath = writeCertificate(t, certificateServer)
+	cfg.TLSServerName = "example.com"
+	cfg.AuthScheme = common.AuthSchemeBearer
+	cfg.AuthToken = "secret"
+
+	function := &common.Function{Name: "f", Endpoint: listener.Addr().String()}
+	success, record := CreateInvoker(cfg, nil, nil).Invoke(context.Background(), function, &testRuntimeSpecs)
+
+	if !success || record.Instance != "instance" {
+		t.Error("Invocation over TLS has failed.")
+	}
+
+	cfg.AuthToken = "wrong"
+	if success, _ = CreateInvoker(cfg, nil, nil).Invoke(context.Background(), function, &testRuntimeSpecs); success {
+		t.Error("Invocation with a wrong token should have failed.")
+	}
+}
@@ -1,15 +1,28 @@
 package clients
 
 import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	log "github.com/sirupsen/logrus"
 	"github.com/vhive-serverless/loader/pkg/common"
 	mc "github.com/vhive-serverless/loader/pkg/metric"
 	"io"
+	"net/http"
 	"sync"
+	"time"
 )
 
+type HTTPResBody struct {
+	DurationInMicroSec uint32 `json:"DurationInMicroSec"`
+	MemoryUsageInKb    uint32 `json:"MemoryUsageInKb"`
+	FirstRequest       bool   `json:"FirstRequest"`
+	Uptime             int64  `json:"Uptime"`
+}
+
 type awsLambdaInvoker struct {
 	announceDoneExe *sync.WaitGroup
 }
@@ -20,14 +33,17 @@ func newAWSLambdaInvoker(announceDoneExe *sync.WaitGroup) *awsLambdaInvoker {
 	}
 }
 
-func (i *awsLambdaInvoker) Invoke(function *common.Function, runtimeSpec *common.RuntimeSpecification) (bool, *mc.ExecutionRecord) {
+func (i *awsLambdaInvoker) Invoke(ctx context.Context, function *common.Function, runtimeSpec *common.RuntimeSpecification) (bool, *mc.ExecutionRecord) {
 	log.Tracef("(Invoke)\t %s: %d[ms], %d[MiB]", function.Name, runtimeSpec.Runtime, runtimeSpec.Memory)
 
 	dataString := fmt.Sprintf(`{"RuntimeInMilliSec": %d, "MemoryInMebiBytes": %d}`, runtimeSpec.Runtime, runtimeSpec.Memory)
-	success, executionRecordBase, res := httpInvocation(dataString, function, i.announceDoneExe, false)
+	success, executionRecordBase, res := httpInvocation(ctx, dataString, function, i.announceDoneExe, false)
 
 	executionRecordBase.RequestedDuration = uint32(runtimeSpec.Runtime * 1e3)
 	record := &mc.ExecutionRecord{ExecutionRecordBase: *executionRecordBase}
+	if res != nil {
+		record.HttpStatusCode = res.StatusCode
+	}
 
 	if !success {
 		return false, record
@@ -52,7 +68,107 @@ func (i *awsLambdaInvoker) Invoke(function *common.Function, runtimeSpec *common
 	record.ActualDuration = httpResBody.DurationInMicroSec
 	record.ActualMemoryUsage = common.Kib2Mib(httpResBody.MemoryUsageInKb)
 
+	// the log tail is only returned by the Invoke API, while invocations through a function URL rely on the handler
+	if initDuration, ok := parseLambdaInitDuration(res.Header.Get("X-Amz-Log-Result")); ok {
+		record.StartType = mc.Cold
+		record.InitTime = initDuration
+	} else {
+		classifyStart(record, httpResBody.FirstRequest, httpResBody.Uptime)
+	}
+
 	logInvocationSummary(function, &record.ExecutionRecordBase, res)
 
 	return true, record
 }
+
+func httpInvocation(ctx context.Context, dataString string, function *common.Function, AnnounceDoneExe *sync.WaitGroup, tlsSkipVerify bool) (bool, *mc.ExecutionRecordBase, *http.Response) {
+	defer AnnounceDoneExe.Done()
+
+	record := &mc.ExecutionRecordBase{}
+
+	start := time.Now()
+	record.StartTime = start.UnixMicro()
+	record.Instance = function.Name
+	requestURL := function.Endpoint
+	if tlsSkipVerify {
+		http.DefaultTransport.(*http.Transport).TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	}
+
+	if dataString != "" {
+		requestURL += "?" + dataString
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL, bytes.NewBuffer([]byte("")))
+	if err != nil {
+		log.Warnf("http request creation failed for function %s - %s", function.Name, err)
+
+		record.ResponseTime = time.Since(start).Microseconds()
+		record.ConnectionTimeout = true
+
+		return false, record, nil
+	}
+
+	req.Header.Set("Content-Type", "application/json") // To avoid data being base64encoded
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		log.Debugf("http request for function %s failed - %s", function.Name, err)
+
+		record.ResponseTime = time.Since(start).Microseconds()
+		record.ConnectionTimeout = true
+
+		return false, record, resp
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		log.Debugf("http request for function %s failed - error code: %s", function.Name, resp.Status)
+
+		record.ResponseTime = time.Since(start).Microseconds()
+		record.ConnectionTimeout = true
+
+		return false, record, resp
+	}
+
+	bodyBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		log.Warnf("Failed to read output %s - %v", function.Name, err)
+
+		record.ResponseTime = time.Since(start).Microseconds()
+		record.FunctionTimeout = true
+
+		return false, record, resp
+	}
+
+	rawJson, err := base64.StdEncoding.DecodeString(string(bodyBytes))
+	if err != nil {
+		log.Warnf("Failed to decode base64 output %s - %v", function.Name, err)
+
+		record.ResponseTime = time.Since(start).Microseconds()
+		record.FunctionTimeout = true
+
+		return false, record, resp
+	}
+
+	var deserializedResponse FunctionResponse
+	err = json.Unmarshal(rawJson, &deserializedResponse)
+	if err != nil {
+		log.Warnf("Failed to deserialize response %s - %v", function.Name, err)
+
+		record.ResponseTime = time.Since(start).Microseconds()
+		record.FunctionTimeout = true
+
+		return false, record, resp
+	}
+
+	record.Instance = deserializedResponse.Function
+	record.ResponseTime = time.Since(start).Microseconds()
+	record.ActualDuration = uint32(deserializedResponse.ExecutionTime)
+
+	return true, record, resp
+}
+
+func logInvocationSummary(function *common.Function, record *mc.ExecutionRecordBase, res *http.Response) {
+	log.Tracef("(Replied)\t %s: %d[ms]", function.Name, record.ActualDuration)
+	log.Tracef("(E2E Latency) %s: %.2f[ms]\n", function.Name, float64(record.ResponseTime)/1e3)
+	log.Tracef("(Client status code) %s: %d", function.Name, res.StatusCode)
+}
@@ -2,12 +2,15 @@ package clients
 
 import (
 	"bytes"
+	"encoding/binary"
 	"fmt"
-	"github.com/sirupsen/logrus"
+	"math"
+	"strings"
+
 	"github.com/vhive-serverless/loader/pkg/common"
+	"github.com/vhive-serverless/loader/pkg/config"
 	"github.com/vhive-serverless/loader/pkg/metric"
 	"go.mongodb.org/mongo-driver/bson"
-	"strings"
 )
 
 type InputItem struct {
@@ -30,79 +33,137 @@ type DandelionDeserializeResponse struct {
 	Sets []InputSet `bson:"sets"`
 }
 
-/*func composeDandelionMatMulBody(functionName string) *bytes.Buffer {
-	request := DandelionRequest{
-		Name: functionName,
-		Sets: []InputSet{
-			{
-				Identifier: "",
-				Items: []InputItem{
-					{
-						Identifier: "",
-						Key:        0,
-						Data:       []byte{1, 0, 0, 0, 0, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0, 0},
-					},
-				},
-			},
-		},
+// dandelionInvocation is a request to Dandelion along with what its output sets are expected to hold
+type dandelionInvocation struct {
+	kernel  string
+	request DandelionRequest
+
+	// matrixSide Number of rows and columns of the input matrix of the matmul kernel
+	matrixSide int
+	// inputSize Number of bytes of the input of the echo kernel
+	inputSize int
+}
+
+// composeDandelionInvocation builds the input set of the configured kernel. Its size is given by the modelled request
+// size of the invocation, if any, or by DandelionInputSizeBytes.
+func composeDandelionInvocation(cfg *config.LoaderConfiguration, function *common.Function, runtimeSpec *common.RuntimeSpecification) (*dandelionInvocation, error) {
+	// at most one of the function and the composition name is set
+	name := function.Name
+	if cfg.DandelionFunctionName != "" {
+		name = cfg.DandelionFunctionName
+	} else if cfg.DandelionCompositionName != "" {
+		name = cfg.DandelionCompositionName
 	}
-	body, err := bson.Marshal(request)
-	if err != nil {
-		logrus.Debugf("Error encoding Dandelion MatMul request - %v", err)
-		return nil
+
+	inputSize := cfg.DandelionInputSizeBytes
+	if runtimeSpec.RequestSize > 0 {
+		inputSize = runtimeSpec.RequestSize
+	}
+
+	invocation := &dandelionInvocation{
+		kernel:  cfg.DandelionKernel,
+		request: DandelionRequest{Name: name},
+	}
+
+	var items []InputItem
+	switch invocation.kernel {
+	case "", common.DandelionBusyLoopKernel:
+		metadata := function.DirigentMetadata
+		if metadata == nil {
+			return nil, fmt.Errorf("no Dirigent metadata for function %s", function.Name)
+		}
+
+		runtimeScale := cfg.DandelionRuntimeMultiplier
+		if runtimeScale <= 0 {
+			runtimeScale = common.DefaultDandelionRuntimeMultiplier
+		}
+		items = []InputItem{{
+			Identifier: "input.csv",
+			Data:       []byte(fmt.Sprintf("%s,%s,%d,%d", function.Name, metadata.Image, runtimeSpec.Runtime*runtimeScale, metadata.IterationMultiplier)),
+		}}
+	case common.DandelionMatMulKernel:
+		// a square matrix of 64-bit integers, preceded by its number of rows
+		invocation.matrixSide = 1
+		if inputSize > 16 {
+			invocation.matrixSide = int(math.Sqrt(float64(inputSize-8) / 8))
+		}
+		items = []InputItem{{Data: composeMatrix(invocation.matrixSide)}}
+	case common.DandelionEchoKernel:
+		invocation.inputSize = common.MinOf(inputSize, common.MaxPayloadSizeBytes)
+		items = []InputItem{{Data: payload(invocation.inputSize)}}
+	default:
+		return nil, fmt.Errorf("unsupported Dandelion kernel %s", invocation.kernel)
 	}
-	return bytes.NewBuffer(body)
-}*/
-
-func composeBusyLoopBody(functionName, image string, runtime, iterations int) *bytes.Buffer {
-	request := DandelionRequest{
-		Name: functionName,
-		Sets: []InputSet{
-			{
-				Identifier: "",
-				Items: []InputItem{
-					{
-						Identifier: "input.csv",
-						Key:        0,
-						Data: []byte(fmt.Sprintf(
-							"%s,%s,%d,%d",
-							functionName,
-							image,
-							// TODO: bug in the current image '\0'. Remove '* 10' when new image is applied
-							runtime*10,
-							iterations,
-						)),
-					},
-				},
-			},
-		},
+
+	invocation.request.Sets = []InputSet{{Identifier: cfg.DandelionInputSetName, Items: items}}
+
+	return invocation, nil
+}
+
+func composeMatrix(side int) []byte {
+	data := make([]byte, 8*(1+side*side))
+	binary.LittleEndian.PutUint64(data, uint64(side))
+
+	for i := 0; i < side*side; i++ {
+		binary.LittleEndian.PutUint64(data[8*(i+1):], uint64(i%side+1))
 	}
 
-	body, err := bson.Marshal(request)
+	return data
+}
+
+func (d *dandelionInvocation) body() (*bytes.Buffer, error) {
+	body, err := bson.Marshal(d.request)
 	if err != nil {
-		logrus.Debugf("Error encoding Dandelion MatMul request - %v", err)
-		return nil
+		return nil, fmt.Errorf("error encoding Dandelion request - %v", err)
 	}
 
-	return bytes.NewBuffer(body)
+	return bytes.NewBuffer(body), nil
 }
 
-func DeserializeDandelionResponse(function *common.Function, body []byte, record *metric.ExecutionRecord) error {
+// deserializeResponse checks the output sets of a Dandelion invocation against what the kernel is expected to produce
+// and marks the invocation as failed if they do not match
+func (d *dandelionInvocation) deserializeResponse(function *common.Function, body []byte, record *metric.ExecutionRecord) error {
 	var result DandelionDeserializeResponse
 	err := bson.Unmarshal(body, &result)
 	if err != nil {
 		return fmt.Errorf("error deserializing response body - %v", err)
 	}
 
-	rawResponseData := result.Sets[0].Items[0].Data
-	data := strings.Split(string(rawResponseData), ",")
+	record.Instance = function.Name
+	record.ActualDuration = 0 // Dandelion does not report the execution time
 
-	if len(data) > 0 && !strings.Contains(strings.ToLower(data[0]), "ok") {
-		record.FunctionTimeout = false
+	var items []InputItem
+	for _, set := range result.Sets {
+		items = append(items, set.Items...)
+	}
+	if len(items) == 0 {
+		record.FunctionTimeout = true
+		return fmt.Errorf("response of %s contains no output items", function.Name)
 	}
 
-	record.Instance = function.Name
-	record.ActualDuration = 0 // this field is not used yet in benchmark
+	switch d.kernel {
+	case "", common.DandelionBusyLoopKernel:
+		data := strings.Split(string(items[0].Data), ",")
+		if !strings.Contains(strings.ToLower(data[0]), "ok") {
+			record.FunctionTimeout = true
+			return fmt.Errorf("busy loop of %s has failed - %s", function.Name, string(items[0].Data))
+		}
+	case common.DandelionMatMulKernel:
+		data := items[0].Data
+		if len(data) != 8*(1+d.matrixSide*d.matrixSide) || binary.LittleEndian.Uint64(data) != uint64(d.matrixSide) {
+			record.FunctionTimeout = true
+			return fmt.Errorf("unexpected %d bytes of output for a %dx%d matrix", len(data), d.matrixSide, d.matrixSide)
+		}
+	case common.DandelionEchoKernel:
+		outputSize := 0
+		for _, item := range items {
+			outputSize += len(item.Data)
+		}
+		if outputSize != d.inputSize {
+			record.FunctionTimeout = true
+			return fmt.Errorf("unexpected %d bytes of output for %d bytes of input", outputSize, d.inputSize)
+		}
+	}
 
 	return nil
 }
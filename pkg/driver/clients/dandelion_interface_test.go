@@ -0,0 +1,159 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2023 EASL and the vHive community
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package clients
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/vhive-serverless/loader/pkg/common"
+	"github.com/vhive-serverless/loader/pkg/config"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// newFakeDandelion returns a server running the kernels on the input sets, the busy loop failing for functions named
+// "failing"
+func newFakeDandelion(t *testing.T, expectedPath string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != expectedPath {
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+
+		body, _ := io.ReadAll(r.Body)
+
+		var request DandelionRequest
+		if err := bson.Unmarshal(body, &request); err != nil || len(request.Sets) != 1 || len(request.Sets[0].Items) != 1 {
+			t.Errorf("Unexpected request %+v - %v", request, err)
+			return
+		}
+
+		input := request.Sets[0].Items[0]
+		output := input.Data
+
+		switch input.Identifier {
+		case "input.csv":
+			output = []byte("OK")
+			if strings.HasPrefix(string(input.Data), "failing,") {
+				output = []byte("FAILED")
+			}
+		case "":
+			if request.Name == "matmul-composition" {
+				// A * A^T keeps the dimensions of a square matrix
+				side := binary.LittleEndian.Uint64(input.Data)
+				output = make([]byte, 8*(1+side*side))
+				binary.LittleEndian.PutUint64(output, side)
+			}
+		}
+
+		response, _ := bson.Marshal(DandelionDeserializeResponse{
+			Sets: []InputSet{{Identifier: "output", Items: []InputItem{{Identifier: "result", Data: output}}}},
+		})
+		_, _ = w.Write(response)
+	}))
+}
+
+func TestDandelionKernels(t *testing.T) {
+	tests := []struct {
+		name        string
+		kernel      string
+		function    string
+		composition string
+		requestSize int
+		success     bool
+	}{
+		{name: "busyloop", kernel: "", function: "test-function", success: true},
+		{name: "busyloop_failing", kernel: common.DandelionBusyLoopKernel, function: "failing", success: false},
+		{name: "matmul", kernel: common.DandelionMatMulKernel, function: "test-function", composition: "matmul-composition", requestSize: 8 + 8*64*64, success: true},
+		{name: "matmul_minimal", kernel: common.DandelionMatMulKernel, function: "test-function", composition: "matmul-composition", success: true},
+		{name: "echo", kernel: common.DandelionEchoKernel, function: "test-function", requestSize: 100_000, success: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := newFakeDandelion(t, "/hot/compute")
+			defer server.Close()
+
+			invoker := CreateInvoker(&config.LoaderConfiguration{
+				Platform:                   "Dirigent-Dandelion",
+				InvokeProtocol:             "http1",
+				GRPCFunctionTimeoutSeconds: 15,
+				DandelionKernel:            test.kernel,
+				DandelionCompositionName:   test.composition,
+				DandelionInvocationPath:    "/hot/compute",
+			}, nil, nil)
+
+			function := &common.Function{
+				Name:             test.function,
+				Endpoint:         strings.TrimPrefix(server.URL, "http://"),
+				DirigentMetadata: &common.DirigentMetadata{Image: "busy-loop", IterationMultiplier: 80},
+			}
+
+			success, record := invoker.Invoke(context.Background(), function, &common.RuntimeSpecification{Runtime: 10, RequestSize: test.requestSize})
+			if success != test.success || record.FunctionTimeout == test.success {
+				t.Errorf("Unexpected outcome of the invocation - %+v", record)
+			}
+			if test.requestSize > 0 && record.BytesSent < int64(test.requestSize) {
+				t.Errorf("Input set of %d bytes is smaller than the requested %d bytes.", record.BytesSent, test.requestSize)
+			}
+		})
+	}
+}
+
+func TestComposeDandelionInvocation(t *testing.T) {
+	cfg := &config.LoaderConfiguration{
+		DandelionKernel:       common.DandelionBusyLoopKernel,
+		DandelionFunctionName: "busy",
+		DandelionInputSetName: "inputs",
+	}
+	function := &common.Function{
+		Name:             "test-function",
+		DirigentMetadata: &common.DirigentMetadata{Image: "busy-loop", IterationMultiplier: 80},
+	}
+
+	invocation, err := composeDandelionInvocation(cfg, function, &common.RuntimeSpecification{Runtime: 25})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	request := invocation.request
+	if request.Name != "busy" || request.Sets[0].Identifier != "inputs" || string(request.Sets[0].Items[0].Data) != "test-function,busy-loop,250,80" {
+		t.Errorf("Unexpected busy loop request %+v", request)
+	}
+
+	cfg.DandelionKernel = common.DandelionMatMulKernel
+	if invocation, _ = composeDandelionInvocation(cfg, function, &common.RuntimeSpecification{RequestSize: 1000}); invocation.matrixSide != 11 {
+		t.Errorf("Expected an 11x11 matrix, got %d.", invocation.matrixSide)
+	}
+
+	cfg.DandelionKernel = "unknown"
+	if _, err = composeDandelionInvocation(cfg, function, &common.RuntimeSpecification{}); err == nil {
+		t.Error("Expected an unknown kernel to be rejected.")
+	}
+}
@@ -0,0 +1,300 @@
+package clients
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/vhive-serverless/loader/pkg/common"
+	"github.com/vhive-serverless/loader/pkg/config"
+	mc "github.com/vhive-serverless/loader/pkg/metric"
+)
+
+// genericHTTPInvoker invokes functions on platforms the loader has no dedicated support for, by sending the HTTP
+// request described by the configured templates and extracting the measurements from the response with JSONPath
+type genericHTTPInvoker struct {
+	client      *http.Client
+	credentials *credentialsProvider
+
+	url     *template.Template
+	method  *template.Template
+	body    *template.Template
+	headers map[string]*template.Template
+
+	durationPath string
+	instancePath string
+	memoryPath   string
+}
+
+func newGenericHTTPInvoker(cfg *config.LoaderConfiguration) *genericHTTPInvoker {
+	if cfg.GenericHTTPURL == "" {
+		log.Fatal("GenericHTTPURL has to be set on the GenericHTTP platform.")
+	}
+
+	method := cfg.GenericHTTPMethod
+	if method == "" {
+		method = http.MethodPost
+	}
+
+	invoker := &genericHTTPInvoker{
+		client:      CreateHTTPClient(cfg.GRPCFunctionTimeoutSeconds, cfg.InvokeProtocol, createTLSConfig(cfg)),
+		credentials: newCredentialsProvider(cfg),
+
+		url:     mustParseTemplate("GenericHTTPURL", cfg.GenericHTTPURL),
+		method:  mustParseTemplate("GenericHTTPMethod", method),
+		headers: make(map[string]*template.Template),
+
+		durationPath: cfg.GenericHTTPDurationPath,
+		instancePath: cfg.GenericHTTPInstancePath,
+		memoryPath:   cfg.GenericHTTPMemoryPath,
+	}
+
+	// without a body template, the raw payload is sent
+	if cfg.GenericHTTPBody != "" {
+		invoker.body = mustParseTemplate("GenericHTTPBody", cfg.GenericHTTPBody)
+	}
+	for key, value := range cfg.GenericHTTPHeaders {
+		invoker.headers[key] = mustParseTemplate("GenericHTTPHeaders", value)
+	}
+
+	for _, path := range []string{invoker.durationPath, invoker.instancePath, invoker.memoryPath} {
+		if _, err := parseJSONPath(path); err != nil {
+			log.Fatalf("Invalid JSONPath %s - %v", path, err)
+		}
+	}
+
+	return invoker
+}
+
+func (i *genericHTTPInvoker) Invoke(ctx context.Context, function *common.Function, runtimeSpec *common.RuntimeSpecification) (bool, *mc.ExecutionRecord) {
+	log.Tracef("(Invoke)\t %s: %d[ms], %d[MiB]", function.Name, runtimeSpec.Runtime, runtimeSpec.Memory)
+
+	record := &mc.ExecutionRecord{
+		ExecutionRecordBase: mc.ExecutionRecordBase{
+			RequestedDuration: uint32(runtimeSpec.Runtime * 1e3),
+		},
+	}
+
+	start := time.Now()
+	record.StartTime = start.UnixMicro()
+
+	req, err := i.createRequest(ctx, function, runtimeSpec)
+	if err != nil {
+		log.Errorf("%s - Failed to create a HTTP request - %v", function.Name, err)
+
+		record.ResponseTime = time.Since(start).Microseconds()
+		record.ConnectionTimeout = true
+
+		return false, record
+	}
+	record.BytesSent = req.ContentLength
+
+	resp, err := i.client.Do(req)
+	if err != nil {
+		log.Errorf("%s - Failed to send an HTTP request to the server - %v", function.Name, err)
+
+		record.ResponseTime = time.Since(start).Microseconds()
+		record.ConnectionTimeout = true
+
+		return false, record
+	}
+
+	record.GRPCConnectionEstablishTime = time.Since(start).Microseconds()
+	record.HttpStatusCode = resp.StatusCode
+
+	defer HandleBodyClosing(resp)
+	body, err := io.ReadAll(resp.Body)
+	record.BytesReceived = int64(len(body))
+	record.ResponseTime = time.Since(start).Microseconds()
+
+	if err != nil || resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		if err != nil {
+			log.Errorf("HTTP request failed - %s - %v", function.Name, err)
+		} else {
+			log.Errorf("HTTP request failed - %s - %s - response: %v - status code: %d", function.Name, req.URL, string(body), resp.StatusCode)
+		}
+
+		record.FunctionTimeout = true
+
+		return false, record
+	}
+
+	if err = i.parseResponse(body, record); err != nil {
+		log.Warnf("Failed to parse the response of %s - %v - %v", function.Name, string(body), err)
+	}
+
+	log.Tracef("(E2E Latency) %s: %.2f[ms]\n", function.Name, float64(record.ResponseTime)/1e3)
+
+	return true, record
+}
+
+func (i *genericHTTPInvoker) createRequest(ctx context.Context, function *common.Function, runtimeSpec *common.RuntimeSpecification) (*http.Request, error) {
+	requestPayload := payload(runtimeSpec.RequestSize)
+	data := newRequestTemplateData(ctx, function, runtimeSpec, requestPayload)
+
+	requestURL, err := executeTemplate(i.url, data)
+	if err != nil {
+		return nil, err
+	}
+	method, err := executeTemplate(i.method, data)
+	if err != nil {
+		return nil, err
+	}
+
+	body := requestPayload
+	if i.body != nil {
+		rendered, err := executeTemplate(i.body, data)
+		if err != nil {
+			return nil, err
+		}
+		body = []byte(rendered)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, strings.ToUpper(strings.TrimSpace(method)), strings.TrimSpace(requestURL), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	setRequestHeaders(ctx, req.Header)
+	for key, value := range i.credentials.headers(function.Name) {
+		req.Header.Set(key, value)
+	}
+	for key, tmpl := range i.headers {
+		value, err := executeTemplate(tmpl, data)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set(key, value)
+	}
+
+	return req, nil
+}
+
+// parseResponse extracts the execution time in milliseconds, the instance and the memory usage in MiB from the
+// response, as far as the respective paths are configured
+func (i *genericHTTPInvoker) parseResponse(body []byte, record *mc.ExecutionRecord) error {
+	if i.durationPath == "" && i.instancePath == "" && i.memoryPath == "" {
+		return nil
+	}
+
+	var document any
+	if err := json.Unmarshal(body, &document); err != nil {
+		return err
+	}
+
+	if i.durationPath != "" {
+		duration, err := lookupJSONNumber(document, i.durationPath)
+		if err != nil {
+			return err
+		}
+		record.ActualDuration = uint32(duration * 1e3) // ms to µs
+	}
+	if i.instancePath != "" {
+		instance, err := lookupJSONPath(document, i.instancePath)
+		if err != nil {
+			return err
+		}
+		record.Instance = fmt.Sprint(instance)
+	}
+	if i.memoryPath != "" {
+		memory, err := lookupJSONNumber(document, i.memoryPath)
+		if err != nil {
+			return err
+		}
+		record.ActualMemoryUsage = uint32(memory)
+	}
+
+	return nil
+}
+
+// parseJSONPath splits a JSONPath such as $.result.durations[0] into its keys and indices. Only the child and index
+// operators are supported.
+func parseJSONPath(path string) ([]any, error) {
+	path = strings.TrimPrefix(strings.TrimPrefix(path, "$"), ".")
+	if path == "" {
+		return nil, nil
+	}
+
+	var steps []any
+	for _, segment := range strings.Split(path, ".") {
+		key, rest, _ := strings.Cut(segment, "[")
+		if key != "" {
+			steps = append(steps, key)
+		} else if rest == "" {
+			return nil, fmt.Errorf("empty key")
+		}
+
+		for rest != "" {
+			index, remainder, ok := strings.Cut(rest, "]")
+			if !ok {
+				return nil, fmt.Errorf("unterminated index")
+			}
+
+			value, err := strconv.Atoi(index)
+			if err != nil || value < 0 {
+				return nil, fmt.Errorf("invalid index %s", index)
+			}
+			steps = append(steps, value)
+
+			if remainder != "" && !strings.HasPrefix(remainder, "[") {
+				return nil, fmt.Errorf("unexpected %s after index", remainder)
+			}
+			rest = strings.TrimPrefix(remainder, "[")
+		}
+	}
+
+	return steps, nil
+}
+
+func lookupJSONPath(document any, path string) (any, error) {
+	steps, err := parseJSONPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	current := document
+	for _, step := range steps {
+		switch step := step.(type) {
+		case string:
+			object, ok := current.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("%s - %s is not a field of an object", path, step)
+			}
+			if current, ok = object[step]; !ok {
+				return nil, fmt.Errorf("%s - field %s not found", path, step)
+			}
+		case int:
+			array, ok := current.([]any)
+			if !ok || step >= len(array) {
+				return nil, fmt.Errorf("%s - index %d out of range", path, step)
+			}
+			current = array[step]
+		}
+	}
+
+	return current, nil
+}
+
+func lookupJSONNumber(document any, path string) (float64, error) {
+	value, err := lookupJSONPath(document, path)
+	if err != nil {
+		return 0, err
+	}
+
+	switch value := value.(type) {
+	case float64:
+		return value, nil
+	case string:
+		return strconv.ParseFloat(value, 64)
+	default:
+		return 0, fmt.Errorf("%s - %v is not a number", path, value)
+	}
+}
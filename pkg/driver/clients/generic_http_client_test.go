@@ -0,0 +1,148 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2023 EASL and the vHive community
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package clients
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/vhive-serverless/loader/pkg/common"
+	"github.com/vhive-serverless/loader/pkg/config"
+)
+
+func TestParseJSONPath(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected []any
+		valid    bool
+	}{
+		{path: "", expected: nil, valid: true},
+		{path: "$", expected: nil, valid: true},
+		{path: "$.duration", expected: []any{"duration"}, valid: true},
+		{path: "result.stats[1].memory", expected: []any{"result", "stats", 1, "memory"}, valid: true},
+		{path: "$[0][2]", expected: []any{0, 2}, valid: true},
+		{path: "$.stats[x]", valid: false},
+		{path: "$.stats[0", valid: false},
+		{path: "$.a..b", valid: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.path, func(t *testing.T) {
+			steps, err := parseJSONPath(test.path)
+			if (err == nil) != test.valid {
+				t.Fatalf("Unexpected validity of %s - %v", test.path, err)
+			}
+			if test.valid && !reflect.DeepEqual(steps, test.expected) {
+				t.Errorf("Unexpected steps %v, expected %v.", steps, test.expected)
+			}
+		})
+	}
+}
+
+func TestGenericHTTPInvoker(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut || r.URL.Path != "/function/test-function" {
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if r.Header.Get("X-Memory") != "128" || r.Header.Get("X-Invocation") != "min0.inv3" {
+			t.Errorf("Unexpected headers %v", r.Header)
+		}
+
+		var body struct {
+			Runtime int    `json:"runtime"`
+			Payload string `json:"payload"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("Unexpected body - %v", err)
+		}
+		if payload, _ := base64.StdEncoding.DecodeString(body.Payload); body.Runtime != 10 || len(payload) != 64 {
+			t.Errorf("Unexpected body %+v", body)
+		}
+
+		_, _ = w.Write([]byte(`{"stats": {"durationMs": 12.5, "memoryMiB": "96"}, "pods": ["test-instance"]}`))
+	}))
+	defer server.Close()
+
+	invoker := CreateInvoker(&config.LoaderConfiguration{
+		Platform:                   "GenericHTTP",
+		InvokeProtocol:             "http1",
+		GRPCFunctionTimeoutSeconds: 15,
+		GenericHTTPURL:             server.URL + "/function/{{.Function}}",
+		GenericHTTPMethod:          "put",
+		GenericHTTPHeaders: map[string]string{
+			"X-Memory":     "{{.Memory}}",
+			"X-Invocation": "{{.InvocationID}}",
+		},
+		GenericHTTPBody:         `{"runtime": {{.Runtime}}, "payload": "{{.Payload}}"}`,
+		GenericHTTPDurationPath: "$.stats.durationMs",
+		GenericHTTPInstancePath: "$.pods[0]",
+		GenericHTTPMemoryPath:   "$.stats.memoryMiB",
+	}, nil, nil)
+
+	function := &common.Function{Name: "test-function"}
+	runtimeSpec := &common.RuntimeSpecification{Runtime: 10, Memory: 128, RequestSize: 64}
+
+	success, record := invoker.Invoke(WithInvocationID(context.Background(), "min0.inv3"), function, runtimeSpec)
+	if !success {
+		t.Fatal("Failed generic HTTP invocation.")
+	}
+
+	if record.ActualDuration != 12_500 || record.Instance != "test-instance" || record.ActualMemoryUsage != 96 {
+		t.Errorf("Unexpected record %+v", record)
+	}
+	if record.HttpStatusCode != http.StatusOK || record.BytesSent == 0 || record.BytesReceived == 0 {
+		t.Errorf("Unexpected record %+v", record)
+	}
+}
+
+func TestGenericHTTPInvokerWithRawPayload(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if r.Method != http.MethodPost || len(body) != 1024 {
+			t.Errorf("Unexpected request %s with %d bytes", r.Method, len(body))
+		}
+
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	invoker := CreateInvoker(&config.LoaderConfiguration{
+		Platform:                   "GenericHTTP",
+		InvokeProtocol:             "http1",
+		GRPCFunctionTimeoutSeconds: 15,
+		GenericHTTPURL:             server.URL,
+	}, nil, nil)
+
+	success, record := invoker.Invoke(context.Background(), &common.Function{Name: "test-function"}, &common.RuntimeSpecification{RequestSize: 1024})
+	if success || !record.FunctionTimeout || record.HttpStatusCode != http.StatusServiceUnavailable {
+		t.Errorf("Expected the invocation to fail, got %+v", record)
+	}
+}
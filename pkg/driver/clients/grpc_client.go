@@ -26,6 +26,7 @@ package clients
 
 import (
 	"context"
+	protov1 "github.com/golang/protobuf/proto"
 	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 	"github.com/vhive-serverless/loader/pkg/common"
@@ -34,7 +35,10 @@ import (
 	helloworld "github.com/vhive-serverless/vSwarm/utils/protobuf/helloworld"
 	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/metadata"
 	"strings"
 	"time"
 
@@ -51,11 +55,16 @@ type ExecutorRPC struct {
 func (i ExecutorRPC) Invoke(function *common.Function, runtimeSpec *common.RuntimeSpecification, conn *grpc.ClientConn, record *mc.ExecutionRecord, executionCxt context.Context) bool {
 	grpcClient := proto.NewExecutorClient(conn)
 
-	response, err := grpcClient.Execute(executionCxt, &proto.FaasRequest{
-		Message:           "nothing",
-		RuntimeInMilliSec: uint32(runtimeSpec.Runtime),
-		MemoryInMebiBytes: uint32(runtimeSpec.Memory),
-	})
+	request := &proto.FaasRequest{
+		Message:             "nothing",
+		RuntimeInMilliSec:   uint32(runtimeSpec.Runtime),
+		MemoryInMebiBytes:   uint32(runtimeSpec.Memory),
+		Payload:             payload(runtimeSpec.RequestSize),
+		ResponseSizeInBytes: uint32(runtimeSpec.ResponseSize),
+	}
+	record.BytesSent = int64(protov1.Size(request))
+
+	response, err := grpcClient.Execute(executionCxt, request)
 
 	if err != nil {
 		logrus.Debugf("gRPC timeout exceeded for function %s - %s", function.Name, err)
@@ -68,6 +77,8 @@ func (i ExecutorRPC) Invoke(function *common.Function, runtimeSpec *common.Runti
 
 	record.Instance = extractInstanceName(response.GetMessage())
 	record.ActualDuration = response.DurationInMicroSec
+	record.BytesReceived = int64(protov1.Size(response))
+	classifyStart(record, response.FirstRequest, int64(response.UptimeInMicroSec))
 
 	if strings.HasPrefix(response.GetMessage(), "FAILURE - mem_alloc") {
 		record.MemoryAllocationTimeout = true
@@ -111,16 +122,91 @@ func (i SayHelloRPC) Invoke(function *common.Function, runtimeSpec *common.Runti
 type grpcInvoker struct {
 	cfg     *config.LoaderConfiguration
 	invoker invoker
+	// pool is nil if a fresh connection is established for every invocation
+	pool *connectionPool
+
+	transportCredentials credentials.TransportCredentials
+	credentials          *credentialsProvider
 }
 
 func newGRPCInvoker(cfg *config.LoaderConfiguration, invoker invoker) *grpcInvoker {
-	return &grpcInvoker{
-		cfg:     cfg,
-		invoker: invoker,
+	i := &grpcInvoker{
+		cfg:                  cfg,
+		invoker:              invoker,
+		transportCredentials: insecure.NewCredentials(),
+		credentials:          newCredentialsProvider(cfg),
+	}
+
+	if tlsConfig := createTLSConfig(cfg); tlsConfig != nil {
+		i.transportCredentials = credentials.NewTLS(tlsConfig)
+	}
+
+	switch cfg.GRPCConnectionMode {
+	case "", common.GRPCConnectionPerCall:
+	case common.GRPCConnectionPooled:
+		i.pool = newConnectionPool(cfg.GRPCPoolSize, time.Duration(cfg.GRPCPoolIdleTimeoutSeconds)*time.Second, i.dial)
+	default:
+		logrus.Fatalf("Unsupported gRPC connection mode %s.", cfg.GRPCConnectionMode)
+	}
+
+	return i
+}
+
+// Close closes the pooled connections, if any
+func (i *grpcInvoker) Close() error {
+	if i.pool != nil {
+		i.pool.Close()
+	}
+
+	return nil
+}
+
+func (i *grpcInvoker) dial(endpoint string, authority string) (*grpc.ClientConn, error) {
+	var dialOptions []grpc.DialOption
+	dialOptions = append(dialOptions, grpc.WithTransportCredentials(i.transportCredentials))
+	if authority != "" {
+		dialOptions = append(dialOptions, grpc.WithAuthority(authority))
+	}
+	if i.cfg.EnableZipkinTracing {
+		dialOptions = append(dialOptions, grpc.WithStatsHandler(otelgrpc.NewClientHandler()))
+	}
+	if i.cfg.GRPCKeepaliveSeconds > 0 {
+		dialOptions = append(dialOptions, grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:                time.Duration(i.cfg.GRPCKeepaliveSeconds) * time.Second,
+			Timeout:             time.Duration(i.cfg.GRPCConnectionTimeoutSeconds) * time.Second,
+			PermitWithoutStream: true,
+		}))
+	}
+
+	return grpc.NewClient(endpoint, dialOptions...)
+}
+
+// connect returns a connection to the function along with the function releasing it, and whether a new connection
+// had to be dialed
+func (i *grpcInvoker) connect(function *common.Function) (*grpc.ClientConn, func(), bool, error) {
+	var authority string
+	if strings.Contains(strings.ToLower(i.cfg.Platform), "dirigent") {
+		authority = function.Name // Dirigent specific
 	}
+
+	if i.pool == nil {
+		conn, err := i.dial(function.Endpoint, authority)
+		if err != nil {
+			return nil, nil, true, err
+		}
+
+		return conn, func() { gRPCConnectionClose(conn) }, true, nil
+	}
+
+	connection, dialed, err := i.pool.acquire(function.Endpoint, authority)
+	if err != nil {
+		return nil, nil, dialed, err
+	}
+
+	return connection.conn, func() { i.pool.release(connection) }, dialed, nil
 }
 
-func (i *grpcInvoker) Invoke(function *common.Function, runtimeSpec *common.RuntimeSpecification) (bool, *mc.ExecutionRecord) {
+func (i *grpcInvoker) Invoke(ctx context.Context, function *common.Function, runtimeSpec *common.RuntimeSpecification) (bool, *mc.ExecutionRecord) {
 	logrus.Tracef("(Invoke)\t %s: %d[ms], %d[MiB]", function.Name, runtimeSpec.Runtime, runtimeSpec.Memory)
 
 	record := &mc.ExecutionRecord{
@@ -135,18 +221,9 @@ func (i *grpcInvoker) Invoke(function *common.Function, runtimeSpec *common.Runt
 	start := time.Now()
 	record.StartTime = start.UnixMicro()
 
-	var dialOptions []grpc.DialOption
-	dialOptions = append(dialOptions, grpc.WithTransportCredentials(insecure.NewCredentials()))
-	if strings.Contains(strings.ToLower(i.cfg.Platform), "dirigent") {
-		dialOptions = append(dialOptions, grpc.WithAuthority(function.Name)) // Dirigent specific
-	}
-	if i.cfg.EnableZipkinTracing {
-		dialOptions = append(dialOptions, grpc.WithStatsHandler(otelgrpc.NewClientHandler()))
-	}
-
 	grpcStart := time.Now()
 
-	conn, err := grpc.NewClient(function.Endpoint, dialOptions...)
+	conn, release, dialed, err := i.connect(function)
 	if err != nil {
 		logrus.Debugf("Failed to establish a gRPC connection - %v\n", err)
 
@@ -155,10 +232,20 @@ func (i *grpcInvoker) Invoke(function *common.Function, runtimeSpec *common.Runt
 
 		return false, record
 	}
-	defer gRPCConnectionClose(conn)
+	defer release()
+
+	// reused connections do not add to the connection establishment time
+	if dialed {
+		record.GRPCConnectionEstablishTime = time.Since(grpcStart).Microseconds()
+	}
 
-	record.GRPCConnectionEstablishTime = time.Since(grpcStart).Microseconds()
-	executionCxt, cancelExecution := context.WithTimeout(context.Background(), time.Duration(i.cfg.GRPCFunctionTimeoutSeconds)*time.Second)
+	for key, value := range requestHeaders(ctx) {
+		ctx = metadata.AppendToOutgoingContext(ctx, key, value)
+	}
+	for key, value := range i.credentials.headers(function.Name) {
+		ctx = metadata.AppendToOutgoingContext(ctx, key, value)
+	}
+	executionCxt, cancelExecution := context.WithTimeout(ctx, time.Duration(i.cfg.GRPCFunctionTimeoutSeconds)*time.Second)
 	defer cancelExecution()
 	success := i.invoker.Invoke(function, runtimeSpec, conn, record, executionCxt)
 	record.ResponseTime = time.Since(start).Microseconds()
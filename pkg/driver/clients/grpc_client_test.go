@@ -99,7 +99,7 @@ func TestGRPCClientWithServerUnreachable(t *testing.T) {
 	cfg.EnableZipkinTracing = true
 
 	invoker := CreateInvoker(cfg, nil, nil)
-	success, record := invoker.Invoke(&testFunction, &testRuntimeSpecs)
+	success, record := invoker.Invoke(context.Background(), &testFunction, &testRuntimeSpecs)
 
 	if record.Instance != "" ||
 		record.RequestedDuration != uint32(testRuntimeSpecs.Runtime*1000) ||
@@ -116,7 +116,7 @@ func TestVSwarmClientUnreachable(t *testing.T) {
 	cfgSwarm := createFakeVSwarmLoaderConfiguration()
 
 	vSwarmInvoker := CreateInvoker(cfgSwarm, nil, nil)
-	success, record := vSwarmInvoker.Invoke(&testFunction, &testRuntimeSpecs)
+	success, record := vSwarmInvoker.Invoke(context.Background(), &testFunction, &testRuntimeSpecs)
 
 	if record.Instance != "" ||
 		record.RequestedDuration != uint32(testRuntimeSpecs.Runtime*1000) ||
@@ -142,7 +142,7 @@ func TestGRPCClientWithServerReachable(t *testing.T) {
 	invoker := CreateInvoker(cfg, nil, nil)
 
 	start := time.Now()
-	success, record := invoker.Invoke(&testFunction, &testRuntimeSpecs)
+	success, record := invoker.Invoke(context.Background(), &testFunction, &testRuntimeSpecs)
 	logrus.Info("Elapsed: ", time.Since(start).Milliseconds(), " ms")
 
 	if !success ||
@@ -168,7 +168,7 @@ func TestVSwarmClientWithServerReachable(t *testing.T) {
 	vSwarmInvoker := CreateInvoker(cfgSwarm, nil, nil)
 
 	start := time.Now()
-	success, record := vSwarmInvoker.Invoke(&testFunction, &testRuntimeSpecs)
+	success, record := vSwarmInvoker.Invoke(context.Background(), &testFunction, &testRuntimeSpecs)
 	logrus.Info("Elapsed: ", time.Since(start).Milliseconds(), " ms")
 	if !success ||
 		record.MemoryAllocationTimeout != false ||
@@ -200,7 +200,7 @@ func TestGRPCClientWithServerBatchWorkload(t *testing.T) {
 	invoker := CreateInvoker(cfg, nil, nil)
 
 	for i := 0; i < 50; i++ {
-		success, record := invoker.Invoke(&testFunction, &testRuntimeSpecs)
+		success, record := invoker.Invoke(context.Background(), &testFunction, &testRuntimeSpecs)
 
 		if !success ||
 			record.MemoryAllocationTimeout != false ||
@@ -214,3 +214,30 @@ func TestGRPCClientWithServerBatchWorkload(t *testing.T) {
 		}
 	}
 }
+
+func TestGRPCClientWithPayload(t *testing.T) {
+	address, port := "localhost", 18084
+	testFunction.Endpoint = fmt.Sprintf("%s:%d", address, port)
+
+	go standard.StartGRPCServer(address, port, standard.TraceFunction, "")
+
+	// make sure that the gRPC server is running
+	time.Sleep(2 * time.Second)
+
+	invoker := CreateInvoker(createFakeLoaderConfiguration(), nil, nil)
+
+	runtimeSpec := testRuntimeSpecs
+	runtimeSpec.RequestSize, runtimeSpec.ResponseSize = 64*1024, 256*1024
+
+	success, record := invoker.Invoke(context.Background(), &testFunction, &runtimeSpec)
+	if !success {
+		t.Fatal("Failed gRPC invocation with payload.")
+	}
+
+	if record.BytesSent < int64(runtimeSpec.RequestSize) || record.BytesSent > int64(runtimeSpec.RequestSize)+64 {
+		t.Errorf("Unexpected number of bytes sent - got %d, expected about %d.", record.BytesSent, runtimeSpec.RequestSize)
+	}
+	if record.BytesReceived < int64(runtimeSpec.ResponseSize) || record.BytesReceived > int64(runtimeSpec.ResponseSize)+256 {
+		t.Errorf("Unexpected number of bytes received - got %d, expected about %d.", record.BytesReceived, runtimeSpec.ResponseSize)
+	}
+}
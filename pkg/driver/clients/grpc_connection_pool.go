@@ -0,0 +1,186 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2023 EASL and the vHive community
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package clients
+
+import (
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+)
+
+type connectionKey struct {
+	endpoint  string
+	authority string
+}
+
+type pooledConnection struct {
+	conn     *grpc.ClientConn
+	inUse    int
+	lastUsed time.Time
+}
+
+// connectionPool keeps up to size connections per endpoint and authority, which are handed out in a round-robin
+// fashion, so that invocations do not pay for the connection setup. Connections that have not been used for
+// idleTimeout are closed, unless idleTimeout is zero.
+type connectionPool struct {
+	size        int
+	idleTimeout time.Duration
+	dial        func(endpoint string, authority string) (*grpc.ClientConn, error)
+
+	connections map[connectionKey][]*pooledConnection
+	next        map[connectionKey]int
+	mutex       sync.Mutex
+
+	// done Stops the eviction of idle connections once the pool is closed
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func newConnectionPool(size int, idleTimeout time.Duration, dial func(string, string) (*grpc.ClientConn, error)) *connectionPool {
+	if size <= 0 {
+		size = 1
+	}
+
+	pool := &connectionPool{
+		size:        size,
+		idleTimeout: idleTimeout,
+		dial:        dial,
+		connections: make(map[connectionKey][]*pooledConnection),
+		next:        make(map[connectionKey]int),
+		done:        make(chan struct{}),
+	}
+
+	if idleTimeout > 0 {
+		go pool.evictIdleConnections()
+	}
+
+	return pool
+}
+
+// acquire returns a connection to the endpoint, which has to be released once the invocation has returned, and
+// whether a new connection had to be dialed
+func (p *connectionPool) acquire(endpoint string, authority string) (*pooledConnection, bool, error) {
+	key := connectionKey{endpoint: endpoint, authority: authority}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	var connection *pooledConnection
+	dialed := false
+
+	if connections := p.connections[key]; len(connections) < p.size {
+		conn, err := p.dial(endpoint, authority)
+		if err != nil {
+			return nil, true, err
+		}
+
+		connection = &pooledConnection{conn: conn}
+		p.connections[key] = append(connections, connection)
+		dialed = true
+	} else {
+		index := p.next[key] % len(connections)
+		p.next[key] = index + 1
+
+		connection = connections[index]
+	}
+
+	connection.inUse++
+	connection.lastUsed = time.Now()
+
+	return connection, dialed, nil
+}
+
+func (p *connectionPool) release(connection *pooledConnection) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	connection.inUse--
+	connection.lastUsed = time.Now()
+}
+
+func (p *connectionPool) evictIdleConnections() {
+	ticker := time.NewTicker(p.idleTimeout / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			p.evict(time.Now().Add(-p.idleTimeout))
+		case <-p.done:
+			return
+		}
+	}
+}
+
+// Close stops the eviction of idle connections and closes all the connections of the pool
+func (p *connectionPool) Close() {
+	p.closeOnce.Do(func() {
+		close(p.done)
+	})
+
+	p.mutex.Lock()
+	connections := p.connections
+	p.connections = make(map[connectionKey][]*pooledConnection)
+	p.next = make(map[connectionKey]int)
+	p.mutex.Unlock()
+
+	for _, pooled := range connections {
+		for _, connection := range pooled {
+			gRPCConnectionClose(connection.conn)
+		}
+	}
+}
+
+// evict closes the connections that are not in use and have last been used before the given time
+func (p *connectionPool) evict(usedBefore time.Time) {
+	var idle []*pooledConnection
+
+	p.mutex.Lock()
+	for key, connections := range p.connections {
+		kept := connections[:0]
+		for _, connection := range connections {
+			if connection.inUse == 0 && connection.lastUsed.Before(usedBefore) {
+				idle = append(idle, connection)
+			} else {
+				kept = append(kept, connection)
+			}
+		}
+
+		if len(kept) == 0 {
+			delete(p.connections, key)
+			delete(p.next, key)
+		} else {
+			p.connections[key] = kept
+		}
+	}
+	p.mutex.Unlock()
+
+	for _, connection := range idle {
+		logrus.Debugf("Closing idle gRPC connection to %s", connection.conn.Target())
+		gRPCConnectionClose(connection.conn)
+	}
+}
@@ -0,0 +1,140 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2023 EASL and the vHive community
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package clients
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/vhive-serverless/loader/pkg/common"
+	"github.com/vhive-serverless/loader/pkg/workload/standard"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+func TestConnectionPool(t *testing.T) {
+	dials := 0
+	pool := newConnectionPool(2, 0, func(endpoint string, authority string) (*grpc.ClientConn, error) {
+		dials++
+		return grpc.NewClient(endpoint, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	})
+
+	var acquired []*pooledConnection
+	for i := 0; i < 4; i++ {
+		connection, dialed, err := pool.acquire("localhost:18090", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if dialed != (i < 2) {
+			t.Errorf("Unexpected dial for connection %d.", i)
+		}
+
+		acquired = append(acquired, connection)
+	}
+
+	if dials != 2 || acquired[0] != acquired[2] || acquired[1] != acquired[3] || acquired[0] == acquired[1] {
+		t.Errorf("Connections have not been reused in a round-robin fashion - %d dials.", dials)
+	}
+
+	// connections to another authority are not shared
+	if _, dialed, _ := pool.acquire("localhost:18090", "other-function"); !dialed {
+		t.Error("Connection to another authority should have been dialed.")
+	}
+
+	for _, connection := range acquired[1:] {
+		pool.release(connection)
+	}
+	pool.evict(time.Now().Add(time.Hour))
+
+	if len(pool.connections[connectionKey{endpoint: "localhost:18090"}]) != 1 {
+		t.Error("Only the connection still in use should have been kept.")
+	}
+
+	pool.Close()
+	if len(pool.connections) != 0 {
+		t.Error("Connections have not been closed along with the pool.")
+	}
+}
+
+func TestConnectionPoolClose(t *testing.T) {
+	pool := newConnectionPool(1, time.Hour, func(endpoint string, authority string) (*grpc.ClientConn, error) {
+		return grpc.NewClient(endpoint, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	})
+
+	connection, _, err := pool.acquire("localhost:18090", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pool.Close()
+	pool.Close()
+
+	select {
+	case <-pool.done:
+	default:
+		t.Error("Eviction of idle connections has not been stopped.")
+	}
+	if len(pool.connections) != 0 || connection.conn.GetState() != connectivity.Shutdown {
+		t.Error("Connections have not been closed along with the pool.")
+	}
+}
+
+func TestGRPCClientWithConnectionPool(t *testing.T) {
+	address, port := "localhost", 18083
+	function := common.Function{
+		Name:     "test-function",
+		Endpoint: fmt.Sprintf("%s:%d", address, port),
+	}
+
+	go standard.StartGRPCServer(address, port, standard.TraceFunction, "")
+
+	// make sure that the gRPC server is running
+	time.Sleep(2 * time.Second)
+
+	cfg := createFakeLoaderConfiguration()
+	cfg.GRPCConnectionMode = common.GRPCConnectionPooled
+	cfg.GRPCKeepaliveSeconds = 10
+
+	invoker := CreateInvoker(cfg, nil, nil)
+
+	for i := 0; i < 3; i++ {
+		success, record := invoker.Invoke(context.Background(), &function, &testRuntimeSpecs)
+		if !success {
+			t.Fatalf("Invocation %d over a pooled connection has failed.", i)
+		}
+
+		if i > 0 && record.GRPCConnectionEstablishTime != 0 {
+			t.Errorf("Invocation %d has reused a connection, but reports a connection establishment time.", i)
+		}
+	}
+
+	CloseInvoker(invoker)
+	if pool := invoker.(*chainedInvoker).platform.(*grpcInvoker).pool; len(pool.connections) != 0 {
+		t.Error("Pooled connections have not been closed along with the invoker.")
+	}
+}
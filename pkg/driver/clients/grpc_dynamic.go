@@ -0,0 +1,397 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2023 EASL and the vHive community
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package clients
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/vhive-serverless/loader/pkg/common"
+	"github.com/vhive-serverless/loader/pkg/config"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/protobuf/encoding/protojson"
+	protov2 "google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	mc "github.com/vhive-serverless/loader/pkg/metric"
+)
+
+// dynamicMethod is a unary gRPC method along with the template of the requests it is invoked with
+type dynamicMethod struct {
+	// path Path of the method on the wire, i.e., /<package>.<service>/<method>
+	path    string
+	input   protoreflect.MessageDescriptor
+	output  protoreflect.MessageDescriptor
+	request *template.Template
+}
+
+// DynamicRPC invokes methods unknown to the loader at compile time. The methods are resolved from a descriptor set
+// or through server reflection and their requests are built from JSON templates, so that any gRPC function can be
+// invoked without generating code for it.
+type DynamicRPC struct {
+	defaultMethod *dynamicMethod
+	// functionMethods Methods of the functions configured individually, by function name
+	functionMethods map[string]*dynamicMethod
+
+	instanceField string
+	durationField string
+}
+
+func newDynamicRPC(cfg *config.LoaderConfiguration) *DynamicRPC {
+	methods := map[string]config.GRPCMethodConfiguration{}
+	if cfg.GRPCDynamicMethod.Method != "" {
+		methods[""] = cfg.GRPCDynamicMethod
+	}
+	for function, method := range cfg.GRPCFunctionMethods {
+		if method.Method == "" {
+			method.Method = cfg.GRPCDynamicMethod.Method
+		}
+		if method.RequestTemplate == "" {
+			method.RequestTemplate = cfg.GRPCDynamicMethod.RequestTemplate
+		}
+		if method.Method == "" {
+			logrus.Fatalf("No gRPC method configured for function %s.", function)
+		}
+
+		methods[function] = method
+	}
+
+	var services []string
+	for _, method := range methods {
+		service, _, err := splitMethodName(method.Method)
+		if err != nil {
+			logrus.Fatalf("Invalid gRPC method %s - %v", method.Method, err)
+		}
+		services = append(services, service)
+	}
+
+	var files *protoregistry.Files
+	var err error
+	switch {
+	case cfg.GRPCDescriptorSetPath != "":
+		files, err = readDescriptorSet(cfg.GRPCDescriptorSetPath)
+	case cfg.GRPCReflectionEndpoint != "":
+		files, err = fetchReflectionDescriptors(cfg, services)
+	default:
+		logrus.Fatal("Either GRPCDescriptorSetPath or GRPCReflectionEndpoint has to be set to invoke gRPC methods dynamically.")
+	}
+	if err != nil {
+		logrus.Fatalf("Failed to load the gRPC service descriptors - %v", err)
+	}
+
+	rpc := &DynamicRPC{
+		functionMethods: make(map[string]*dynamicMethod),
+		instanceField:   cfg.GRPCInstanceField,
+		durationField:   cfg.GRPCDurationField,
+	}
+
+	for function, method := range methods {
+		resolved, err := resolveMethod(files, method)
+		if err != nil {
+			logrus.Fatalf("Failed to resolve gRPC method %s - %v", method.Method, err)
+		}
+
+		if function == "" {
+			rpc.defaultMethod = resolved
+		} else {
+			rpc.functionMethods[function] = resolved
+		}
+	}
+
+	for _, path := range []string{rpc.instanceField, rpc.durationField} {
+		if _, err := parseJSONPath(path); err != nil {
+			logrus.Fatalf("Invalid JSONPath %s - %v", path, err)
+		}
+	}
+
+	return rpc
+}
+
+// splitMethodName splits a fully qualified method name, e.g., helloworld.Greeter/SayHello or
+// helloworld.Greeter.SayHello, into the names of the service and of the method
+func splitMethodName(name string) (string, string, error) {
+	name = strings.TrimPrefix(name, "/")
+
+	separator := strings.LastIndex(name, "/")
+	if separator == -1 {
+		separator = strings.LastIndex(name, ".")
+	}
+	if separator <= 0 || separator == len(name)-1 {
+		return "", "", fmt.Errorf("expected <package>.<service>/<method>")
+	}
+
+	return name[:separator], name[separator+1:], nil
+}
+
+func resolveMethod(files *protoregistry.Files, method config.GRPCMethodConfiguration) (*dynamicMethod, error) {
+	serviceName, methodName, err := splitMethodName(method.Method)
+	if err != nil {
+		return nil, err
+	}
+
+	descriptor, err := files.FindDescriptorByName(protoreflect.FullName(serviceName))
+	if err != nil {
+		return nil, err
+	}
+	service, ok := descriptor.(protoreflect.ServiceDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a service", serviceName)
+	}
+
+	methodDescriptor := service.Methods().ByName(protoreflect.Name(methodName))
+	if methodDescriptor == nil {
+		return nil, fmt.Errorf("service %s has no method %s", serviceName, methodName)
+	}
+	if methodDescriptor.IsStreamingClient() || methodDescriptor.IsStreamingServer() {
+		return nil, fmt.Errorf("streaming methods are not supported")
+	}
+
+	requestTemplate := method.RequestTemplate
+	if requestTemplate == "" {
+		requestTemplate = "{}"
+	}
+
+	return &dynamicMethod{
+		path:    fmt.Sprintf("/%s/%s", serviceName, methodName),
+		input:   methodDescriptor.Input(),
+		output:  methodDescriptor.Output(),
+		request: mustParseTemplate(method.Method, requestTemplate),
+	}, nil
+}
+
+// readDescriptorSet reads a FileDescriptorSet such as the one written by protoc --descriptor_set_out, which has to
+// contain the imported files as well, i.e., be written with --include_imports
+func readDescriptorSet(path string) (*protoregistry.Files, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	set := &descriptorpb.FileDescriptorSet{}
+	if err = protov2.Unmarshal(data, set); err != nil {
+		return nil, err
+	}
+
+	return protodesc.NewFiles(set)
+}
+
+// fetchReflectionDescriptors retrieves the files defining the given services, along with all the files they import,
+// from the server reflection service at the given endpoint. Imported files the server does not provide, such as the
+// well-known types, are taken from the files linked into the loader.
+func fetchReflectionDescriptors(cfg *config.LoaderConfiguration, services []string) (*protoregistry.Files, error) {
+	transportCredentials := insecure.NewCredentials()
+	if tlsConfig := createTLSConfig(cfg); tlsConfig != nil {
+		transportCredentials = credentials.NewTLS(tlsConfig)
+	}
+
+	conn, err := grpc.NewClient(cfg.GRPCReflectionEndpoint, grpc.WithTransportCredentials(transportCredentials))
+	if err != nil {
+		return nil, err
+	}
+	defer gRPCConnectionClose(conn)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(cfg.GRPCConnectionTimeoutSeconds)*time.Second)
+	defer cancel()
+
+	stream, err := reflectionpb.NewServerReflectionClient(conn).ServerReflectionInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = stream.CloseSend() }()
+
+	files := make(map[string]*descriptorpb.FileDescriptorProto)
+	var order []string
+
+	request := func(req *reflectionpb.ServerReflectionRequest) error {
+		if err := stream.Send(req); err != nil {
+			return err
+		}
+		resp, err := stream.Recv()
+		if err != nil {
+			return err
+		}
+		if errorResponse := resp.GetErrorResponse(); errorResponse != nil {
+			return fmt.Errorf("%s", errorResponse.GetErrorMessage())
+		}
+
+		for _, data := range resp.GetFileDescriptorResponse().GetFileDescriptorProto() {
+			file := &descriptorpb.FileDescriptorProto{}
+			if err := protov2.Unmarshal(data, file); err != nil {
+				return err
+			}
+			if _, ok := files[file.GetName()]; !ok {
+				files[file.GetName()] = file
+				order = append(order, file.GetName())
+			}
+		}
+
+		return nil
+	}
+
+	for _, service := range services {
+		err = request(&reflectionpb.ServerReflectionRequest{
+			MessageRequest: &reflectionpb.ServerReflectionRequest_FileContainingSymbol{FileContainingSymbol: service},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch the descriptor of %s - %v", service, err)
+		}
+	}
+
+	// servers usually send the imported files along with the requested one, but are not obliged to
+	for i := 0; i < len(order); i++ {
+		for _, dependency := range files[order[i]].GetDependency() {
+			if _, ok := files[dependency]; ok {
+				continue
+			}
+
+			if linked, err := protoregistry.GlobalFiles.FindFileByPath(dependency); err == nil {
+				files[dependency] = protodesc.ToFileDescriptorProto(linked)
+				order = append(order, dependency)
+				continue
+			}
+
+			err = request(&reflectionpb.ServerReflectionRequest{
+				MessageRequest: &reflectionpb.ServerReflectionRequest_FileByFilename{FileByFilename: dependency},
+			})
+			if err != nil {
+				return nil, fmt.Errorf("failed to fetch the descriptor of %s - %v", dependency, err)
+			}
+			if _, ok := files[dependency]; !ok {
+				return nil, fmt.Errorf("server did not provide the descriptor of %s", dependency)
+			}
+		}
+	}
+
+	set := &descriptorpb.FileDescriptorSet{}
+	for _, name := range order {
+		set.File = append(set.File, files[name])
+	}
+
+	return protodesc.NewFiles(set)
+}
+
+func (i *DynamicRPC) Invoke(function *common.Function, runtimeSpec *common.RuntimeSpecification, conn *grpc.ClientConn, record *mc.ExecutionRecord, executionCxt context.Context) bool {
+	method, ok := i.functionMethods[function.Name]
+	if !ok {
+		method = i.defaultMethod
+	}
+	if method == nil {
+		logrus.Errorf("No gRPC method configured for function %s", function.Name)
+		record.ConnectionTimeout = true
+
+		return false
+	}
+
+	request, err := method.createRequest(executionCxt, function, runtimeSpec)
+	if err != nil {
+		logrus.Errorf("%s - Failed to create a request for %s - %v", function.Name, method.path, err)
+		record.ConnectionTimeout = true
+
+		return false
+	}
+	record.BytesSent = int64(protov2.Size(request))
+
+	response := dynamicpb.NewMessage(method.output)
+	if err = conn.Invoke(executionCxt, method.path, request, response); err != nil {
+		logrus.Debugf("gRPC timeout exceeded for function %s - %s", function.Name, err)
+
+		record.ConnectionTimeout = true // WithBlock deprecated in new gRPC interface
+		record.FunctionTimeout = true
+
+		return false
+	}
+	record.BytesReceived = int64(protov2.Size(response))
+
+	if err = i.parseResponse(response, record); err != nil {
+		logrus.Warnf("Failed to parse the response of %s - %v", function.Name, err)
+	}
+
+	logrus.Tracef("(Replied)\t %s: %s, %.2f[ms]", function.Name, record.Instance, float64(record.ActualDuration)/1e3)
+
+	return true
+}
+
+func (m *dynamicMethod) createRequest(ctx context.Context, function *common.Function, runtimeSpec *common.RuntimeSpecification) (*dynamicpb.Message, error) {
+	rendered, err := executeTemplate(m.request, newRequestTemplateData(ctx, function, runtimeSpec, payload(runtimeSpec.RequestSize)))
+	if err != nil {
+		return nil, err
+	}
+
+	request := dynamicpb.NewMessage(m.input)
+	if err = protojson.Unmarshal([]byte(rendered), request); err != nil {
+		return nil, err
+	}
+
+	return request, nil
+}
+
+// parseResponse extracts the instance and the execution time in milliseconds, as for the GenericHTTP platform, from the
+// JSON mapping of the response, as far as the respective fields are configured
+func (i *DynamicRPC) parseResponse(response *dynamicpb.Message, record *mc.ExecutionRecord) error {
+	if i.instanceField == "" && i.durationField == "" {
+		return nil
+	}
+
+	// fields holding default values are emitted too, so that a zero duration is not mistaken for a missing field
+	data, err := protojson.MarshalOptions{EmitUnpopulated: true}.Marshal(response)
+	if err != nil {
+		return err
+	}
+
+	var document any
+	if err = json.Unmarshal(data, &document); err != nil {
+		return err
+	}
+
+	if i.instanceField != "" {
+		instance, err := lookupJSONPath(document, i.instanceField)
+		if err != nil {
+			return err
+		}
+		record.Instance = fmt.Sprint(instance)
+	}
+	if i.durationField != "" {
+		duration, err := lookupJSONNumber(document, i.durationField)
+		if err != nil {
+			return err
+		}
+		record.ActualDuration = uint32(duration * 1e3) // ms to µs
+	}
+
+	return nil
+}
@@ -0,0 +1,179 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2023 EASL and the vHive community
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package clients
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/vhive-serverless/loader/pkg/config"
+	mc "github.com/vhive-serverless/loader/pkg/metric"
+	"github.com/vhive-serverless/loader/pkg/workload/standard"
+	"google.golang.org/protobuf/encoding/protojson"
+	protov2 "google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+func writeExecutorDescriptorSet(t *testing.T) string {
+	file, err := protoregistry.GlobalFiles.FindFileByPath("server/faas.proto")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := protov2.Marshal(&descriptorpb.FileDescriptorSet{
+		File: []*descriptorpb.FileDescriptorProto{protodesc.ToFileDescriptorProto(file)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "faas.pb")
+	if err = os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func TestSplitMethodName(t *testing.T) {
+	tests := []struct {
+		name    string
+		service string
+		method  string
+		invalid bool
+	}{
+		{name: "helloworld.Greeter/SayHello", service: "helloworld.Greeter", method: "SayHello"},
+		{name: "/helloworld.Greeter/SayHello", service: "helloworld.Greeter", method: "SayHello"},
+		{name: "helloworld.Greeter.SayHello", service: "helloworld.Greeter", method: "SayHello"},
+		{name: "SayHello", invalid: true},
+		{name: "helloworld.Greeter/", invalid: true},
+	}
+
+	for _, test := range tests {
+		service, method, err := splitMethodName(test.name)
+		if test.invalid != (err != nil) || service != test.service || method != test.method {
+			t.Errorf("%s - got %s, %s, %v", test.name, service, method, err)
+		}
+	}
+}
+
+func TestDynamicRPCParseResponse(t *testing.T) {
+	descriptor, err := protoregistry.GlobalFiles.FindDescriptorByName("faas.FaasReply")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	response := dynamicpb.NewMessage(descriptor.(protoreflect.MessageDescriptor))
+	if err = protojson.Unmarshal([]byte(`{"message": "test-instance", "durationInMicroSec": 12}`), response); err != nil {
+		t.Fatal(err)
+	}
+
+	record := &mc.ExecutionRecord{}
+	rpc := &DynamicRPC{instanceField: "$.message", durationField: "$.durationInMicroSec"}
+	if err = rpc.parseResponse(response, record); err != nil {
+		t.Fatal(err)
+	}
+
+	// the execution time is read in milliseconds, as for the GenericHTTP platform
+	if record.Instance != "test-instance" || record.ActualDuration != 12_000 {
+		t.Errorf("Unexpected record %+v", record)
+	}
+}
+
+func TestDynamicRPCWithDescriptorSet(t *testing.T) {
+	address, port := "localhost", 18085
+	function := testFunction
+	function.Endpoint = fmt.Sprintf("%s:%d", address, port)
+
+	go standard.StartGRPCServer(address, port, standard.TraceFunction, "")
+	time.Sleep(2 * time.Second)
+
+	cfg := createFakeLoaderConfiguration()
+	cfg.GRPCDescriptorSetPath = writeExecutorDescriptorSet(t)
+	cfg.GRPCDynamicMethod = config.GRPCMethodConfiguration{
+		Method:          "faas.Executor/Execute",
+		RequestTemplate: `{"runtimeInMilliSec": {{.Runtime}}, "memoryInMebiBytes": {{.Memory}}, "payload": "{{.Payload}}"}`,
+	}
+	// the duration is read in milliseconds, which suffices to check that the field is found
+	cfg.GRPCDurationField = "$.durationInMicroSec"
+
+	runtimeSpec := testRuntimeSpecs
+	runtimeSpec.RequestSize = 1024
+
+	success, record := CreateInvoker(cfg, nil, nil).Invoke(context.Background(), &function, &runtimeSpec)
+	if !success || record.ConnectionTimeout || record.FunctionTimeout {
+		t.Fatal("Failed dynamic gRPC invocation of the trace function.")
+	}
+
+	if record.ActualDuration == 0 {
+		t.Error("Duration has not been read from the response.")
+	}
+	if record.BytesSent < int64(runtimeSpec.RequestSize) {
+		t.Errorf("Unexpected number of bytes sent - got %d, expected at least %d.", record.BytesSent, runtimeSpec.RequestSize)
+	}
+}
+
+func TestDynamicRPCWithReflection(t *testing.T) {
+	address, port := "localhost", 18086
+	function := testFunction
+	function.Endpoint = fmt.Sprintf("%s:%d", address, port)
+
+	go startVSwarmGRPCServer(address, port)
+	time.Sleep(2 * time.Second)
+
+	cfg := createFakeLoaderConfiguration()
+	cfg.GRPCReflectionEndpoint = function.Endpoint
+	cfg.GRPCFunctionMethods = map[string]config.GRPCMethodConfiguration{
+		function.Name: {
+			Method:          "helloworld.Greeter/SayHello",
+			RequestTemplate: `{"name": "{{.Function}}"}`,
+		},
+	}
+	cfg.GRPCInstanceField = "$.message"
+
+	success, record := CreateInvoker(cfg, nil, nil).Invoke(context.Background(), &function, &testRuntimeSpecs)
+	if !success || record.ConnectionTimeout || record.FunctionTimeout {
+		t.Fatal("Failed dynamic gRPC invocation of the vSwarm function.")
+	}
+
+	if record.Instance != "Reply message" {
+		t.Errorf("Unexpected instance - got %s, expected Reply message.", record.Instance)
+	}
+
+	// functions without a method of their own fall back to GRPCDynamicMethod, which is not configured
+	other := function
+	other.Name = "other-function"
+	if success, _ = CreateInvoker(cfg, nil, nil).Invoke(context.Background(), &other, &testRuntimeSpecs); success {
+		t.Error("Invocation of a function without a gRPC method should fail.")
+	}
+}
@@ -2,6 +2,7 @@ package clients
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	log "github.com/sirupsen/logrus"
 	"github.com/vhive-serverless/loader/pkg/common"
@@ -19,21 +20,35 @@ type FunctionResponse struct {
 	Function      string `json:"Function"`
 	MachineName   string `json:"MachineName"`
 	ExecutionTime int64  `json:"ExecutionTime"`
+	// FirstRequest and Uptime (in microseconds) describe the instance, if the function reports them
+	FirstRequest bool  `json:"FirstRequest"`
+	Uptime       int64 `json:"Uptime"`
 }
 
 type httpInvoker struct {
-	client *http.Client
-	cfg    *config.LoaderConfiguration
+	client      *http.Client
+	cfg         *config.LoaderConfiguration
+	scheme      string
+	credentials *credentialsProvider
 }
 
 func newHTTPInvoker(cfg *config.LoaderConfiguration) *httpInvoker {
+	tlsConfig := createTLSConfig(cfg)
+
+	scheme := "http://"
+	if tlsConfig != nil {
+		scheme = "https://"
+	}
+
 	return &httpInvoker{
-		client: CreateHTTPClient(cfg.GRPCFunctionTimeoutSeconds, cfg.InvokeProtocol),
-		cfg:    cfg,
+		client:      CreateHTTPClient(cfg.GRPCFunctionTimeoutSeconds, cfg.InvokeProtocol, tlsConfig),
+		cfg:         cfg,
+		scheme:      scheme,
+		credentials: newCredentialsProvider(cfg),
 	}
 }
 
-func (i *httpInvoker) Invoke(function *common.Function, runtimeSpec *common.RuntimeSpecification) (bool, *mc.ExecutionRecord) {
+func (i *httpInvoker) Invoke(ctx context.Context, function *common.Function, runtimeSpec *common.RuntimeSpecification) (bool, *mc.ExecutionRecord) {
 	isDandelion := strings.Contains(strings.ToLower(i.cfg.Platform), "dandelion")
 	isKnative := strings.Contains(strings.ToLower(i.cfg.Platform), "knative")
 
@@ -51,15 +66,27 @@ func (i *httpInvoker) Invoke(function *common.Function, runtimeSpec *common.Runt
 	start := time.Now()
 	record.StartTime = start.UnixMicro()
 
-	requestBody := &bytes.Buffer{}
-	/*if body := composeDandelionMatMulBody(function.Name); isDandelion && body != nil {
-		requestBody = body
-	}*/
-	if body := composeBusyLoopBody(function.Name, function.DirigentMetadata.Image, runtimeSpec.Runtime, function.DirigentMetadata.IterationMultiplier); isDandelion && body != nil {
-		requestBody = body
+	requestBody := bytes.NewBuffer(payload(runtimeSpec.RequestSize))
+
+	var dandelion *dandelionInvocation
+	if isDandelion {
+		var err error
+		if dandelion, err = composeDandelionInvocation(i.cfg, function, runtimeSpec); err == nil {
+			requestBody, err = dandelion.body()
+		}
+		if err != nil {
+			log.Errorf("Failed to compose a Dandelion request - %v", err)
+
+			record.ResponseTime = time.Since(start).Microseconds()
+			record.ConnectionTimeout = true
+
+			return false, record
+		}
 	}
 
-	req, err := http.NewRequest("POST", "http://"+function.Endpoint, requestBody)
+	record.BytesSent = int64(requestBody.Len())
+
+	req, err := http.NewRequestWithContext(ctx, "POST", i.scheme+function.Endpoint, requestBody)
 	if err != nil {
 		log.Errorf("Failed to create a HTTP request - %v\n", err)
 
@@ -74,15 +101,27 @@ func (i *httpInvoker) Invoke(function *common.Function, runtimeSpec *common.Runt
 		req.Host = function.Name
 	}
 
-	req.Header.Set("workload", function.DirigentMetadata.Image)
 	req.Header.Set("function", function.Name)
 	req.Header.Set("requested_cpu", strconv.Itoa(runtimeSpec.Runtime))
 	req.Header.Set("requested_memory", strconv.Itoa(runtimeSpec.Memory))
-	req.Header.Set("multiplier", strconv.Itoa(function.DirigentMetadata.IterationMultiplier))
-	req.Header.Set("io_percentage", strconv.Itoa(function.DirigentMetadata.IOPercentage))
+	if runtimeSpec.ResponseSize > 0 {
+		req.Header.Set("response_size", strconv.Itoa(runtimeSpec.ResponseSize))
+	}
+	if metadata := function.DirigentMetadata; metadata != nil {
+		req.Header.Set("workload", metadata.Image)
+		req.Header.Set("multiplier", strconv.Itoa(metadata.IterationMultiplier))
+		req.Header.Set("io_percentage", strconv.Itoa(metadata.IOPercentage))
+	}
+	setRequestHeaders(ctx, req.Header)
+	for key, value := range i.credentials.headers(function.Name) {
+		req.Header.Set(key, value)
+	}
 
 	if isDandelion {
-		req.URL.Path = "/hot/matmul"
+		req.URL.Path = i.cfg.DandelionInvocationPath
+		if req.URL.Path == "" {
+			req.URL.Path = common.DefaultDandelionInvocationPath
+		}
 	}
 
 	resp, err := i.client.Do(req)
@@ -96,9 +135,11 @@ func (i *httpInvoker) Invoke(function *common.Function, runtimeSpec *common.Runt
 	}
 
 	record.GRPCConnectionEstablishTime = time.Since(start).Microseconds()
+	record.HttpStatusCode = resp.StatusCode
 
 	defer HandleBodyClosing(resp)
 	body, err := io.ReadAll(resp.Body)
+	record.BytesReceived = int64(len(body))
 
 	if err != nil || resp.StatusCode != http.StatusOK || len(body) == 0 {
 		if err != nil {
@@ -116,9 +157,13 @@ func (i *httpInvoker) Invoke(function *common.Function, runtimeSpec *common.Runt
 	}
 
 	if isDandelion {
-		err = DeserializeDandelionResponse(function, body, record)
+		err = dandelion.deserializeResponse(function, body, record)
 		if err != nil {
-			log.Warnf("Failed to deserialize Dandelion response - %v - %v", string(body), err)
+			log.Warnf("Failed to deserialize Dandelion response - %v", err)
+		}
+		if record.FunctionTimeout {
+			record.ResponseTime = time.Since(start).Microseconds()
+			return false, record
 		}
 	} else if i.cfg.AsyncMode {
 		record.AsyncResponseID = string(body)
@@ -152,6 +197,7 @@ func DeserializeDirigentResponse(body []byte, record *mc.ExecutionRecord) error
 
 	record.Instance = deserializedResponse.Function
 	record.ActualDuration = uint32(deserializedResponse.ExecutionTime)
+	classifyStart(record, deserializedResponse.FirstRequest, deserializedResponse.Uptime)
 
 	return nil
 }
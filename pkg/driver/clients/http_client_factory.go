@@ -10,16 +10,17 @@ import (
 	"time"
 )
 
-func CreateHTTPClient(timeout int, invokeProtocol string) *http.Client {
+// CreateHTTPClient returns a client speaking the given protocol, over TLS unless tlsConfig is nil
+func CreateHTTPClient(timeout int, invokeProtocol string, tlsConfig *tls.Config) *http.Client {
 	client := &http.Client{
 		Timeout: time.Duration(timeout) * time.Second,
 	}
 
 	switch invokeProtocol {
 	case "http1":
-		client.Transport = getHttp1Transport(timeout)
+		client.Transport = getHttp1Transport(timeout, tlsConfig)
 	case "http2":
-		client.Transport = getHttp2Transport()
+		client.Transport = getHttp2Transport(tlsConfig)
 	case "grpc":
 	default:
 		logrus.Errorf("Invalid invoke protocol in the configuration file.")
@@ -28,8 +29,9 @@ func CreateHTTPClient(timeout int, invokeProtocol string) *http.Client {
 	return client
 }
 
-func getHttp1Transport(timeout int) *http.Transport {
+func getHttp1Transport(timeout int, tlsConfig *tls.Config) *http.Transport {
 	return &http.Transport{
+		TLSClientConfig: tlsConfig,
 		DialContext: (&net.Dialer{
 			Timeout: time.Duration(timeout) * time.Second,
 		}).DialContext,
@@ -40,7 +42,13 @@ func getHttp1Transport(timeout int) *http.Transport {
 	}
 }
 
-func getHttp2Transport() *http2.Transport {
+func getHttp2Transport(tlsConfig *tls.Config) *http2.Transport {
+	if tlsConfig != nil {
+		return &http2.Transport{
+			TLSClientConfig: tlsConfig,
+		}
+	}
+
 	return &http2.Transport{
 		AllowHTTP: true,
 		DialTLSContext: func(ctx context.Context, network, addr string, cfg *tls.Config) (net.Conn, error) {
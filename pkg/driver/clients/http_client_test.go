@@ -0,0 +1,68 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2023 EASL and the vHive community
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package clients
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/vhive-serverless/loader/pkg/common"
+	"github.com/vhive-serverless/loader/pkg/config"
+	"github.com/vhive-serverless/loader/pkg/workload/standard"
+)
+
+func TestHTTPClientWithPayload(t *testing.T) {
+	server := httptest.NewServer(standard.NewHTTPHandler(standard.TraceFunction, "test-instance"))
+	defer server.Close()
+
+	invoker := CreateInvoker(&config.LoaderConfiguration{
+		Platform:                   "Knative",
+		InvokeProtocol:             "http1",
+		GRPCFunctionTimeoutSeconds: 15,
+	}, nil, nil)
+
+	function := &common.Function{
+		Name:     "test-function",
+		Endpoint: strings.TrimPrefix(server.URL, "http://"),
+	}
+	runtimeSpec := &common.RuntimeSpecification{Runtime: 10, Memory: 128, RequestSize: 32 * 1024, ResponseSize: 128 * 1024}
+
+	success, record := invoker.Invoke(context.Background(), function, runtimeSpec)
+	if !success {
+		t.Fatal("Failed HTTP invocation with payload.")
+	}
+
+	if record.Instance != "test-instance" {
+		t.Errorf("Unexpected instance %s.", record.Instance)
+	}
+	if record.BytesSent != int64(runtimeSpec.RequestSize) {
+		t.Errorf("Unexpected number of bytes sent - got %d, expected %d.", record.BytesSent, runtimeSpec.RequestSize)
+	}
+	if record.BytesReceived < int64(runtimeSpec.ResponseSize) || record.BytesReceived > int64(runtimeSpec.ResponseSize)+512 {
+		t.Errorf("Unexpected number of bytes received - got %d, expected about %d.", record.BytesReceived, runtimeSpec.ResponseSize)
+	}
+}
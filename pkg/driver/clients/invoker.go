@@ -1,6 +1,8 @@
 package clients
 
 import (
+	"context"
+	"io"
 	"sync"
 
 	"github.com/sirupsen/logrus"
@@ -9,17 +11,59 @@ import (
 	"github.com/vhive-serverless/loader/pkg/metric"
 )
 
+// Invoker issues a single invocation of a function. Cancelling the context aborts the invocation in flight, which is
+// then reported as failed.
 type Invoker interface {
-	Invoke(*common.Function, *common.RuntimeSpecification) (bool, *metric.ExecutionRecord)
+	Invoke(context.Context, *common.Function, *common.RuntimeSpecification) (bool, *metric.ExecutionRecord)
 }
 
-func CreateInvoker(cfg *config.LoaderConfiguration, announceDoneExe *sync.WaitGroup, readOpenWhiskMetadata *sync.Mutex) Invoker {
+// CreateInvoker returns the invoker of the configured platform wrapped into the configured middlewares. The retry
+// middleware, if any, is placed where the retry middleware is listed, or outside all the other middlewares otherwise,
+// so that every attempt passes through them.
+func CreateInvoker(cfg *config.LoaderConfiguration, announceDoneExe *sync.WaitGroup, retry Middleware) Invoker {
+	platformInvoker := createPlatformInvoker(cfg, announceDoneExe)
+
+	return &chainedInvoker{
+		Invoker:  Chain(platformInvoker, createMiddlewares(cfg, retry)...),
+		platform: platformInvoker,
+	}
+}
+
+// chainedInvoker is the invoker of a platform wrapped into middlewares, which is closed along with the invoker of the
+// platform
+type chainedInvoker struct {
+	Invoker
+	platform Invoker
+}
+
+func (c *chainedInvoker) Close() error {
+	if closer, ok := c.platform.(io.Closer); ok {
+		return closer.Close()
+	}
+
+	return nil
+}
+
+// CloseInvoker releases the resources held by the invoker, e.g., pooled connections, once no more invocations are
+// issued through it
+func CloseInvoker(invoker Invoker) {
+	closer, ok := invoker.(io.Closer)
+	if !ok {
+		return
+	}
+
+	if err := closer.Close(); err != nil {
+		logrus.Warnf("Failed to close the invoker - %v", err)
+	}
+}
+
+func createPlatformInvoker(cfg *config.LoaderConfiguration, announceDoneExe *sync.WaitGroup) Invoker {
 	switch cfg.Platform {
 	case "AWSLambda":
 		return newAWSLambdaInvoker(announceDoneExe)
 	case "Dirigent":
 		if cfg.InvokeProtocol == "grpc" {
-			return newGRPCInvoker(cfg, ExecutorRPC{})
+			return createGRPCInvoker(cfg, ExecutorRPC{})
 		} else {
 			return newHTTPInvoker(cfg)
 		}
@@ -28,18 +72,35 @@ func CreateInvoker(cfg *config.LoaderConfiguration, announceDoneExe *sync.WaitGr
 	case "Knative":
 		if cfg.InvokeProtocol == "grpc" {
 			if !cfg.VSwarm {
-				return newGRPCInvoker(cfg, ExecutorRPC{})
+				return createGRPCInvoker(cfg, ExecutorRPC{})
 			} else {
-				return newGRPCInvoker(cfg, SayHelloRPC{})
+				return createGRPCInvoker(cfg, SayHelloRPC{})
 			}
 		} else {
 			return newHTTPInvoker(cfg)
 		}
 	case "OpenWhisk":
-		return newOpenWhiskInvoker(announceDoneExe, readOpenWhiskMetadata)
+		return newOpenWhiskInvoker(cfg)
+	case "GenericHTTP":
+		return newGenericHTTPInvoker(cfg)
+	case "Local":
+		if cfg.InvokeProtocol == "grpc" {
+			return createGRPCInvoker(cfg, ExecutorRPC{})
+		} else {
+			return newHTTPInvoker(cfg)
+		}
 	default:
 		logrus.Fatal("Unsupported platform.")
 	}
 
 	return nil
 }
+
+// createGRPCInvoker invokes the configured gRPC methods dynamically if any, and the given method otherwise
+func createGRPCInvoker(cfg *config.LoaderConfiguration, rpc invoker) Invoker {
+	if cfg.GRPCDynamicMethod.Method != "" || len(cfg.GRPCFunctionMethods) > 0 {
+		rpc = newDynamicRPC(cfg)
+	}
+
+	return newGRPCInvoker(cfg, rpc)
+}
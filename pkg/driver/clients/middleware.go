@@ -0,0 +1,253 @@
+package clients
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/vhive-serverless/loader/pkg/common"
+	"github.com/vhive-serverless/loader/pkg/config"
+	"github.com/vhive-serverless/loader/pkg/metric"
+)
+
+// Middleware wraps an Invoker to add a behaviour that is shared by all the platforms
+type Middleware func(Invoker) Invoker
+
+// InvokerFunc adapts an ordinary function to the Invoker interface
+type InvokerFunc func(context.Context, *common.Function, *common.RuntimeSpecification) (bool, *metric.ExecutionRecord)
+
+func (f InvokerFunc) Invoke(ctx context.Context, function *common.Function, runtimeSpec *common.RuntimeSpecification) (bool, *metric.ExecutionRecord) {
+	return f(ctx, function, runtimeSpec)
+}
+
+// Chain wraps the invoker into the given middlewares, the first of which is the outermost one
+func Chain(invoker Invoker, middlewares ...Middleware) Invoker {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		invoker = middlewares[i](invoker)
+	}
+
+	return invoker
+}
+
+func createMiddlewares(cfg *config.LoaderConfiguration, retry Middleware) []Middleware {
+	var middlewares []Middleware
+	retryPlaced := false
+
+	for _, name := range cfg.InvokerMiddlewares {
+		switch name {
+		case common.RetryMiddleware:
+			if retry != nil {
+				middlewares = append(middlewares, retry)
+			}
+			retryPlaced = true
+		case common.LoggingMiddleware:
+			middlewares = append(middlewares, LoggingMiddleware())
+		case common.RateLimitMiddleware:
+			if cfg.RateLimitRps <= 0 {
+				logrus.Fatal("RateLimitRps has to be positive when the rate_limit middleware is enabled.")
+			}
+			middlewares = append(middlewares, RateLimitMiddleware(cfg.RateLimitRps))
+		case common.TracingMiddleware:
+			middlewares = append(middlewares, TracingMiddleware())
+		case common.ValidationMiddleware:
+			middlewares = append(middlewares, ValidationMiddleware())
+		case common.FaultInjectionMiddleware:
+			if cfg.FaultInjectionProbability < 0 || cfg.FaultInjectionProbability > 1 {
+				logrus.Fatal("FaultInjectionProbability has to be within [0, 1].")
+			}
+			middlewares = append(middlewares, FaultInjectionMiddleware(cfg.FaultInjectionProbability,
+				time.Duration(cfg.FaultInjectionDelayMs)*time.Millisecond, cfg.Seed))
+		default:
+			logrus.Fatalf("Unsupported invoker middleware %s.", name)
+		}
+	}
+
+	if retry != nil && !retryPlaced {
+		middlewares = append([]Middleware{retry}, middlewares...)
+	}
+
+	return middlewares
+}
+
+// failedRecord describes an invocation that has not reached the platform
+func failedRecord(runtimeSpec *common.RuntimeSpecification, start time.Time) *metric.ExecutionRecord {
+	return &metric.ExecutionRecord{
+		ExecutionRecordBase: metric.ExecutionRecordBase{
+			StartTime:         start.UnixMicro(),
+			RequestedDuration: uint32(runtimeSpec.Runtime * 1e3),
+			ResponseTime:      time.Since(start).Microseconds(),
+			ConnectionTimeout: true,
+		},
+	}
+}
+
+// LoggingMiddleware logs the outcome of every invocation
+func LoggingMiddleware() Middleware {
+	return func(next Invoker) Invoker {
+		return InvokerFunc(func(ctx context.Context, function *common.Function, runtimeSpec *common.RuntimeSpecification) (bool, *metric.ExecutionRecord) {
+			logrus.Debugf("Invoking %s - %d[ms], %d[MiB]", function.Name, runtimeSpec.Runtime, runtimeSpec.Memory)
+
+			success, record := next.Invoke(ctx, function, runtimeSpec)
+
+			logrus.Debugf("Invocation of %s returned - success = %t, response time = %.2f[ms], instance = %s",
+				function.Name, success, float64(record.ResponseTime)/1e3, record.Instance)
+
+			return success, record
+		})
+	}
+}
+
+// RateLimitMiddleware spaces out the invocations across all the functions so that no more than rps of them are
+// issued per second. The time spent waiting is added to the client-side queueing delay of the invocation.
+func RateLimitMiddleware(rps float64) Middleware {
+	interval := time.Duration(float64(time.Second) / rps)
+
+	var next time.Time
+	mutex := sync.Mutex{}
+
+	return func(invoker Invoker) Invoker {
+		return InvokerFunc(func(ctx context.Context, function *common.Function, runtimeSpec *common.RuntimeSpecification) (bool, *metric.ExecutionRecord) {
+			start := time.Now()
+
+			mutex.Lock()
+			if next.Before(start) {
+				next = start
+			}
+			slot := next
+			next = next.Add(interval)
+			mutex.Unlock()
+
+			if delay := time.Until(slot); delay > 0 {
+				timer := time.NewTimer(delay)
+
+				select {
+				case <-timer.C:
+				case <-ctx.Done():
+					timer.Stop()
+					logrus.Debugf("Invocation of %s cancelled while waiting for the rate limiter.", function.Name)
+
+					return false, failedRecord(runtimeSpec, start)
+				}
+			}
+
+			queueingDelay := time.Since(start).Microseconds()
+			success, record := invoker.Invoke(ctx, function, runtimeSpec)
+			record.ClientQueueingDelay += queueingDelay
+
+			return success, record
+		})
+	}
+}
+
+type requestHeadersKey struct{}
+
+// withRequestHeaders returns a context carrying headers that invokers attach to the outgoing request, as HTTP headers
+// or as gRPC metadata
+func withRequestHeaders(ctx context.Context, headers map[string]string) context.Context {
+	merged := make(map[string]string)
+	for key, value := range requestHeaders(ctx) {
+		merged[key] = value
+	}
+	for key, value := range headers {
+		merged[key] = value
+	}
+
+	return context.WithValue(ctx, requestHeadersKey{}, merged)
+}
+
+func requestHeaders(ctx context.Context) map[string]string {
+	headers, _ := ctx.Value(requestHeadersKey{}).(map[string]string)
+	return headers
+}
+
+func setRequestHeaders(ctx context.Context, header http.Header) {
+	for key, value := range requestHeaders(ctx) {
+		header.Set(key, value)
+	}
+}
+
+// TracingMiddleware attaches a W3C trace context to every invocation, so that the invocation can be followed through
+// the platform
+func TracingMiddleware() Middleware {
+	return func(next Invoker) Invoker {
+		return InvokerFunc(func(ctx context.Context, function *common.Function, runtimeSpec *common.RuntimeSpecification) (bool, *metric.ExecutionRecord) {
+			traceID, spanID := uuid.New(), uuid.New()
+			traceParent := fmt.Sprintf("00-%s-%s-01", hex.EncodeToString(traceID[:]), hex.EncodeToString(spanID[:8]))
+
+			logrus.Tracef("Invoking %s with trace parent %s", function.Name, traceParent)
+
+			return next.Invoke(withRequestHeaders(ctx, map[string]string{"traceparent": traceParent}), function, runtimeSpec)
+		})
+	}
+}
+
+// ValidationMiddleware reports an invocation as failed if its record shows that the function has not completed
+// successfully, even though the platform has replied
+func ValidationMiddleware() Middleware {
+	return func(next Invoker) Invoker {
+		return InvokerFunc(func(ctx context.Context, function *common.Function, runtimeSpec *common.RuntimeSpecification) (bool, *metric.ExecutionRecord) {
+			success, record := next.Invoke(ctx, function, runtimeSpec)
+			if !success {
+				return false, record
+			}
+
+			switch {
+			case record.HttpStatusCode != 0 && (record.HttpStatusCode < 200 || record.HttpStatusCode >= 300):
+				logrus.Debugf("Invocation of %s failed validation - status code %d.", function.Name, record.HttpStatusCode)
+			case record.ConnectionTimeout || record.FunctionTimeout:
+				logrus.Debugf("Invocation of %s failed validation - timeout.", function.Name)
+			case record.MemoryAllocationTimeout:
+				logrus.Debugf("Invocation of %s failed validation - memory allocation timeout.", function.Name)
+			default:
+				return true, record
+			}
+
+			return false, record
+		})
+	}
+}
+
+// FaultInjectionMiddleware fails the given share of the invocations without issuing them and delays the remaining
+// ones by the given time, which is accounted for in their response time as if it was spent in the network
+func FaultInjectionMiddleware(probability float64, delay time.Duration, seed int64) Middleware {
+	random := rand.New(rand.NewSource(seed))
+	mutex := sync.Mutex{}
+
+	return func(next Invoker) Invoker {
+		return InvokerFunc(func(ctx context.Context, function *common.Function, runtimeSpec *common.RuntimeSpecification) (bool, *metric.ExecutionRecord) {
+			start := time.Now()
+
+			mutex.Lock()
+			inject := random.Float64() < probability
+			mutex.Unlock()
+
+			if inject {
+				logrus.Debugf("Injecting a failure into the invocation of %s.", function.Name)
+				return false, failedRecord(runtimeSpec, start)
+			}
+
+			if delay > 0 {
+				timer := time.NewTimer(delay)
+
+				select {
+				case <-timer.C:
+				case <-ctx.Done():
+					timer.Stop()
+					return false, failedRecord(runtimeSpec, start)
+				}
+			}
+
+			success, record := next.Invoke(ctx, function, runtimeSpec)
+			record.ResponseTime += record.StartTime - start.UnixMicro()
+			record.StartTime = start.UnixMicro()
+
+			return success, record
+		})
+	}
+}
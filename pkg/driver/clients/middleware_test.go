@@ -0,0 +1,211 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2023 EASL and the vHive community
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package clients
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vhive-serverless/loader/pkg/common"
+	"github.com/vhive-serverless/loader/pkg/config"
+	"github.com/vhive-serverless/loader/pkg/metric"
+)
+
+func createFakeInvoker(record metric.ExecutionRecord, calls *int) Invoker {
+	return InvokerFunc(func(ctx context.Context, function *common.Function, runtimeSpec *common.RuntimeSpecification) (bool, *metric.ExecutionRecord) {
+		*calls++
+
+		result := record
+		result.StartTime = time.Now().UnixMicro()
+
+		return true, &result
+	})
+}
+
+func TestChainOrder(t *testing.T) {
+	var order []string
+	tag := func(name string) Middleware {
+		return func(next Invoker) Invoker {
+			return InvokerFunc(func(ctx context.Context, function *common.Function, runtimeSpec *common.RuntimeSpecification) (bool, *metric.ExecutionRecord) {
+				order = append(order, name)
+				return next.Invoke(ctx, function, runtimeSpec)
+			})
+		}
+	}
+
+	calls := 0
+	invoker := Chain(createFakeInvoker(metric.ExecutionRecord{}, &calls), tag("outer"), tag("inner"))
+	invoker.Invoke(context.Background(), &common.Function{Name: "f"}, &common.RuntimeSpecification{})
+
+	if strings.Join(order, ",") != "outer,inner" || calls != 1 {
+		t.Errorf("Unexpected order of middlewares - %v, %d calls.", order, calls)
+	}
+}
+
+func TestRetryMiddlewarePlacement(t *testing.T) {
+	tests := []struct {
+		testName      string
+		middlewares   []string
+		expectedOrder string
+	}{
+		{
+			testName:      "outermost_by_default",
+			middlewares:   []string{common.LoggingMiddleware, common.ValidationMiddleware},
+			expectedOrder: "retry,logging,validation",
+		},
+		{
+			testName:      "listed",
+			middlewares:   []string{common.LoggingMiddleware, common.RetryMiddleware, common.ValidationMiddleware},
+			expectedOrder: "logging,retry,validation",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.testName, func(t *testing.T) {
+			var order []string
+			tag := func(name string) Middleware {
+				return func(next Invoker) Invoker {
+					return InvokerFunc(func(ctx context.Context, function *common.Function, runtimeSpec *common.RuntimeSpecification) (bool, *metric.ExecutionRecord) {
+						order = append(order, name)
+						return next.Invoke(ctx, function, runtimeSpec)
+					})
+				}
+			}
+
+			middlewares := createMiddlewares(&config.LoaderConfiguration{InvokerMiddlewares: test.middlewares}, tag("retry"))
+			for i, name := range test.middlewares {
+				if name != common.RetryMiddleware {
+					// the configured middlewares are replaced by tags to observe the order
+					middlewares[i+len(middlewares)-len(test.middlewares)] = tag(name)
+				}
+			}
+
+			calls := 0
+			Chain(createFakeInvoker(metric.ExecutionRecord{}, &calls), middlewares...).Invoke(context.Background(), &common.Function{Name: "f"}, &common.RuntimeSpecification{})
+
+			if strings.Join(order, ",") != test.expectedOrder {
+				t.Errorf("Unexpected order of middlewares - %v.", order)
+			}
+		})
+	}
+}
+
+func TestValidationMiddleware(t *testing.T) {
+	tests := []struct {
+		testName        string
+		record          metric.ExecutionRecord
+		expectedSuccess bool
+	}{
+		{
+			testName:        "grpc_success",
+			record:          metric.ExecutionRecord{},
+			expectedSuccess: true,
+		},
+		{
+			testName:        "http_success",
+			record:          metric.ExecutionRecord{HttpStatusCode: 200},
+			expectedSuccess: true,
+		},
+		{
+			testName:        "http_server_error",
+			record:          metric.ExecutionRecord{HttpStatusCode: 503},
+			expectedSuccess: false,
+		},
+		{
+			testName:        "memory_allocation_timeout",
+			record:          metric.ExecutionRecord{MemoryAllocationTimeout: true},
+			expectedSuccess: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.testName, func(t *testing.T) {
+			calls := 0
+			invoker := Chain(createFakeInvoker(test.record, &calls), ValidationMiddleware())
+
+			success, _ := invoker.Invoke(context.Background(), &common.Function{Name: "f"}, &common.RuntimeSpecification{})
+			if success != test.expectedSuccess {
+				t.Errorf("Unexpected outcome of validation - got %t, expected %t.", success, test.expectedSuccess)
+			}
+		})
+	}
+}
+
+func TestFaultInjectionMiddleware(t *testing.T) {
+	calls := 0
+	invoker := Chain(createFakeInvoker(metric.ExecutionRecord{}, &calls), FaultInjectionMiddleware(1, 0, 42))
+
+	success, record := invoker.Invoke(context.Background(), &common.Function{Name: "f"}, &common.RuntimeSpecification{})
+	if success || !record.ConnectionTimeout || calls != 0 {
+		t.Error("Injected failure should not reach the platform.")
+	}
+
+	delay := 50 * time.Millisecond
+	invoker = Chain(createFakeInvoker(metric.ExecutionRecord{}, &calls), FaultInjectionMiddleware(0, delay, 42))
+
+	success, record = invoker.Invoke(context.Background(), &common.Function{Name: "f"}, &common.RuntimeSpecification{})
+	if !success || calls != 1 || record.ResponseTime < delay.Microseconds() {
+		t.Errorf("Injected delay is not accounted for in the response time of %d μs.", record.ResponseTime)
+	}
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	calls := 0
+	invoker := Chain(createFakeInvoker(metric.ExecutionRecord{}, &calls), RateLimitMiddleware(20))
+
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		invoker.Invoke(context.Background(), &common.Function{Name: "f"}, &common.RuntimeSpecification{})
+	}
+
+	// the first invocation is issued right away and the remaining ones are spaced by 50 ms
+	if elapsed := time.Since(start); elapsed < 200*time.Millisecond || calls != 5 {
+		t.Errorf("Rate limit has not been respected - 5 invocations issued in %v.", elapsed)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if success, _ := invoker.Invoke(ctx, &common.Function{Name: "f"}, &common.RuntimeSpecification{}); success {
+		t.Error("Invocation waiting for the rate limiter should fail once the context is cancelled.")
+	}
+}
+
+func TestTracingMiddleware(t *testing.T) {
+	var traceParent string
+	invoker := Chain(InvokerFunc(func(ctx context.Context, function *common.Function, runtimeSpec *common.RuntimeSpecification) (bool, *metric.ExecutionRecord) {
+		traceParent = requestHeaders(ctx)["traceparent"]
+		return true, &metric.ExecutionRecord{}
+	}), TracingMiddleware())
+
+	invoker.Invoke(context.Background(), &common.Function{Name: "f"}, &common.RuntimeSpecification{})
+
+	if !regexp.MustCompile(`^00-[0-9a-f]{32}-[0-9a-f]{16}-01$`).MatchString(traceParent) {
+		t.Errorf("Unexpected trace parent %q.", traceParent)
+	}
+}
@@ -0,0 +1,294 @@
+package clients
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/vhive-serverless/loader/pkg/common"
+	"github.com/vhive-serverless/loader/pkg/config"
+	mc "github.com/vhive-serverless/loader/pkg/metric"
+)
+
+// openWhiskActivationPageSize Maximum number of activations OpenWhisk returns per listing
+const openWhiskActivationPageSize = 200
+
+// OpenWhiskClient talks to the REST API of OpenWhisk, replacing the wsk CLI
+type OpenWhiskClient struct {
+	apiHost   string
+	namespace string
+	user      string
+	password  string
+
+	client *http.Client
+}
+
+type openWhiskAnnotation struct {
+	Key   string `json:"key"`
+	Value any    `json:"value"`
+}
+
+// Activation is the record OpenWhisk keeps for every invocation of an action. Timestamps and durations are in
+// milliseconds. Only the ID is set for an activation that has not completed yet.
+type Activation struct {
+	ActivationID string                `json:"activationId"`
+	Name         string                `json:"name"`
+	Start        int64                 `json:"start"`
+	End          int64                 `json:"end"`
+	Duration     int64                 `json:"duration"`
+	Annotations  []openWhiskAnnotation `json:"annotations"`
+	Response     *ActivationResponse   `json:"response"`
+}
+
+type ActivationResponse struct {
+	Status  string          `json:"status"`
+	Success bool            `json:"success"`
+	Result  json.RawMessage `json:"result"`
+}
+
+func (a *Activation) completed() bool {
+	return a.Response != nil
+}
+
+func (a *Activation) annotation(key string) (int64, bool) {
+	for _, annotation := range a.Annotations {
+		if value, ok := annotation.Value.(float64); ok && annotation.Key == key {
+			return int64(value), true
+		}
+	}
+
+	return 0, false
+}
+
+// NewOpenWhiskClient returns a client for the API host and credentials from the configuration, which default to the
+// ones the wsk CLI has been set up with
+func NewOpenWhiskClient(cfg *config.LoaderConfiguration) *OpenWhiskClient {
+	apiHost, auth := cfg.OpenWhiskAPIHost, cfg.OpenWhiskAuth
+	if apiHost == "" || auth == "" {
+		properties := readWskProperties()
+		if apiHost == "" {
+			apiHost = properties["APIHOST"]
+		}
+		if auth == "" {
+			auth = properties["AUTH"]
+		}
+	}
+
+	if apiHost == "" || auth == "" {
+		log.Fatal("OpenWhisk API host and credentials have to be configured, either in the configuration or through wsk.")
+	}
+	if !strings.Contains(apiHost, "://") {
+		apiHost = "https://" + apiHost
+	}
+
+	user, password, _ := strings.Cut(auth, ":")
+
+	namespace := cfg.OpenWhiskNamespace
+	if namespace == "" {
+		namespace = common.OpenWhiskDefaultNamespace
+	}
+
+	return &OpenWhiskClient{
+		apiHost:   strings.TrimSuffix(apiHost, "/"),
+		namespace: namespace,
+		user:      user,
+		password:  password,
+
+		client: &http.Client{
+			Timeout: time.Duration(cfg.GRPCFunctionTimeoutSeconds) * time.Second,
+			Transport: &http.Transport{
+				// OpenWhisk deployments typically use self-signed certificates, as assumed by wsk -i
+				TLSClientConfig:     &tls.Config{InsecureSkipVerify: !cfg.OpenWhiskVerifyTLS},
+				MaxIdleConnsPerHost: 100,
+			},
+		},
+	}
+}
+
+// readWskProperties reads the properties set with wsk property set, from the same file as the wsk CLI does
+func readWskProperties() map[string]string {
+	properties := make(map[string]string)
+
+	path := os.Getenv("WSK_CONFIG_FILE")
+	if path == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return properties
+		}
+		path = filepath.Join(home, ".wskprops")
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return properties
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if key, value, ok := strings.Cut(strings.TrimSpace(scanner.Text()), "="); ok {
+			properties[key] = value
+		}
+	}
+
+	return properties
+}
+
+// ActionURL returns the URL of the action with the given name
+func (c *OpenWhiskClient) ActionURL(name string) string {
+	return fmt.Sprintf("%s/api/v1/namespaces/%s/actions/%s", c.apiHost, url.PathEscape(c.namespace), url.PathEscape(name))
+}
+
+func (c *OpenWhiskClient) do(ctx context.Context, method string, requestURL string, body any) (*http.Response, []byte, error) {
+	var requestBody io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, nil, err
+		}
+		requestBody = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, requestURL, requestBody)
+	if err != nil {
+		return nil, nil, err
+	}
+	req.SetBasicAuth(c.user, c.password)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer HandleBodyClosing(resp)
+
+	responseBody, err := io.ReadAll(resp.Body)
+	return resp, responseBody, err
+}
+
+// CreateAction creates the action from the given source code, or updates it if it exists already
+func (c *OpenWhiskClient) CreateAction(ctx context.Context, name string, kind string, code []byte) error {
+	resp, body, err := c.do(ctx, http.MethodPut, c.ActionURL(name)+"?overwrite=true", map[string]any{
+		"exec": map[string]any{
+			"kind": kind,
+			"code": string(code),
+		},
+		"annotations": []openWhiskAnnotation{
+			{Key: "web-export", Value: true},
+			{Key: "raw-http", Value: false},
+			{Key: "final", Value: true},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("status code %d - %s", resp.StatusCode, string(body))
+	}
+
+	return nil
+}
+
+func (c *OpenWhiskClient) DeleteAction(ctx context.Context, name string) error {
+	resp, body, err := c.do(ctx, http.MethodDelete, c.ActionURL(name), nil)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("status code %d - %s", resp.StatusCode, string(body))
+	}
+
+	return nil
+}
+
+// Invoke invokes the action and returns its activation along with the status code of the response. A blocking
+// invocation returns the completed activation, unless the action runs for longer than OpenWhisk waits for, in which
+// case only the activation ID is returned, as for a non-blocking invocation.
+func (c *OpenWhiskClient) Invoke(ctx context.Context, name string, parameters any, blocking bool) (*Activation, int, error) {
+	requestURL := fmt.Sprintf("%s?blocking=%t&result=false", c.ActionURL(name), blocking)
+
+	resp, body, err := c.do(ctx, http.MethodPost, requestURL, parameters)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// 502 is returned along with the activation if the action has failed
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusAccepted && resp.StatusCode != http.StatusBadGateway {
+		return nil, resp.StatusCode, fmt.Errorf("status code %d - %s", resp.StatusCode, string(body))
+	}
+
+	activation := &Activation{}
+	if err = json.Unmarshal(body, activation); err != nil {
+		return nil, resp.StatusCode, err
+	}
+
+	return activation, resp.StatusCode, nil
+}
+
+// ListActivations returns the records of all the activations in the namespace that have started since the given time
+// in milliseconds since the epoch
+func (c *OpenWhiskClient) ListActivations(ctx context.Context, since int64) ([]Activation, error) {
+	var result []Activation
+
+	for skip := 0; ; skip += openWhiskActivationPageSize {
+		requestURL := fmt.Sprintf("%s/api/v1/namespaces/%s/activations?docs=true&limit=%d&skip=%d&since=%s",
+			c.apiHost, url.PathEscape(c.namespace), openWhiskActivationPageSize, skip, strconv.FormatInt(since, 10))
+
+		resp, body, err := c.do(ctx, http.MethodGet, requestURL, nil)
+		if err != nil {
+			return result, err
+		}
+		if resp.StatusCode != http.StatusOK {
+			return result, fmt.Errorf("status code %d - %s", resp.StatusCode, string(body))
+		}
+
+		var page []Activation
+		if err = json.Unmarshal(body, &page); err != nil {
+			return result, err
+		}
+
+		result = append(result, page...)
+		if len(page) < openWhiskActivationPageSize {
+			return result, nil
+		}
+	}
+}
+
+// FillActivationRecord copies the measurements of a completed activation into the record and reports whether the
+// action has succeeded
+func FillActivationRecord(record *mc.ExecutionRecord, activation *Activation) bool {
+	record.ActualDuration = uint32(activation.Duration * 1000) // ms to µs
+
+	waitTime, _ := activation.annotation("waitTime")
+	record.WaitTime = waitTime * 1000
+
+	// OpenWhisk only annotates the activations that have initialized a container
+	if initTime, ok := activation.annotation("initTime"); ok {
+		record.StartType = mc.Cold
+		record.InitTime = initTime * 1000
+	} else {
+		record.StartType = mc.Hot
+		record.InitTime = 0
+	}
+
+	if !activation.Response.Success {
+		log.Debugf("Activation %s of %s has failed - %s", activation.ActivationID, activation.Name, activation.Response.Status)
+		record.FunctionTimeout = true
+
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,227 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2023 EASL and the vHive community
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package clients
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/vhive-serverless/loader/pkg/common"
+	"github.com/vhive-serverless/loader/pkg/config"
+	mc "github.com/vhive-serverless/loader/pkg/metric"
+)
+
+// fakeOpenWhisk stands in for the REST API of OpenWhisk, where every invocation of an action initializes a container
+// on its first activation only
+type fakeOpenWhisk struct {
+	actions     map[string]string
+	activations []Activation
+	mutex       sync.Mutex
+}
+
+func newFakeOpenWhisk(t *testing.T) (*fakeOpenWhisk, *httptest.Server) {
+	fake := &fakeOpenWhisk{actions: make(map[string]string)}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, password, ok := r.BasicAuth(); !ok || user != "user" || password != "key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		fake.mutex.Lock()
+		defer fake.mutex.Unlock()
+
+		switch name, isAction := strings.CutPrefix(r.URL.Path, "/api/v1/namespaces/_/actions/"); {
+		case isAction && r.Method == http.MethodPut:
+			var action struct {
+				Exec struct {
+					Kind string `json:"kind"`
+					Code string `json:"code"`
+				} `json:"exec"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&action); err != nil || action.Exec.Kind != common.OpenWhiskActionKind {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			fake.actions[name] = action.Exec.Code
+		case isAction && r.Method == http.MethodDelete:
+			if _, ok := fake.actions[name]; !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			delete(fake.actions, name)
+		case isAction && r.Method == http.MethodPost:
+			var parameters map[string]string
+			if err := json.NewDecoder(r.Body).Decode(&parameters); err != nil || parameters["cpu"] == "" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+
+			activation := fake.activate(name)
+			if r.URL.Query().Get("blocking") != "true" {
+				w.WriteHeader(http.StatusAccepted)
+				_ = json.NewEncoder(w).Encode(map[string]string{"activationId": activation.ActivationID})
+				return
+			}
+			_ = json.NewEncoder(w).Encode(activation)
+		case r.URL.Path == "/api/v1/namespaces/_/activations" && r.Method == http.MethodGet:
+			limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+			skip, _ := strconv.Atoi(r.URL.Query().Get("skip"))
+			if r.URL.Query().Get("docs") != "true" || limit <= 0 {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+
+			page := fake.activations[min(skip, len(fake.activations)):min(skip+limit, len(fake.activations))]
+			_ = json.NewEncoder(w).Encode(page)
+		default:
+			t.Errorf("Unexpected request %s %s.", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+
+	return fake, server
+}
+
+// activate has to be called with the mutex held
+func (f *fakeOpenWhisk) activate(name string) Activation {
+	activation := Activation{
+		ActivationID: fmt.Sprintf("activation-%d", len(f.activations)),
+		Name:         name,
+		Start:        1000,
+		End:          1025,
+		Duration:     25,
+		Annotations:  []openWhiskAnnotation{{Key: "waitTime", Value: 3}},
+	}
+	if len(f.activations) == 0 {
+		activation.Annotations = append(activation.Annotations, openWhiskAnnotation{Key: "initTime", Value: 150})
+	}
+
+	activation.Response = &ActivationResponse{Status: "success", Success: true, Result: json.RawMessage(`{}`)}
+
+	f.activations = append(f.activations, activation)
+
+	return activation
+}
+
+func createFakeOpenWhiskConfiguration(apiHost string, invocationMode string) *config.LoaderConfiguration {
+	return &config.LoaderConfiguration{
+		Platform:                   "OpenWhisk",
+		OpenWhiskAPIHost:           apiHost,
+		OpenWhiskAuth:              "user:key",
+		OpenWhiskInvocationMode:    invocationMode,
+		GRPCFunctionTimeoutSeconds: 5,
+	}
+}
+
+func TestOpenWhiskActions(t *testing.T) {
+	fake, server := newFakeOpenWhisk(t)
+	defer server.Close()
+
+	client := NewOpenWhiskClient(createFakeOpenWhiskConfiguration(server.URL, ""))
+
+	if err := client.CreateAction(context.Background(), "test-function", common.OpenWhiskActionKind, []byte("package main")); err != nil {
+		t.Fatalf("Failed to create the action - %v", err)
+	}
+	if fake.actions["test-function"] != "package main" {
+		t.Error("Action has not been created with its source code.")
+	}
+
+	if err := client.DeleteAction(context.Background(), "test-function"); err != nil || len(fake.actions) != 0 {
+		t.Errorf("Failed to delete the action - %v", err)
+	}
+	if err := client.DeleteAction(context.Background(), "test-function"); err == nil {
+		t.Error("Deleting a missing action has not failed.")
+	}
+
+	if err := NewOpenWhiskClient(&config.LoaderConfiguration{OpenWhiskAPIHost: server.URL, OpenWhiskAuth: "user:wrong"}).
+		CreateAction(context.Background(), "test-function", common.OpenWhiskActionKind, nil); err == nil {
+		t.Error("Request with wrong credentials has not failed.")
+	}
+}
+
+func TestOpenWhiskBlockingInvocation(t *testing.T) {
+	_, server := newFakeOpenWhisk(t)
+	defer server.Close()
+
+	invoker := CreateInvoker(createFakeOpenWhiskConfiguration(server.URL, common.OpenWhiskBlocking), nil, nil)
+	function := &common.Function{Name: "test-function"}
+
+	for i, expectedStartType := range []mc.StartType{mc.Cold, mc.Hot} {
+		success, record := invoker.Invoke(context.Background(), function, &testRuntimeSpecs)
+		if !success || record.AsyncResponseID != "" || record.HttpStatusCode != http.StatusOK {
+			t.Fatalf("Blocking invocation %d has failed.", i)
+		}
+
+		if record.StartType != expectedStartType || record.ActualDuration != 25_000 || record.WaitTime != 3_000 {
+			t.Errorf("Unexpected record of invocation %d - %s start, %d μs duration, %d μs wait time.",
+				i, record.StartType, record.ActualDuration, record.WaitTime)
+		}
+		if expectedStartType == mc.Cold && record.InitTime != 150_000 {
+			t.Errorf("Unexpected init time %d μs.", record.InitTime)
+		}
+	}
+}
+
+func TestOpenWhiskNonBlockingInvocation(t *testing.T) {
+	_, server := newFakeOpenWhisk(t)
+	defer server.Close()
+
+	cfg := createFakeOpenWhiskConfiguration(server.URL, common.OpenWhiskNonBlocking)
+	invoker := CreateInvoker(cfg, nil, nil)
+
+	submitted := make(map[string]bool)
+	for i := 0; i < openWhiskActivationPageSize+10; i++ {
+		success, record := invoker.Invoke(context.Background(), &common.Function{Name: "test-function"}, &testRuntimeSpecs)
+		if !success || record.AsyncResponseID == "" || record.HttpStatusCode != http.StatusAccepted {
+			t.Fatalf("Non-blocking invocation %d has failed.", i)
+		}
+
+		submitted[record.AsyncResponseID] = true
+	}
+
+	activations, err := NewOpenWhiskClient(cfg).ListActivations(context.Background(), 0)
+	if err != nil || len(activations) != len(submitted) {
+		t.Fatalf("Failed to list all the activations - %d listed, %v.", len(activations), err)
+	}
+
+	for i := range activations {
+		if !submitted[activations[i].ActivationID] {
+			t.Errorf("Unexpected activation %s listed.", activations[i].ActivationID)
+		}
+
+		record := &mc.ExecutionRecord{}
+		if !FillActivationRecord(record, &activations[i]) || (i == 0) != (record.StartType == mc.Cold) {
+			t.Errorf("Unexpected record of activation %s.", activations[i].ActivationID)
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package clients
+
+import (
+	"math/rand"
+	"sync"
+
+	"github.com/vhive-serverless/loader/pkg/common"
+)
+
+var (
+	payloadBuffer     []byte
+	payloadBufferOnce sync.Once
+)
+
+// payload returns random bytes of the given size, which are shared by all the invocations and must not be modified.
+// Random content keeps compression along the way from shrinking the payload.
+func payload(size int) []byte {
+	if size <= 0 {
+		return nil
+	}
+
+	payloadBufferOnce.Do(func() {
+		payloadBuffer = make([]byte, common.MaxPayloadSizeBytes)
+		rand.New(rand.NewSource(42)).Read(payloadBuffer)
+	})
+
+	return payloadBuffer[:common.MinOf(size, common.MaxPayloadSizeBytes)]
+}
@@ -0,0 +1,75 @@
+package clients
+
+import (
+	"context"
+	"encoding/base64"
+	"strings"
+	"text/template"
+
+	"github.com/google/uuid"
+	log "github.com/sirupsen/logrus"
+	"github.com/vhive-serverless/loader/pkg/common"
+)
+
+// RequestTemplateData holds the values the request templates of the GenericHTTP platform and of dynamic gRPC
+// invocations are evaluated with
+type RequestTemplateData struct {
+	Function string
+	Endpoint string
+	// Runtime and Memory Requested execution time in milliseconds and memory in MiB
+	Runtime int
+	Memory  int
+	// InvocationID Identifier of the invocation assigned by the loader, e.g., min0.inv3, or a UUID if there is none
+	InvocationID string
+	// Payload Request payload encoded in base64, of PayloadSize bytes before encoding
+	Payload      string
+	PayloadSize  int
+	ResponseSize int
+}
+
+type invocationIDKey struct{}
+
+// WithInvocationID returns a context carrying the identifier the loader has assigned to the invocation, which is
+// passed to the request templates
+func WithInvocationID(ctx context.Context, invocationID string) context.Context {
+	return context.WithValue(ctx, invocationIDKey{}, invocationID)
+}
+
+func invocationID(ctx context.Context) string {
+	if id, ok := ctx.Value(invocationIDKey{}).(string); ok && id != "" {
+		return id
+	}
+
+	return uuid.New().String()
+}
+
+func newRequestTemplateData(ctx context.Context, function *common.Function, runtimeSpec *common.RuntimeSpecification, requestPayload []byte) *RequestTemplateData {
+	return &RequestTemplateData{
+		Function:     function.Name,
+		Endpoint:     function.Endpoint,
+		Runtime:      runtimeSpec.Runtime,
+		Memory:       runtimeSpec.Memory,
+		InvocationID: invocationID(ctx),
+		Payload:      base64.StdEncoding.EncodeToString(requestPayload),
+		PayloadSize:  len(requestPayload),
+		ResponseSize: runtimeSpec.ResponseSize,
+	}
+}
+
+func mustParseTemplate(name string, text string) *template.Template {
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		log.Fatalf("Failed to parse the %s template - %v", name, err)
+	}
+
+	return tmpl
+}
+
+func executeTemplate(tmpl *template.Template, data *RequestTemplateData) (string, error) {
+	var result strings.Builder
+	if err := tmpl.Execute(&result, data); err != nil {
+		return "", err
+	}
+
+	return result.String(), nil
+}
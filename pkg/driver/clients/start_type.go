@@ -0,0 +1,49 @@
+package clients
+
+import (
+	"encoding/base64"
+	"regexp"
+	"strconv"
+	"time"
+
+	mc "github.com/vhive-serverless/loader/pkg/metric"
+)
+
+var lambdaInitDurationRegex = regexp.MustCompile(`Init Duration: ([0-9.]+) ms`)
+
+// classifyStart sets the start type of the invocation from the instance that has served it. The invocation is a cold
+// start if it is the first one the instance has served and the instance has started after the invocation was issued,
+// in which case the uptime of the instance is its initialization time. Nothing is set if the uptime is not reported.
+func classifyStart(record *mc.ExecutionRecord, firstRequest bool, uptime int64) {
+	if uptime <= 0 {
+		return
+	}
+
+	if elapsed := time.Now().UnixMicro() - record.StartTime; firstRequest && uptime <= elapsed {
+		record.StartType = mc.Cold
+		record.InitTime = uptime
+	} else {
+		record.StartType = mc.Hot
+	}
+}
+
+// parseLambdaInitDuration returns the initialization time in microseconds from the base64-encoded log tail returned
+// by AWS Lambda, which only reports it for cold starts
+func parseLambdaInitDuration(logResult string) (int64, bool) {
+	logs, err := base64.StdEncoding.DecodeString(logResult)
+	if err != nil {
+		return 0, false
+	}
+
+	match := lambdaInitDurationRegex.FindSubmatch(logs)
+	if match == nil {
+		return 0, false
+	}
+
+	initDuration, err := strconv.ParseFloat(string(match[1]), 64)
+	if err != nil {
+		return 0, false
+	}
+
+	return int64(initDuration * 1e3), true
+}
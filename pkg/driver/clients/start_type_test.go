@@ -0,0 +1,77 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2023 EASL and the vHive community
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package clients
+
+import (
+	"encoding/base64"
+	"testing"
+	"time"
+
+	mc "github.com/vhive-serverless/loader/pkg/metric"
+)
+
+func TestClassifyStart(t *testing.T) {
+	tests := []struct {
+		testName         string
+		firstRequest     bool
+		uptime           int64
+		expectedType     mc.StartType
+		expectedInitTime int64
+	}{
+		{testName: "not_reported", firstRequest: true, uptime: 0, expectedType: "", expectedInitTime: 0},
+		{testName: "cold_start", firstRequest: true, uptime: 50_000, expectedType: mc.Cold, expectedInitTime: 50_000},
+		{testName: "warm_start", firstRequest: false, uptime: 50_000, expectedType: mc.Hot, expectedInitTime: 0},
+		{testName: "prewarmed_instance", firstRequest: true, uptime: 60_000_000, expectedType: mc.Hot, expectedInitTime: 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.testName, func(t *testing.T) {
+			record := &mc.ExecutionRecord{}
+			record.StartTime = time.Now().Add(-100 * time.Millisecond).UnixMicro()
+
+			classifyStart(record, test.firstRequest, test.uptime)
+
+			if record.StartType != test.expectedType || record.InitTime != test.expectedInitTime {
+				t.Errorf("Unexpected classification - got %q and %d μs.", record.StartType, record.InitTime)
+			}
+		})
+	}
+}
+
+func TestParseLambdaInitDuration(t *testing.T) {
+	coldLogs := "START RequestId: 42 Version: $LATEST\nEND RequestId: 42\nREPORT RequestId: 42\tDuration: 12.34 ms\t" +
+		"Billed Duration: 13 ms\tMemory Size: 128 MB\tMax Memory Used: 30 MB\tInit Duration: 187.65 ms\t\n"
+	warmLogs := "REPORT RequestId: 43\tDuration: 10.01 ms\tBilled Duration: 11 ms\tMemory Size: 128 MB\t\n"
+
+	if initTime, ok := parseLambdaInitDuration(base64.StdEncoding.EncodeToString([]byte(coldLogs))); !ok || initTime != 187_650 {
+		t.Errorf("Unexpected init duration of a cold start - %d μs.", initTime)
+	}
+	if _, ok := parseLambdaInitDuration(base64.StdEncoding.EncodeToString([]byte(warmLogs))); ok {
+		t.Error("Init duration found in the logs of a warm start.")
+	}
+	if _, ok := parseLambdaInitDuration(""); ok {
+		t.Error("Init duration found without logs.")
+	}
+}
@@ -0,0 +1,181 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2023 EASL and the vHive community
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package driver
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/vhive-serverless/loader/pkg/common"
+)
+
+const (
+	controlStateRunning = "running"
+	controlStatePaused  = "paused"
+	controlStateStopped = "stopped"
+)
+
+// invocationCounters points to the result statistics kept by a single function driver
+type invocationCounters struct {
+	successful *int64
+	failed     *int64
+	issued     *int64
+}
+
+type experimentStatus struct {
+	State          string  `json:"state"`
+	Phase          string  `json:"phase"`
+	Minute         int64   `json:"minute"`
+	RateMultiplier float64 `json:"rateMultiplier"`
+	Issued         int64   `json:"issued"`
+	Succeeded      int64   `json:"succeeded"`
+	Failed         int64   `json:"failed"`
+}
+
+// registerCounters exposes the result statistics of a function driver through the control server while the
+// experiment is running
+func (d *Driver) registerCounters(successful *int64, failed *int64, issued *int64) {
+	d.countersMutex.Lock()
+	defer d.countersMutex.Unlock()
+
+	d.counters = append(d.counters, invocationCounters{
+		successful: successful,
+		failed:     failed,
+		issued:     issued,
+	})
+}
+
+func (d *Driver) status() experimentStatus {
+	rate, paused, _ := d.clock.state()
+
+	status := experimentStatus{
+		State:          controlStateRunning,
+		Phase:          common.ExecutionPhase.String(),
+		Minute:         atomic.LoadInt64(&d.currentMinute),
+		RateMultiplier: rate,
+	}
+
+	if d.isAborted() {
+		status.State = controlStateStopped
+	} else if paused {
+		status.State = controlStatePaused
+	}
+
+	if d.Configuration.WithWarmup() && status.Minute < int64(d.Configuration.LoaderConfiguration.WarmupDuration) {
+		status.Phase = common.WarmupPhase.String()
+	}
+
+	d.countersMutex.Lock()
+	defer d.countersMutex.Unlock()
+
+	for _, counters := range d.counters {
+		status.Succeeded += atomic.LoadInt64(counters.successful)
+		status.Failed += atomic.LoadInt64(counters.failed)
+		status.Issued += atomic.LoadInt64(counters.issued)
+	}
+
+	return status
+}
+
+// startControlServer exposes an HTTP API to inspect and steer the experiment while it is running. Returns nil if the
+// control server has not been enabled in the configuration.
+func (d *Driver) startControlServer() *http.Server {
+	address := d.Configuration.LoaderConfiguration.ControlServerAddress
+	if address == "" {
+		return nil
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /status", d.handleStatus)
+	mux.HandleFunc("POST /pause", d.handlePause)
+	mux.HandleFunc("POST /resume", d.handleResume)
+	mux.HandleFunc("POST /rate", d.handleRate)
+	mux.HandleFunc("POST /stop", d.handleStop)
+
+	server := &http.Server{
+		Addr:    address,
+		Handler: mux,
+	}
+
+	go func() {
+		err := server.ListenAndServe()
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Errorf("Control server failed - %v", err)
+		}
+	}()
+
+	log.Infof("Control server is listening on %s.", address)
+
+	return server
+}
+
+func (d *Driver) writeStatus(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if err := json.NewEncoder(w).Encode(d.status()); err != nil {
+		log.Warnf("Failed to write the experiment status - %v", err)
+	}
+}
+
+func (d *Driver) handleStatus(w http.ResponseWriter, _ *http.Request) {
+	d.writeStatus(w)
+}
+
+func (d *Driver) handlePause(w http.ResponseWriter, _ *http.Request) {
+	log.Infof("Pausing the load through the control server.")
+	d.clock.setPaused(true)
+
+	d.writeStatus(w)
+}
+
+func (d *Driver) handleResume(w http.ResponseWriter, _ *http.Request) {
+	log.Infof("Resuming the load through the control server.")
+	d.clock.setPaused(false)
+
+	d.writeStatus(w)
+}
+
+func (d *Driver) handleRate(w http.ResponseWriter, r *http.Request) {
+	multiplier, err := strconv.ParseFloat(r.URL.Query().Get("multiplier"), 64)
+	if err != nil || multiplier <= 0 {
+		http.Error(w, "Rate multiplier should be a positive number.", http.StatusBadRequest)
+		return
+	}
+
+	log.Infof("Setting the rate multiplier to %.2f through the control server.", multiplier)
+	d.clock.setRate(multiplier)
+
+	d.writeStatus(w)
+}
+
+func (d *Driver) handleStop(w http.ResponseWriter, _ *http.Request) {
+	d.abortExperiment("The experiment has been stopped through the control server.")
+
+	d.writeStatus(w)
+}
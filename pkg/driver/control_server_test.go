@@ -0,0 +1,129 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2023 EASL and the vHive community
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package driver
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+)
+
+func TestControlServer(t *testing.T) {
+	driver := createTestDriver([]int{1})
+	driver.Configuration.LoaderConfiguration.OutputPathPrefix = filepath.Join(t.TempDir(), "test")
+	driver.clock.start()
+
+	successful, failed, issued := int64(3), int64(1), int64(4)
+	driver.registerCounters(&successful, &failed, &issued)
+
+	tests := []struct {
+		testName       string
+		handler        http.HandlerFunc
+		target         string
+		expectedCode   int
+		expectedState  string
+		expectedRate   float64
+		expectedIssued int64
+	}{
+		{
+			testName:       "status",
+			handler:        driver.handleStatus,
+			target:         "/status",
+			expectedCode:   http.StatusOK,
+			expectedState:  controlStateRunning,
+			expectedRate:   1,
+			expectedIssued: 4,
+		},
+		{
+			testName:       "pause",
+			handler:        driver.handlePause,
+			target:         "/pause",
+			expectedCode:   http.StatusOK,
+			expectedState:  controlStatePaused,
+			expectedRate:   1,
+			expectedIssued: 4,
+		},
+		{
+			testName:       "rate",
+			handler:        driver.handleRate,
+			target:         "/rate?multiplier=2.5",
+			expectedCode:   http.StatusOK,
+			expectedState:  controlStatePaused,
+			expectedRate:   2.5,
+			expectedIssued: 4,
+		},
+		{
+			testName:     "invalid_rate",
+			handler:      driver.handleRate,
+			target:       "/rate?multiplier=-1",
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			testName:       "resume",
+			handler:        driver.handleResume,
+			target:         "/resume",
+			expectedCode:   http.StatusOK,
+			expectedState:  controlStateRunning,
+			expectedRate:   2.5,
+			expectedIssued: 4,
+		},
+		{
+			testName:       "stop",
+			handler:        driver.handleStop,
+			target:         "/stop",
+			expectedCode:   http.StatusOK,
+			expectedState:  controlStateStopped,
+			expectedRate:   2.5,
+			expectedIssued: 4,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.testName, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			test.handler(recorder, httptest.NewRequest(http.MethodPost, test.target, nil))
+
+			if recorder.Code != test.expectedCode {
+				t.Fatalf("Unexpected status code - got %d, expected %d.", recorder.Code, test.expectedCode)
+			}
+			if test.expectedCode != http.StatusOK {
+				return
+			}
+
+			var status experimentStatus
+			if err := json.NewDecoder(recorder.Body).Decode(&status); err != nil {
+				t.Fatal(err)
+			}
+
+			if status.State != test.expectedState || status.RateMultiplier != test.expectedRate ||
+				status.Issued != test.expectedIssued || status.Succeeded != successful || status.Failed != failed {
+
+				t.Errorf("Unexpected experiment status - %+v.", status)
+			}
+		})
+	}
+}
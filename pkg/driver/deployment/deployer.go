@@ -20,6 +20,10 @@ func CreateDeployer(cfg *config.Configuration) FunctionDeployer {
 		return newKnativeDeployer()
 	case "OpenWhisk":
 		return newOpenWhiskDeployer()
+	case "Local":
+		return newLocalDeployer()
+	case "GenericHTTP":
+		return newGenericHTTPDeployer()
 	default:
 		logrus.Fatal("Unsupported platform.")
 	}
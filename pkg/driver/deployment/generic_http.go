@@ -0,0 +1,104 @@
+package deployment
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/vhive-serverless/loader/pkg/common"
+	"github.com/vhive-serverless/loader/pkg/config"
+)
+
+// genericHTTPFunction is the description of a function posted to the webhooks of the GenericHTTP platform
+type genericHTTPFunction struct {
+	Function          string `json:"function"`
+	CPURequestsMilli  int    `json:"cpuRequestsMilli"`
+	MemoryRequestsMiB int    `json:"memoryRequestsMiB"`
+	CPULimitsMilli    int    `json:"cpuLimitsMilli"`
+}
+
+// genericHTTPDeployer leaves the deployment of the functions to the user, unless webhooks are configured, in which
+// case every function is posted to the deployment webhook before the experiment and to the cleanup webhook after it.
+// The deployment webhook may respond with the endpoint of the function, which the request templates can refer to.
+type genericHTTPDeployer struct {
+	functions    []*common.Function
+	cleanWebhook string
+}
+
+var webhookClient = &http.Client{
+	Timeout: 300 * time.Second,
+}
+
+func newGenericHTTPDeployer() *genericHTTPDeployer {
+	return &genericHTTPDeployer{}
+}
+
+func (ghd *genericHTTPDeployer) Deploy(cfg *config.Configuration) {
+	ghd.functions = cfg.Functions
+	ghd.cleanWebhook = cfg.LoaderConfiguration.GenericHTTPCleanWebhook
+
+	webhook := cfg.LoaderConfiguration.GenericHTTPDeployWebhook
+	if webhook == "" {
+		return
+	}
+
+	for _, function := range ghd.functions {
+		body, err := callWebhook(webhook, function)
+		if err != nil {
+			log.Fatalf("Failed to deploy function %s through %s - %v", function.Name, webhook, err)
+		}
+
+		var response struct {
+			Endpoint string `json:"endpoint"`
+		}
+		if json.Unmarshal(body, &response) == nil && response.Endpoint != "" {
+			function.Endpoint = response.Endpoint
+		}
+
+		log.Debugf("Deployed function %s through %s", function.Name, webhook)
+	}
+}
+
+func (ghd *genericHTTPDeployer) Clean() {
+	if ghd.cleanWebhook == "" {
+		return
+	}
+
+	for _, function := range ghd.functions {
+		if _, err := callWebhook(ghd.cleanWebhook, function); err != nil {
+			log.Errorf("Failed to remove function %s through %s - %v", function.Name, ghd.cleanWebhook, err)
+		}
+	}
+}
+
+func callWebhook(webhook string, function *common.Function) ([]byte, error) {
+	payload, err := json.Marshal(genericHTTPFunction{
+		Function:          function.Name,
+		CPURequestsMilli:  function.CPURequestsMilli,
+		MemoryRequestsMiB: function.MemoryRequestsMiB,
+		CPULimitsMilli:    function.CPULimitsMilli,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := webhookClient.Post(webhook, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("status code %d - %s", resp.StatusCode, string(body))
+	}
+
+	return body, nil
+}
@@ -1,17 +1,28 @@
 package deployment
 
 import (
-	"bytes"
+	"context"
 	"fmt"
-	log "github.com/sirupsen/logrus"
-	"github.com/vhive-serverless/loader/pkg/common"
-	"github.com/vhive-serverless/loader/pkg/config"
 	"math"
-	"os/exec"
-	"regexp"
+	"net/url"
+	"os"
 	"runtime"
 	"strconv"
 	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/vhive-serverless/loader/pkg/common"
+	"github.com/vhive-serverless/loader/pkg/config"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/yaml"
 )
 
 const (
@@ -19,11 +30,24 @@ const (
 	namespace          = "default"
 )
 
+var knativeServiceResource = schema.GroupVersionResource{Group: "serving.knative.dev", Version: "v1", Resource: "services"}
+
 var (
-	urlRegex = regexp.MustCompile("at URL:\nhttp://([^\n]+)")
+	// knativeReadyTimeout and knativeReadyPollInterval Bound the time and set the pace of waiting for a Knative
+	// service to become ready
+	knativeReadyTimeout      = 30 * time.Minute
+	knativeReadyPollInterval = time.Second
 )
 
-type knativeDeployer struct{}
+// knativeDeployer creates serving.knative.dev/v1 services from the YAML specification through the Kubernetes API.
+// The client is created from the kubeconfig of the user, or from the service account when running within the cluster.
+type knativeDeployer struct {
+	client dynamic.Interface
+
+	// namespaces Namespaces the functions have been deployed to, all services of which are removed on cleanup
+	namespaces map[string]struct{}
+	mutex      sync.Mutex
+}
 
 type knativeDeploymentConfiguration struct {
 	YamlPath          string
@@ -33,7 +57,9 @@ type knativeDeploymentConfiguration struct {
 }
 
 func newKnativeDeployer() *knativeDeployer {
-	return &knativeDeployer{}
+	return &knativeDeployer{
+		namespaces: make(map[string]struct{}),
+	}
 }
 
 func newKnativeDeployerConfiguration(cfg *config.Configuration) knativeDeploymentConfiguration {
@@ -45,9 +71,48 @@ func newKnativeDeployerConfiguration(cfg *config.Configuration) knativeDeploymen
 	}
 }
 
-func (*knativeDeployer) Deploy(cfg *config.Configuration) {
+func newKubernetesClient() (dynamic.Interface, error) {
+	restConfig, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		clientcmd.NewDefaultClientConfigLoadingRules(),
+		&clientcmd.ConfigOverrides{},
+	).ClientConfig()
+	if err != nil {
+		if restConfig, err = rest.InClusterConfig(); err != nil {
+			return nil, err
+		}
+	}
+
+	return dynamic.NewForConfig(restConfig)
+}
+
+func (kd *knativeDeployer) connect() error {
+	if kd.client != nil {
+		return nil
+	}
+
+	client, err := newKubernetesClient()
+	if err != nil {
+		return fmt.Errorf("failed to create a Kubernetes client - %v", err)
+	}
+	kd.client = client
+
+	return nil
+}
+
+func (kd *knativeDeployer) Deploy(cfg *config.Configuration) {
+	if err := kd.connect(); err != nil {
+		// TODO: there should be a toggle to turn off deployment because if this is fatal then we cannot test the thing locally
+		log.Warnf("Failed to deploy functions - %v", err)
+		return
+	}
+
 	knativeConfig := newKnativeDeployerConfiguration(cfg)
 
+	specification, err := os.ReadFile(knativeConfig.YamlPath)
+	if err != nil {
+		log.Fatalf("Failed to read the Knative service specification %s - %v", knativeConfig.YamlPath, err)
+	}
+
 	queue := make(chan struct{}, runtime.NumCPU()) // message queue as a sync method
 	deployed := sync.WaitGroup{}
 	deployed.Add(len(cfg.Functions))
@@ -59,31 +124,49 @@ func (*knativeDeployer) Deploy(cfg *config.Configuration) {
 			defer deployed.Done()
 			defer func() { <-queue }()
 
-			knativeDeploySingleFunction(
+			err := kd.deploySingleFunction(
 				cfg.Functions[i],
-				knativeConfig.YamlPath,
+				string(specification),
 				knativeConfig.IsPartiallyPanic,
 				knativeConfig.EndpointPort,
 				knativeConfig.AutoscalingMetric,
 			)
+			if err != nil {
+				log.Warnf("Failed to deploy function %s - %v", cfg.Functions[i].Name, err)
+			}
 		}()
 	}
 
 	deployed.Wait()
 }
 
-func (*knativeDeployer) Clean() {
-	cmd := exec.Command("kn", "service", "delete", "--all")
+func (kd *knativeDeployer) Clean() {
+	if err := kd.connect(); err != nil {
+		log.Errorf("Unable to delete Knative services - %v", err)
+		return
+	}
+
+	if len(kd.namespaces) == 0 {
+		kd.namespaces[namespace] = struct{}{}
+	}
 
-	var out bytes.Buffer
-	cmd.Stdout = &out
+	for ns := range kd.namespaces {
+		services, err := kd.client.Resource(knativeServiceResource).Namespace(ns).List(context.Background(), metav1.ListOptions{})
+		if err != nil {
+			log.Errorf("Unable to list Knative services in namespace %s - %v", ns, err)
+			continue
+		}
 
-	if err := cmd.Run(); err != nil {
-		log.Errorf("Unable to delete Knative services - %s", err)
+		for _, service := range services.Items {
+			err = kd.client.Resource(knativeServiceResource).Namespace(ns).Delete(context.Background(), service.GetName(), metav1.DeleteOptions{})
+			if err != nil && !apierrors.IsNotFound(err) {
+				log.Errorf("Unable to delete Knative service %s - %v", service.GetName(), err)
+			}
+		}
 	}
 }
 
-func knativeDeploySingleFunction(function *common.Function, yamlPath string, isPartiallyPanic bool, endpointPort int, autoscalingMetric string) bool {
+func (kd *knativeDeployer) deploySingleFunction(function *common.Function, specification string, isPartiallyPanic bool, endpointPort int, autoscalingMetric string) error {
 	panicWindow := "\"10.0\""
 	panicThreshold := "\"200.0\""
 	if isPartiallyPanic {
@@ -97,44 +180,157 @@ func knativeDeploySingleFunction(function *common.Function, yamlPath string, isP
 		// second, then round to an integer as that is what the knative config expects
 	}
 
-	cmd := exec.Command(
-		"bash",
-		"./pkg/driver/deployment/knative.sh",
-		yamlPath,
-		function.Name,
+	service, err := composeKnativeService(specification, map[string]string{
+		"FUNC_NAME": function.Name,
+
+		"CPU_REQUEST":     strconv.Itoa(function.CPURequestsMilli) + "m",
+		"CPU_LIMITS":      strconv.Itoa(function.CPULimitsMilli) + "m",
+		"MEMORY_REQUESTS": strconv.Itoa(function.MemoryRequestsMiB) + "Mi",
+		"PANIC_WINDOW":    panicWindow,
+		"PANIC_THRESHOLD": panicThreshold,
+
+		"AUTOSCALING_METRIC": wrapString(autoscalingMetric),
+		"AUTOSCALING_TARGET": wrapString(strconv.Itoa(autoscalingTarget)),
+
+		"COLD_START_BUSY_LOOP_MS": wrapString(strconv.Itoa(function.ColdStartBusyLoopMs)),
+	})
+	if err != nil {
+		return err
+	}
+
+	service.SetName(function.Name)
+	if service.GetNamespace() == "" {
+		service.SetNamespace(namespace)
+	}
 
-		strconv.Itoa(function.CPURequestsMilli)+"m",
-		strconv.Itoa(function.CPULimitsMilli)+"m",
-		strconv.Itoa(function.MemoryRequestsMiB)+"Mi",
-		strconv.Itoa(function.InitialScale),
-		panicWindow,
-		panicThreshold,
+	// the annotations kn service apply used to set through --scale-init and --concurrency-target, overriding the YAML
+	annotations := map[string]string{
+		"autoscaling.knative.dev/initial-scale": strconv.Itoa(function.InitialScale),
+		"autoscaling.knative.dev/target":        "1",
+	}
+	for annotation, value := range annotations {
+		err = unstructured.SetNestedField(service.Object, value, "spec", "template", "metadata", "annotations", annotation)
+		if err != nil {
+			return fmt.Errorf("failed to set annotation %s - %v", annotation, err)
+		}
+	}
 
-		wrapString(autoscalingMetric),
-		wrapString(strconv.Itoa(autoscalingTarget)),
+	kd.mutex.Lock()
+	kd.namespaces[service.GetNamespace()] = struct{}{}
+	kd.mutex.Unlock()
 
-		wrapString(strconv.Itoa(function.ColdStartBusyLoopMs)),
-	)
+	if err = kd.apply(service); err != nil {
+		return err
+	}
 
-	stdoutStderr, err := cmd.CombinedOutput()
-	log.Debug("CMD response: ", string(stdoutStderr))
+	serviceURL, err := kd.waitForReady(service.GetNamespace(), service.GetName())
 	if err != nil {
-		// TODO: there should be a toggle to turn off deployment because if this is fatal then we cannot test the thing locally
-		log.Warnf("Failed to deploy function %s: %v\n%s\n", function.Name, err, stdoutStderr)
-		return false
+		return err
 	}
-	if endpoint := urlRegex.FindStringSubmatch(string(stdoutStderr))[1]; endpoint != function.Endpoint {
-		// TODO: check when this situation happens
-		log.Debugf("Update function endpoint to %s\n", endpoint)
-		function.Endpoint = endpoint
+
+	if parsed, err := url.Parse(serviceURL); err == nil && parsed.Host != "" {
+		log.Debugf("Update function endpoint to %s\n", parsed.Host)
+		function.Endpoint = parsed.Host
 	} else {
-		function.Endpoint = fmt.Sprintf("%s.%s.%s", function.Name, namespace, bareMetalLbGateway)
+		function.Endpoint = fmt.Sprintf("%s.%s.%s", function.Name, service.GetNamespace(), bareMetalLbGateway)
 	}
 	// adding port to the endpoint
 	function.Endpoint = fmt.Sprintf("%s:%d", function.Endpoint, endpointPort)
 	log.Debugf("Deployed function on %s\n", function.Endpoint)
 
-	return true
+	return nil
+}
+
+// composeKnativeService substitutes the given variables, and the environment variables otherwise, into the YAML
+// specification of the service
+func composeKnativeService(specification string, variables map[string]string) (*unstructured.Unstructured, error) {
+	expanded := os.Expand(specification, func(key string) string {
+		if value, ok := variables[key]; ok {
+			return value
+		}
+
+		return os.Getenv(key)
+	})
+
+	service := &unstructured.Unstructured{}
+	if err := yaml.Unmarshal([]byte(expanded), &service.Object); err != nil {
+		return nil, fmt.Errorf("invalid Knative service specification - %v", err)
+	}
+	if gvk := service.GroupVersionKind(); gvk.Group != knativeServiceResource.Group || gvk.Kind != "Service" {
+		return nil, fmt.Errorf("expected a %s service rather than %s", knativeServiceResource.GroupVersion(), gvk)
+	}
+
+	return service, nil
+}
+
+// apply creates the service, or updates it if it exists already
+func (kd *knativeDeployer) apply(service *unstructured.Unstructured) error {
+	services := kd.client.Resource(knativeServiceResource).Namespace(service.GetNamespace())
+
+	existing, err := services.Get(context.Background(), service.GetName(), metav1.GetOptions{})
+	switch {
+	case apierrors.IsNotFound(err):
+		_, err = services.Create(context.Background(), service, metav1.CreateOptions{})
+	case err == nil:
+		service.SetResourceVersion(existing.GetResourceVersion())
+		_, err = services.Update(context.Background(), service, metav1.UpdateOptions{})
+	}
+	if err != nil {
+		return fmt.Errorf("failed to apply Knative service %s - %v", service.GetName(), err)
+	}
+
+	return nil
+}
+
+// waitForReady waits until the latest generation of the service has become ready and returns its URL. A service
+// failing to become ready, e.g., because its revision cannot be created, is reported right away.
+func (kd *knativeDeployer) waitForReady(ns string, name string) (string, error) {
+	services := kd.client.Resource(knativeServiceResource).Namespace(ns)
+
+	var serviceURL string
+	var lastErr error
+
+	err := wait.PollUntilContextTimeout(context.Background(), knativeReadyPollInterval, knativeReadyTimeout, true, func(ctx context.Context) (bool, error) {
+		service, err := services.Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			lastErr = err
+			return false, nil
+		}
+
+		observedGeneration, _, _ := unstructured.NestedInt64(service.Object, "status", "observedGeneration")
+		if observedGeneration < service.GetGeneration() {
+			lastErr = fmt.Errorf("generation %d has not been observed yet", service.GetGeneration())
+			return false, nil
+		}
+
+		conditions, _, _ := unstructured.NestedSlice(service.Object, "status", "conditions")
+		for _, condition := range conditions {
+			condition, ok := condition.(map[string]interface{})
+			if !ok || condition["type"] != "Ready" {
+				continue
+			}
+
+			switch condition["status"] {
+			case "True":
+				serviceURL, _, _ = unstructured.NestedString(service.Object, "status", "url")
+				return true, nil
+			case "False":
+				return false, fmt.Errorf("%v - %v", condition["reason"], condition["message"])
+			}
+		}
+
+		lastErr = fmt.Errorf("not ready yet")
+		return false, nil
+	})
+	if err != nil {
+		if wait.Interrupted(err) && lastErr != nil {
+			err = lastErr
+		}
+
+		return "", fmt.Errorf("service %s has not become ready - %v", name, err)
+	}
+
+	return serviceURL, nil
 }
 
 func wrapString(value string) string {
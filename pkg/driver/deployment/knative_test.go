@@ -0,0 +1,225 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2023 EASL and the vHive community
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package deployment
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vhive-serverless/loader/pkg/common"
+	"github.com/vhive-serverless/loader/pkg/config"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	k8sruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// newFakeKnativeDeployer returns a deployer whose services are given the ready condition of the given status upon
+// creation and update, as Knative would set it once the revision has been rolled out or has failed
+func newFakeKnativeDeployer(readyStatus string) (*knativeDeployer, *dynamicfake.FakeDynamicClient) {
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(k8sruntime.NewScheme(),
+		map[schema.GroupVersionResource]string{knativeServiceResource: "ServiceList"})
+
+	client.PrependReactor("*", "services", func(action k8stesting.Action) (bool, k8sruntime.Object, error) {
+		objectAction, ok := action.(interface{ GetObject() k8sruntime.Object })
+		if !ok {
+			return false, nil, nil
+		}
+
+		service := objectAction.GetObject().(*unstructured.Unstructured)
+		service.Object["status"] = map[string]interface{}{
+			"url": "http://" + service.GetName() + "." + service.GetNamespace() + ".example.com",
+			"conditions": []interface{}{
+				map[string]interface{}{"type": "Ready", "status": readyStatus, "reason": "RevisionFailed", "message": "image not found"},
+			},
+		}
+
+		return false, nil, nil
+	})
+
+	deployer := newKnativeDeployer()
+	deployer.client = client
+
+	return deployer, client
+}
+
+func createKnativeConfiguration(functions ...*common.Function) *config.Configuration {
+	return &config.Configuration{
+		LoaderConfiguration: &config.LoaderConfiguration{
+			Platform:          "Knative",
+			EndpointPort:      80,
+			AutoscalingMetric: "concurrency",
+		},
+		Functions: functions,
+		YAMLPath:  "../../../workloads/container/trace_func_go.yaml",
+	}
+}
+
+func TestKnativeDeploy(t *testing.T) {
+	deployer, client := newFakeKnativeDeployer("True")
+
+	function := &common.Function{
+		Name:                "test-function",
+		CPURequestsMilli:    250,
+		CPULimitsMilli:      1000,
+		MemoryRequestsMiB:   128,
+		InitialScale:        2,
+		ColdStartBusyLoopMs: 10,
+	}
+	deployer.Deploy(createKnativeConfiguration(function))
+
+	if function.Endpoint != "test-function.default.example.com:80" {
+		t.Errorf("Unexpected endpoint %s.", function.Endpoint)
+	}
+
+	service, err := client.Resource(knativeServiceResource).Namespace("default").Get(context.Background(), function.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	annotations, _, _ := unstructured.NestedStringMap(service.Object, "spec", "template", "metadata", "annotations")
+	expectedAnnotations := map[string]string{
+		"autoscaling.knative.dev/initial-scale":              "2",
+		"autoscaling.knative.dev/panic-window-percentage":    "10.0",
+		"autoscaling.knative.dev/panic-threshold-percentage": "200.0",
+		"autoscaling.knative.dev/metric":                     "concurrency",
+		"autoscaling.knative.dev/target":                     "1",
+	}
+	for key, value := range expectedAnnotations {
+		if annotations[key] != value {
+			t.Errorf("Unexpected annotation %s - got %s, expected %s.", key, annotations[key], value)
+		}
+	}
+
+	containers, _, _ := unstructured.NestedSlice(service.Object, "spec", "template", "spec", "containers")
+	if len(containers) != 1 {
+		t.Fatalf("Unexpected number of containers %d.", len(containers))
+	}
+	resources, _, _ := unstructured.NestedMap(containers[0].(map[string]interface{}), "resources")
+	cpuRequests, _, _ := unstructured.NestedString(resources, "requests", "cpu")
+	cpuLimits, _, _ := unstructured.NestedString(resources, "limits", "cpu")
+	memoryRequests, _, _ := unstructured.NestedString(resources, "requests", "memory")
+	if cpuRequests != "250m" || cpuLimits != "1000m" || memoryRequests != "128Mi" {
+		t.Errorf("Unexpected resources %v.", resources)
+	}
+
+	// redeploying updates the existing service
+	function.InitialScale = 0
+	deployer.Deploy(createKnativeConfiguration(function))
+
+	service, _ = client.Resource(knativeServiceResource).Namespace("default").Get(context.Background(), function.Name, metav1.GetOptions{})
+	if scale, _, _ := unstructured.NestedString(service.Object, "spec", "template", "metadata", "annotations", "autoscaling.knative.dev/initial-scale"); scale != "0" {
+		t.Errorf("Service has not been updated - initial scale is %s.", scale)
+	}
+
+	deployer.Clean()
+
+	services, err := client.Resource(knativeServiceResource).Namespace("default").List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(services.Items) != 0 {
+		t.Errorf("%d services remain after cleanup.", len(services.Items))
+	}
+}
+
+func TestKnativeDeployAnnotations(t *testing.T) {
+	deployer, client := newFakeKnativeDeployer("True")
+
+	specification, err := os.ReadFile(createKnativeConfiguration().YAMLPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	function := &common.Function{
+		Name:         "test-function",
+		InitialScale: 3,
+		RuntimeStats: &common.FunctionRuntimeStats{Average: 50},
+	}
+	if err = deployer.deploySingleFunction(function, string(specification), true, 80, "rps"); err != nil {
+		t.Fatal(err)
+	}
+
+	service, err := client.Resource(knativeServiceResource).Namespace("default").Get(context.Background(), function.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the target of the YAML is overridden as kn service apply --concurrency-target 1 did
+	annotations, _, _ := unstructured.NestedStringMap(service.Object, "spec", "template", "metadata", "annotations")
+	expectedAnnotations := map[string]string{
+		"autoscaling.knative.dev/initial-scale":              "3",
+		"autoscaling.knative.dev/panic-window-percentage":    "100.0",
+		"autoscaling.knative.dev/panic-threshold-percentage": "1000.0",
+		"autoscaling.knative.dev/metric":                     "rps",
+		"autoscaling.knative.dev/target":                     "1",
+	}
+	for key, value := range expectedAnnotations {
+		if annotations[key] != value {
+			t.Errorf("Unexpected annotation %s - got %s, expected %s.", key, annotations[key], value)
+		}
+	}
+}
+
+func TestKnativeDeployFailure(t *testing.T) {
+	deployer, _ := newFakeKnativeDeployer("False")
+
+	specification, err := os.ReadFile(createKnativeConfiguration().YAMLPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	function := &common.Function{Name: "failing-function"}
+	err = deployer.deploySingleFunction(function, string(specification), false, 80, "concurrency")
+	if err == nil || !strings.Contains(err.Error(), "RevisionFailed") {
+		t.Errorf("Expected the failed revision to be reported, got %v.", err)
+	}
+	if function.Endpoint != "" {
+		t.Errorf("Endpoint of a function that failed to deploy has been set to %s.", function.Endpoint)
+	}
+}
+
+func TestKnativeDeployTimeout(t *testing.T) {
+	deployer, _ := newFakeKnativeDeployer("Unknown")
+
+	timeout, interval := knativeReadyTimeout, knativeReadyPollInterval
+	knativeReadyTimeout, knativeReadyPollInterval = 50*time.Millisecond, 10*time.Millisecond
+	defer func() { knativeReadyTimeout, knativeReadyPollInterval = timeout, interval }()
+
+	specification, err := os.ReadFile(createKnativeConfiguration().YAMLPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = deployer.deploySingleFunction(&common.Function{Name: "pending-function"}, string(specification), false, 80, "concurrency")
+	if err == nil || !strings.Contains(err.Error(), "not ready yet") {
+		t.Errorf("Expected the deployment to time out, got %v.", err)
+	}
+}
@@ -0,0 +1,325 @@
+package deployment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/vhive-serverless/loader/pkg/common"
+	"github.com/vhive-serverless/loader/pkg/config"
+	"github.com/vhive-serverless/loader/pkg/workload/proto"
+	"github.com/vhive-serverless/loader/pkg/workload/standard"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+var errLocalFunctionStopped = errors.New("function has been removed")
+
+// localDeployer emulates a FaaS platform within the loader process. Every function gets a gateway listening on a
+// loopback port, which routes the invocations to instances of the standard trace function that are started on demand,
+// each of them on its own loopback port.
+type localDeployer struct {
+	functions []*localFunction
+}
+
+type localDeploymentConfiguration struct {
+	UseGRPC              bool
+	ColdStartDelay       time.Duration
+	ContainerConcurrency int
+	ScaleToZeroAfter     time.Duration
+}
+
+func newLocalDeployer() *localDeployer {
+	return &localDeployer{}
+}
+
+func newLocalDeployerConfiguration(cfg *config.Configuration) localDeploymentConfiguration {
+	return localDeploymentConfiguration{
+		UseGRPC:              cfg.LoaderConfiguration.InvokeProtocol == "grpc",
+		ColdStartDelay:       time.Duration(cfg.LoaderConfiguration.LocalColdStartDelayMs) * time.Millisecond,
+		ContainerConcurrency: cfg.LoaderConfiguration.LocalContainerConcurrency,
+		ScaleToZeroAfter:     time.Duration(cfg.LoaderConfiguration.LocalScaleToZeroAfterMs) * time.Millisecond,
+	}
+}
+
+func (ld *localDeployer) Deploy(cfg *config.Configuration) {
+	localConfig := newLocalDeployerConfiguration(cfg)
+
+	for _, function := range cfg.Functions {
+		localFunction, err := startLocalFunction(function.Name, localConfig)
+		if err != nil {
+			log.Fatalf("Failed to start the local gateway of function %s - %v", function.Name, err)
+		}
+
+		function.Endpoint = localFunction.endpoint
+		ld.functions = append(ld.functions, localFunction)
+
+		log.Debugf("Deployed function %s locally on %s", function.Name, function.Endpoint)
+	}
+}
+
+func (ld *localDeployer) Clean() {
+	for _, localFunction := range ld.functions {
+		localFunction.stop()
+	}
+
+	ld.functions = nil
+}
+
+type localInstance struct {
+	name string
+	// ready is closed once the cold start of the instance is over
+	ready chan struct{}
+	err   error
+
+	inFlight  int
+	idleSince time.Time
+
+	grpcServer *grpc.Server
+	conn       *grpc.ClientConn
+
+	httpServer *http.Server
+	proxy      *httputil.ReverseProxy
+}
+
+func (i *localInstance) stop() {
+	if i.conn != nil {
+		if err := i.conn.Close(); err != nil {
+			log.Warnf("Error while closing the connection to instance %s - %v", i.name, err)
+		}
+	}
+	if i.grpcServer != nil {
+		i.grpcServer.Stop()
+	}
+	if i.httpServer != nil {
+		if err := i.httpServer.Close(); err != nil {
+			log.Warnf("Error while stopping instance %s - %v", i.name, err)
+		}
+	}
+}
+
+type localFunction struct {
+	proto.UnimplementedExecutorServer
+
+	name     string
+	endpoint string
+	cfg      localDeploymentConfiguration
+
+	grpcServer *grpc.Server
+	httpServer *http.Server
+
+	instances     []*localInstance
+	instanceCount int
+	stopped       bool
+	mutex         sync.Mutex
+}
+
+func startLocalFunction(name string, cfg localDeploymentConfiguration) (*localFunction, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+
+	f := &localFunction{
+		name:     name,
+		endpoint: listener.Addr().String(),
+		cfg:      cfg,
+	}
+
+	if cfg.UseGRPC {
+		f.grpcServer = grpc.NewServer()
+		proto.RegisterExecutorServer(f.grpcServer, f)
+
+		go serveLocal(f.name, func() error { return f.grpcServer.Serve(listener) })
+	} else {
+		f.httpServer = &http.Server{Handler: h2c.NewHandler(http.HandlerFunc(f.ServeHTTP), &http2.Server{})}
+
+		go serveLocal(f.name, func() error { return f.httpServer.Serve(listener) })
+	}
+
+	return f, nil
+}
+
+func serveLocal(name string, serve func() error) {
+	if err := serve(); err != nil && !errors.Is(err, http.ErrServerClosed) && !errors.Is(err, grpc.ErrServerStopped) {
+		log.Errorf("Local server of %s has failed - %v", name, err)
+	}
+}
+
+// Execute routes a gRPC invocation to an instance of the function
+func (f *localFunction) Execute(ctx context.Context, req *proto.FaasRequest) (*proto.FaasReply, error) {
+	instance, err := f.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer f.release(instance)
+
+	reply, err := proto.NewExecutorClient(instance.conn).Execute(ctx, req)
+	if err == nil && reply.Message == "" {
+		reply.Message = instance.name
+	}
+
+	return reply, err
+}
+
+// ServeHTTP routes an HTTP invocation to an instance of the function
+func (f *localFunction) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	instance, err := f.acquire(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	defer f.release(instance)
+
+	instance.proxy.ServeHTTP(w, r)
+}
+
+// acquire returns an instance with a free slot, scaling the function out if there is none, and waits for the cold
+// start of the instance to be over
+func (f *localFunction) acquire(ctx context.Context) (*localInstance, error) {
+	f.mutex.Lock()
+
+	var instance *localInstance
+	for _, candidate := range f.instances {
+		if f.cfg.ContainerConcurrency <= 0 || candidate.inFlight < f.cfg.ContainerConcurrency {
+			instance = candidate
+			break
+		}
+	}
+
+	if instance == nil {
+		if f.stopped {
+			f.mutex.Unlock()
+			return nil, errLocalFunctionStopped
+		}
+
+		f.instanceCount++
+		instance = &localInstance{
+			name:  fmt.Sprintf("%s-%05d", f.name, f.instanceCount),
+			ready: make(chan struct{}),
+		}
+		f.instances = append(f.instances, instance)
+
+		go f.startInstance(instance)
+	}
+
+	instance.inFlight++
+	f.mutex.Unlock()
+
+	select {
+	case <-instance.ready:
+		if instance.err != nil {
+			f.release(instance)
+			return nil, instance.err
+		}
+
+		return instance, nil
+	case <-ctx.Done():
+		f.release(instance)
+		return nil, ctx.Err()
+	}
+}
+
+func (f *localFunction) release(instance *localInstance) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	instance.inFlight--
+	if instance.inFlight == 0 && f.cfg.ScaleToZeroAfter > 0 {
+		instance.idleSince = time.Now()
+		time.AfterFunc(f.cfg.ScaleToZeroAfter, func() { f.retire(instance) })
+	}
+}
+
+// retire removes the instance if it has not been used since it became idle
+func (f *localFunction) retire(instance *localInstance) {
+	f.mutex.Lock()
+
+	if instance.inFlight > 0 || time.Since(instance.idleSince) < f.cfg.ScaleToZeroAfter || !f.remove(instance) {
+		f.mutex.Unlock()
+		return
+	}
+
+	f.mutex.Unlock()
+
+	log.Debugf("Scaling down instance %s", instance.name)
+	<-instance.ready
+	instance.stop()
+}
+
+// remove has to be called with the mutex held and reports whether the instance was still part of the function
+func (f *localFunction) remove(instance *localInstance) bool {
+	for i, candidate := range f.instances {
+		if candidate == instance {
+			f.instances = append(f.instances[:i], f.instances[i+1:]...)
+			return true
+		}
+	}
+
+	return false
+}
+
+func (f *localFunction) startInstance(instance *localInstance) {
+	start := time.Now()
+	defer close(instance.ready)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		log.Errorf("Failed to start instance %s - %v", instance.name, err)
+
+		f.mutex.Lock()
+		instance.err = err
+		f.remove(instance)
+		f.mutex.Unlock()
+
+		return
+	}
+
+	if f.cfg.UseGRPC {
+		instance.grpcServer = standard.NewGRPCServer(standard.TraceFunction)
+		go serveLocal(instance.name, func() error { return instance.grpcServer.Serve(listener) })
+
+		instance.conn, err = grpc.NewClient(listener.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+		common.Check(err)
+	} else {
+		instance.httpServer = &http.Server{Handler: standard.NewHTTPHandler(standard.TraceFunction, instance.name)}
+		go serveLocal(instance.name, func() error { return instance.httpServer.Serve(listener) })
+
+		instance.proxy = httputil.NewSingleHostReverseProxy(&url.URL{Scheme: "http", Host: listener.Addr().String()})
+	}
+
+	time.Sleep(f.cfg.ColdStartDelay - time.Since(start))
+
+	log.Debugf("Instance %s has started in %v", instance.name, time.Since(start))
+}
+
+func (f *localFunction) stop() {
+	f.mutex.Lock()
+	f.stopped = true
+	instances := f.instances
+	f.instances = nil
+	f.mutex.Unlock()
+
+	if f.grpcServer != nil {
+		f.grpcServer.Stop()
+	}
+	if f.httpServer != nil {
+		if err := f.httpServer.Close(); err != nil {
+			log.Warnf("Error while stopping the local gateway of function %s - %v", f.name, err)
+		}
+	}
+
+	for _, instance := range instances {
+		<-instance.ready
+		instance.stop()
+	}
+}
@@ -0,0 +1,122 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2023 EASL and the vHive community
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package deployment
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/vhive-serverless/loader/pkg/common"
+	"github.com/vhive-serverless/loader/pkg/config"
+	"github.com/vhive-serverless/loader/pkg/driver/clients"
+	"github.com/vhive-serverless/loader/pkg/metric"
+)
+
+func createLocalConfiguration(invokeProtocol string, concurrency int, scaleToZeroAfterMs int) *config.Configuration {
+	return &config.Configuration{
+		LoaderConfiguration: &config.LoaderConfiguration{
+			Platform:                     "Local",
+			InvokeProtocol:               invokeProtocol,
+			GRPCConnectionTimeoutSeconds: 5,
+			GRPCFunctionTimeoutSeconds:   15,
+			LocalColdStartDelayMs:        200,
+			LocalContainerConcurrency:    concurrency,
+			LocalScaleToZeroAfterMs:      scaleToZeroAfterMs,
+		},
+		Functions: []*common.Function{{Name: "trace-func-0"}},
+	}
+}
+
+func TestLocalPlatform(t *testing.T) {
+	for _, invokeProtocol := range []string{"grpc", "http1", "http2"} {
+		t.Run(invokeProtocol, func(t *testing.T) {
+			cfg := createLocalConfiguration(invokeProtocol, 1, 0)
+
+			deployer := CreateDeployer(cfg)
+			deployer.Deploy(cfg)
+			defer deployer.Clean()
+
+			invoker := clients.CreateInvoker(cfg.LoaderConfiguration, nil, nil)
+			runtimeSpec := &common.RuntimeSpecification{Runtime: 10, Memory: 128}
+
+			success, record := invoker.Invoke(context.Background(), cfg.Functions[0], runtimeSpec)
+			if !success || record.Instance != "trace-func-0-00001" {
+				t.Fatalf("Invocation has failed or has been served by an unexpected instance %q.", record.Instance)
+			}
+			if record.ResponseTime < 200_000 {
+				t.Errorf("Cold start delay is missing from the response time of %d μs.", record.ResponseTime)
+			}
+			if record.StartType != metric.Cold || record.InitTime < 200_000 || record.InitTime > record.ResponseTime {
+				t.Errorf("First invocation has not been classified as a cold start - %q, %d μs.", record.StartType, record.InitTime)
+			}
+
+			success, record = invoker.Invoke(context.Background(), cfg.Functions[0], runtimeSpec)
+			if !success || record.Instance != "trace-func-0-00001" || record.ResponseTime >= 200_000 {
+				t.Errorf("Warm invocation should have been served by the first instance - %q, %d μs.", record.Instance, record.ResponseTime)
+			}
+			if record.StartType != metric.Hot || record.InitTime != 0 {
+				t.Errorf("Second invocation has not been classified as a warm start - %q, %d μs.", record.StartType, record.InitTime)
+			}
+		})
+	}
+}
+
+func TestLocalPlatformScaling(t *testing.T) {
+	cfg := createLocalConfiguration("grpc", 1, 300)
+
+	deployer := CreateDeployer(cfg)
+	deployer.Deploy(cfg)
+	defer deployer.Clean()
+
+	invoker := clients.CreateInvoker(cfg.LoaderConfiguration, nil, nil)
+	runtimeSpec := &common.RuntimeSpecification{Runtime: 100, Memory: 128}
+
+	// two concurrent invocations do not fit into a single instance
+	wg := sync.WaitGroup{}
+	records := make([]*metric.ExecutionRecord, 2)
+	for i := range records {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+			_, records[i] = invoker.Invoke(context.Background(), cfg.Functions[0], runtimeSpec)
+		}()
+	}
+	wg.Wait()
+
+	if records[0].Instance == records[1].Instance {
+		t.Errorf("Concurrent invocations have been served by the same instance %s.", records[0].Instance)
+	}
+
+	// both instances are scaled down once idle, hence the next invocation is a cold start
+	time.Sleep(600 * time.Millisecond)
+
+	success, record := invoker.Invoke(context.Background(), cfg.Functions[0], runtimeSpec)
+	if !success || record.Instance != "trace-func-0-00003" {
+		t.Errorf("Invocation after scale to zero has been served by instance %q.", record.Instance)
+	}
+}
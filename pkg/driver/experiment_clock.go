@@ -0,0 +1,218 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2023 EASL and the vHive community
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package driver
+
+import (
+	"runtime"
+	"sync"
+	"time"
+)
+
+// experimentClock maps wall-clock time to trace time, i.e., the time IATs are expressed in. Trace time passes faster or
+// slower than wall-clock time according to the rate multiplier and stands still while the load is paused.
+type experimentClock struct {
+	startOnce sync.Once
+	mutex     sync.RWMutex
+
+	// wall-clock and trace time (in microseconds) of the last change of the rate or the pause state
+	realAnchor  time.Time
+	traceAnchor int64
+
+	rate    float64
+	paused  bool
+	altered bool
+
+	// changed is closed and replaced whenever the rate or the pause state changes
+	changed chan struct{}
+}
+
+func newExperimentClock() *experimentClock {
+	return &experimentClock{
+		rate:    1,
+		changed: make(chan struct{}),
+	}
+}
+
+// start sets the beginning of the experiment, which is shared by all the function drivers
+func (c *experimentClock) start() {
+	c.startOnce.Do(func() {
+		c.mutex.Lock()
+		defer c.mutex.Unlock()
+
+		c.realAnchor = time.Now()
+	})
+}
+
+// resumeAt makes the experiment start at the given trace time instead of the beginning of the trace
+func (c *experimentClock) resumeAt(traceTime int64) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.traceAnchor = traceTime
+}
+
+func (c *experimentClock) traceTimeAt(t time.Time) int64 {
+	if c.paused {
+		return c.traceAnchor
+	}
+
+	return c.traceAnchor + int64(float64(t.Sub(c.realAnchor).Microseconds())*c.rate)
+}
+
+// now returns the trace time in microseconds since the beginning of the experiment
+func (c *experimentClock) now() int64 {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	return c.traceTimeAt(time.Now())
+}
+
+// until returns the wall-clock time left until the given trace time is reached, whether the clock is paused and a
+// channel that gets closed once the estimate becomes stale
+func (c *experimentClock) until(traceTime int64) (time.Duration, bool, <-chan struct{}) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	if c.paused {
+		return 0, true, c.changed
+	}
+
+	remaining := float64(traceTime-c.traceTimeAt(time.Now())) / c.rate
+
+	return time.Duration(remaining) * time.Microsecond, false, c.changed
+}
+
+// wallClockTime returns the wall-clock time at which the given trace time is reached if the rate does not change in
+// the meantime, or the current time if the clock is paused
+func (c *experimentClock) wallClockTime(traceTime int64) time.Time {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	if c.paused {
+		return time.Now()
+	}
+
+	return c.realAnchor.Add(time.Duration(float64(traceTime-c.traceAnchor)/c.rate) * time.Microsecond)
+}
+
+// toWallClock converts a duration in trace time to wall-clock time
+func (c *experimentClock) toWallClock(duration time.Duration) time.Duration {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	return time.Duration(float64(duration) / c.rate)
+}
+
+func (c *experimentClock) update(modify func()) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	now := time.Now()
+	c.traceAnchor, c.realAnchor = c.traceTimeAt(now), now
+	modify()
+	c.altered = true
+
+	close(c.changed)
+	c.changed = make(chan struct{})
+}
+
+func (c *experimentClock) setRate(rate float64) {
+	c.update(func() { c.rate = rate })
+}
+
+func (c *experimentClock) setPaused(paused bool) {
+	c.update(func() { c.paused = paused })
+}
+
+func (c *experimentClock) state() (rate float64, paused bool, altered bool) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	return c.rate, c.paused, c.altered
+}
+
+// waitForTraceTime blocks until the experiment clock reaches the given trace time and returns false if the experiment
+// has been aborted in the meantime
+func (d *Driver) waitForTraceTime(traceTime int64) bool {
+	for {
+		remaining, paused, changed := d.clock.until(traceTime)
+		if !paused && remaining <= 0 {
+			return !d.isAborted()
+		}
+
+		if paused {
+			select {
+			case <-changed:
+				continue
+			case <-d.abort:
+				return false
+			}
+		}
+
+		timer := time.NewTimer(remaining)
+
+		select {
+		case <-timer.C:
+		case <-changed:
+		case <-d.abort:
+			timer.Stop()
+			return false
+		}
+
+		timer.Stop()
+	}
+}
+
+// waitWhilePaused blocks until the load gets resumed and returns false if the experiment has been aborted in the meantime
+func (d *Driver) waitWhilePaused() bool {
+	return d.waitForTraceTime(d.clock.now())
+}
+
+// waitUntil blocks until the given trace time and returns false if the experiment has been aborted in the meantime.
+// Unlike waitForTraceTime, the last SchedulerSpinMicroseconds are busy-waited if set, as timer wake-ups are too coarse
+// for sub-millisecond accuracy, at the cost of keeping a core busy.
+func (d *Driver) waitUntil(traceTime int64) bool {
+	spin := int64(d.Configuration.LoaderConfiguration.SchedulerSpinMicroseconds)
+	if spin <= 0 {
+		return d.waitForTraceTime(traceTime)
+	}
+
+	if !d.waitForTraceTime(traceTime - spin) {
+		return false
+	}
+
+	for {
+		remaining, paused, _ := d.clock.until(traceTime)
+		if paused {
+			return d.waitForTraceTime(traceTime)
+		} else if remaining <= 0 {
+			return !d.isAborted()
+		} else if d.isAborted() {
+			return false
+		}
+
+		runtime.Gosched()
+	}
+}
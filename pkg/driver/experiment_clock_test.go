@@ -0,0 +1,118 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2023 EASL and the vHive community
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package driver
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestExperimentClock(t *testing.T) {
+	clock := newExperimentClock()
+	clock.start()
+
+	time.Sleep(100 * time.Millisecond)
+	if now := clock.now(); now < 100_000 || now > 200_000 {
+		t.Errorf("Unexpected trace time at rate 1 - %d μs.", now)
+	}
+
+	clock.setRate(2)
+	if remaining, _, _ := clock.until(clock.now() + 1_000_000); remaining > 500*time.Millisecond {
+		t.Errorf("Doubling the rate should halve the wall-clock time until the next invocation - got %v.", remaining)
+	}
+
+	clock.setPaused(true)
+	pausedAt := clock.now()
+	time.Sleep(50 * time.Millisecond)
+	if clock.now() != pausedAt {
+		t.Error("Trace time should not pass while the clock is paused.")
+	}
+	if _, paused, _ := clock.until(pausedAt + 1); !paused {
+		t.Error("Clock should report being paused.")
+	}
+
+	if _, _, altered := clock.state(); !altered {
+		t.Error("Clock should report that the load has been altered.")
+	}
+}
+
+func TestWaitForTraceTime(t *testing.T) {
+	driver := createTestDriver([]int{1})
+	driver.Configuration.LoaderConfiguration.OutputPathPrefix = filepath.Join(t.TempDir(), "test")
+	driver.clock.start()
+
+	driver.clock.setPaused(true)
+	go func() {
+		time.Sleep(200 * time.Millisecond)
+		driver.clock.setPaused(false)
+	}()
+
+	start := time.Now()
+	if !driver.waitForTraceTime(driver.clock.now() + 100_000) {
+		t.Fatal("Waiting should not be interrupted if the experiment has not been aborted.")
+	}
+	if elapsed := time.Since(start); elapsed < 300*time.Millisecond {
+		t.Errorf("Paused time should not count towards the trace time - waited for %v.", elapsed)
+	}
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		driver.abortExperiment("test")
+	}()
+
+	if driver.waitForTraceTime(driver.clock.now() + time.Minute.Microseconds()) {
+		t.Error("Waiting should be interrupted once the experiment is aborted.")
+	}
+}
+
+func TestWaitUntil(t *testing.T) {
+	// the longest spin covers the whole wait, so that the experiment is aborted while busy-waiting
+	for _, spin := range []int{0, 500, 2_000_000} {
+		t.Run(fmt.Sprintf("spin_%d", spin), func(t *testing.T) {
+			driver := createTestDriver([]int{1})
+			driver.Configuration.LoaderConfiguration.SchedulerSpinMicroseconds = spin
+			driver.clock.start()
+
+			dueAt := driver.clock.now() + 20_000
+			if !driver.waitUntil(dueAt) {
+				t.Fatal("Waiting should not be interrupted if the experiment has not been aborted.")
+			}
+			if now := driver.clock.now(); now < dueAt {
+				t.Errorf("Returned %d μs before the invocation is due.", dueAt-now)
+			}
+
+			go func() {
+				time.Sleep(50 * time.Millisecond)
+				driver.abortExperiment("test")
+			}()
+
+			if driver.waitUntil(driver.clock.now() + time.Second.Microseconds()) {
+				t.Error("Waiting should be interrupted once the experiment is aborted.")
+			}
+		})
+	}
+}
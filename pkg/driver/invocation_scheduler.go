@@ -0,0 +1,386 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2023 EASL and the vHive community
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package driver
+
+import (
+	"container/heap"
+	"container/list"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/vhive-serverless/loader/pkg/common"
+	mc "github.com/vhive-serverless/loader/pkg/metric"
+)
+
+// invocationCursor walks over the IAT array of a single function (or DAG) and keeps track of the minute the next
+// invocation belongs to, so that invocation IDs and phases do not depend on the scheduler in use.
+type invocationCursor struct {
+	template InvocationMetadata
+	iat      common.IATArray
+
+	iatIndex int
+	// issued Number of invocations handed out so far, which is read concurrently when writing a checkpoint
+	issued int64
+	// dueAt Time of the next invocation in microseconds since the beginning of the experiment
+	dueAt int64
+
+	minuteIndexSearch                   *common.IntervalSearch
+	minuteIndexEnd                      int
+	minuteIndex                         int
+	invocationSinceTheBeginningOfMinute int
+
+	phase common.ExperimentPhase
+
+	// tracked Whether the invocations are tracked until completion, which is needed for checkpoints
+	tracked bool
+	// pending Indices of the issued invocations that have not completed yet
+	pending map[int]struct{}
+	// reissue Indices of the invocations in flight at the checkpoint the experiment is resumed from, which are
+	// issued again before the remaining ones
+	reissue []int
+	// resumedDueAt Time of the next invocation in the IAT array, which is restored once all have been reissued
+	resumedDueAt int64
+	// mutex Guards issued and pending for checkpoints to observe a consistent state
+	mutex sync.Mutex
+}
+
+func (d *Driver) newInvocationCursor(template InvocationMetadata) *invocationCursor {
+	function := template.RootFunction.Front().Value.(*common.Node).Function
+
+	minuteIndexSearch := common.NewIntervalSearch(function.Specification.PerMinuteCount)
+	interval := minuteIndexSearch.SearchInterval(0)
+
+	cursor := &invocationCursor{
+		template: template,
+		iat:      function.Specification.IAT,
+
+		minuteIndexSearch: minuteIndexSearch,
+		minuteIndexEnd:    interval.End,
+		minuteIndex:       interval.Value,
+
+		phase: common.ExecutionPhase,
+
+		tracked: d.Configuration.LoaderConfiguration.EnableCheckpointing,
+		pending: make(map[int]struct{}),
+	}
+
+	if d.Configuration.WithWarmup() {
+		cursor.phase = common.WarmupPhase
+	}
+
+	if cursor.hasNext() {
+		cursor.dueAt = int64(cursor.iat[0])
+	}
+
+	if d.resumeFrom != nil {
+		cursor.skipTo(d.resumeFrom.IatIndex[function.Name])
+		cursor.reissueAt(d.resumeFrom.Pending[function.Name], d.Configuration.ScaledDuration(time.Duration(d.resumeFrom.Minute)*time.Minute).Microseconds())
+	}
+	d.registerCursor(cursor)
+
+	return cursor
+}
+
+func (c *invocationCursor) functionName() string {
+	return c.template.RootFunction.Front().Value.(*common.Node).Function.Name
+}
+
+func (c *invocationCursor) progress() int {
+	return int(atomic.LoadInt64(&c.issued))
+}
+
+// snapshot returns the progress of the cursor along with the sorted indices of the invocations in flight
+func (c *invocationCursor) snapshot() (int, []int) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	pending := make([]int, 0, len(c.pending))
+	for iatIndex := range c.pending {
+		pending = append(pending, iatIndex)
+	}
+	sort.Ints(pending)
+
+	return c.progress(), pending
+}
+
+// complete marks the invocation with the given index as completed
+func (c *invocationCursor) complete(iatIndex int) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	delete(c.pending, iatIndex)
+}
+
+// reissueAt makes the cursor issue the invocations with the given indices at the given time before moving on
+func (c *invocationCursor) reissueAt(iatIndices []int, dueAt int64) {
+	if len(iatIndices) == 0 {
+		return
+	}
+
+	c.reissue = append(c.reissue, iatIndices...)
+	c.resumedDueAt = c.dueAt
+	c.dueAt = dueAt
+}
+
+// track marks the invocation as in flight until all of its parts have completed
+func (c *invocationCursor) track(metadata *InvocationMetadata) {
+	if !c.tracked {
+		return
+	}
+
+	c.pending[metadata.IatIndex] = struct{}{}
+	metadata.tracked = &trackedInvocation{
+		cursor:   c,
+		iatIndex: metadata.IatIndex,
+		refs:     1,
+	}
+}
+
+// skipTo moves the cursor to the invocation with the given index, as if all the previous ones had been issued
+func (c *invocationCursor) skipTo(iatIndex int) {
+	for c.iatIndex < iatIndex && c.hasNext() {
+		c.iatIndex++
+
+		if c.hasNext() {
+			c.dueAt += int64(c.iat[c.iatIndex])
+		}
+	}
+
+	if interval := c.minuteIndexSearch.SearchInterval(c.iatIndex); interval != nil {
+		c.minuteIndexEnd, c.minuteIndex = interval.End, interval.Value
+		c.invocationSinceTheBeginningOfMinute = c.iatIndex - interval.Start
+	}
+
+	atomic.StoreInt64(&c.issued, int64(c.iatIndex))
+}
+
+func (c *invocationCursor) hasNext() bool {
+	return len(c.reissue) > 0 || c.iatIndex < len(c.iat)
+}
+
+// next returns the metadata of the invocation that is due and moves the cursor to the following one
+func (c *invocationCursor) next(granularity common.TraceGranularity) *InvocationMetadata {
+	if len(c.reissue) > 0 {
+		return c.nextReissued(granularity)
+	}
+
+	metadata := c.template
+	metadata.Phase = c.phase
+	metadata.InvocationID = composeInvocationID(granularity, c.minuteIndex, c.invocationSinceTheBeginningOfMinute)
+	metadata.IatIndex = c.iatIndex
+
+	c.mutex.Lock()
+	c.track(&metadata)
+	c.iatIndex++
+	atomic.StoreInt64(&c.issued, int64(c.iatIndex))
+	c.mutex.Unlock()
+
+	// counter updates
+	c.invocationSinceTheBeginningOfMinute++
+	if c.iatIndex > c.minuteIndexEnd {
+		interval := c.minuteIndexSearch.SearchInterval(c.iatIndex)
+		if interval != nil { // otherwise, there are no more invocations for this cursor
+			c.minuteIndexEnd, c.minuteIndex, c.invocationSinceTheBeginningOfMinute = interval.End, interval.Value, 0
+		}
+	}
+
+	if c.hasNext() {
+		c.dueAt += int64(c.iat[c.iatIndex])
+	}
+
+	return &metadata
+}
+
+// nextReissued returns the metadata of the next invocation to issue again after resuming the experiment
+func (c *invocationCursor) nextReissued(granularity common.TraceGranularity) *InvocationMetadata {
+	iatIndex := c.reissue[0]
+	c.reissue = c.reissue[1:]
+
+	metadata := c.template
+	metadata.Phase = c.phase
+	metadata.IatIndex = iatIndex
+	if interval := c.minuteIndexSearch.SearchInterval(iatIndex); interval != nil {
+		metadata.InvocationID = composeInvocationID(granularity, interval.Value, iatIndex-interval.Start)
+	}
+
+	c.mutex.Lock()
+	c.track(&metadata)
+	c.mutex.Unlock()
+
+	if len(c.reissue) == 0 {
+		c.dueAt = c.resumedDueAt
+	}
+
+	return &metadata
+}
+
+// invocationHeap is a min-heap of cursors ordered by the time their next invocation is due
+type invocationHeap []*invocationCursor
+
+func (h invocationHeap) Len() int {
+	return len(h)
+}
+
+func (h invocationHeap) Less(i, j int) bool {
+	return h[i].dueAt < h[j].dueAt
+}
+
+func (h invocationHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+}
+
+func (h *invocationHeap) Push(x any) {
+	*h = append(*h, x.(*invocationCursor))
+}
+
+func (h *invocationHeap) Pop() any {
+	old := *h
+	n := len(old)
+	item := old[n-1]
+	old[n-1] = nil
+	*h = old[:n-1]
+
+	return item
+}
+
+func (d *Driver) invocationWorker(workQueue <-chan *InvocationMetadata) {
+	for metadata := range workQueue {
+		d.runInvocation(metadata)
+	}
+}
+
+func (d *Driver) runInvocation(metadata *InvocationMetadata) {
+	if !d.Configuration.TestMode {
+		d.invokeFunction(metadata)
+	} else {
+		d.invokeFunctionInTestMode(metadata)
+	}
+}
+
+// invocationScheduler issues the invocations of all the functions from a single goroutine, replacing one
+// functionsDriver per function. Invocations are handed over to a bounded pool of workers once they are due, or run on
+// a goroutine of their own if all the workers are busy, so that slow invocations do not delay those of other functions.
+func (d *Driver) invocationScheduler(functionLinkedLists []*list.List, announceSchedulerDone *sync.WaitGroup, addInvocationsToGroup *sync.WaitGroup, totalSuccessful *int64, totalFailed *int64, totalIssued *int64, recordOutputChannel chan *mc.ExecutionRecord) {
+	defer announceSchedulerDone.Done()
+
+	waitForInvocations := sync.WaitGroup{}
+
+	cursors := make(invocationHeap, 0, len(functionLinkedLists))
+	for _, functionLinkedList := range functionLinkedLists {
+		function := functionLinkedList.Front().Value.(*common.Node).Function
+		invocationCount := len(function.Specification.IAT)
+		addInvocationsToGroup.Add(invocationCount)
+
+		if invocationCount == 0 {
+			log.Debugf("No invocations found for function %s.\n", function.Name)
+			continue
+		}
+
+		cursors = append(cursors, d.newInvocationCursor(InvocationMetadata{
+			RootFunction:        functionLinkedList,
+			SuccessCount:        totalSuccessful,
+			FailedCount:         totalFailed,
+			FunctionsInvoked:    totalIssued,
+			RecordOutputChannel: recordOutputChannel,
+			AnnounceDoneWG:      &waitForInvocations,
+			AnnounceDoneExe:     addInvocationsToGroup,
+		}))
+	}
+	heap.Init(&cursors)
+
+	workerPoolSize := d.Configuration.LoaderConfiguration.SchedulerWorkerPoolSize
+	if workerPoolSize <= 0 {
+		workerPoolSize = common.DefaultSchedulerWorkerPoolSize
+	}
+
+	// invocations are only handed over to idle workers rather than queued behind busy ones
+	workQueue := make(chan *InvocationMetadata)
+	for i := 0; i < workerPoolSize; i++ {
+		go d.invocationWorker(workQueue)
+	}
+
+	if d.Configuration.WithWarmup() {
+		log.Infof("Warmup phase has started.")
+	}
+
+	d.clock.start()
+	d.registerCounters(totalSuccessful, totalFailed, totalIssued)
+
+	overflowInvocations := 0
+	for cursors.Len() > 0 {
+		cursor := cursors[0]
+
+		d.announceWarmupEnd(cursor.minuteIndex, &cursor.phase)
+		if !d.waitUntil(cursor.dueAt) {
+			break
+		}
+		d.runtimeMonitor.invocationIssued()
+		intendedFireTime := d.clock.wallClockTime(cursor.dueAt).UnixMicro()
+		metadata := cursor.next(d.Configuration.TraceGranularity)
+		metadata.IntendedFireTime = intendedFireTime
+		if scheduledBy := metadata.RootFunction.Front().Value.(*common.Node).Function.Name; d.retryPolicy.skipScheduledInvocation(scheduledBy) {
+			log.Debugf("Skipping invocation with ID %s of function %s in favour of a retry.", metadata.InvocationID, scheduledBy)
+			d.releaseInvocation(metadata)
+		} else {
+			aborted := false
+			waitForInvocations.Add(1)
+
+			select {
+			case workQueue <- metadata:
+			case <-d.abort:
+				aborted = true
+			case <-d.invocationContext.Done():
+				aborted = true
+			default:
+				overflowInvocations++
+				go d.runInvocation(metadata)
+			}
+
+			if aborted {
+				waitForInvocations.Done()
+				d.releaseInvocation(metadata)
+				break
+			}
+		}
+
+		if cursor.hasNext() {
+			heap.Fix(&cursors, 0)
+		} else {
+			heap.Pop(&cursors)
+		}
+	}
+
+	close(workQueue)
+	waitForInvocations.Wait()
+
+	if overflowInvocations > 0 {
+		log.Warnf("%d invocations have been issued beyond the %d workers of the scheduler, as all of them were busy.", overflowInvocations, workerPoolSize)
+	}
+
+	log.Debugf("All the invocations have been completed.\n")
+}
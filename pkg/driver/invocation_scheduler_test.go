@@ -0,0 +1,164 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2023 EASL and the vHive community
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package driver
+
+import (
+	"container/list"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/vhive-serverless/loader/pkg/common"
+	"github.com/vhive-serverless/loader/pkg/metric"
+)
+
+func createFunctionLinkedList(name string, iat common.IATArray, perMinuteCount []int) *list.List {
+	functionLinkedList := list.New()
+	functionLinkedList.PushBack(&common.Node{
+		Function: &common.Function{
+			Name: name,
+			Specification: &common.FunctionSpecification{
+				IAT:            iat,
+				PerMinuteCount: perMinuteCount,
+			},
+		},
+	})
+
+	return functionLinkedList
+}
+
+func TestInvocationCursor(t *testing.T) {
+	driver := createTestDriver([]int{2, 0, 1})
+	driver.Configuration.TraceGranularity = common.MinuteGranularity
+
+	cursor := driver.newInvocationCursor(InvocationMetadata{
+		RootFunction: createFunctionLinkedList("f", common.IATArray{0, 30_000_000, 120_000_000}, []int{2, 0, 1}),
+	})
+
+	expectedIDs := []string{"min0.inv0", "min0.inv1", "min2.inv0"}
+	expectedDueAt := []int64{0, 30_000_000, 150_000_000}
+
+	for i := 0; i < len(expectedIDs); i++ {
+		if !cursor.hasNext() {
+			t.Fatalf("Cursor has run out of invocations at index %d.", i)
+		}
+
+		if cursor.dueAt != expectedDueAt[i] {
+			t.Errorf("Unexpected due time of invocation %d - got %d, expected %d.", i, cursor.dueAt, expectedDueAt[i])
+		}
+
+		metadata := cursor.next(driver.Configuration.TraceGranularity)
+		if metadata.InvocationID != expectedIDs[i] || metadata.IatIndex != i || metadata.Phase != common.ExecutionPhase {
+			t.Errorf("Unexpected invocation metadata - ID = %s, IAT index = %d.", metadata.InvocationID, metadata.IatIndex)
+		}
+	}
+
+	if cursor.hasNext() {
+		t.Error("Cursor should not have any invocations left.")
+	}
+}
+
+func TestInvocationScheduler(t *testing.T) {
+	driver := createTestDriver([]int{1})
+
+	var successfulInvocations, failedInvocations, invocationsIssued int64
+	schedulerDone, allFunctionsInvoked := &sync.WaitGroup{}, &sync.WaitGroup{}
+	recordOutputChannel := make(chan *metric.ExecutionRecord, 6)
+
+	functionLinkedLists := []*list.List{
+		createFunctionLinkedList("f1", common.IATArray{0, 200_000, 200_000}, []int{3}),
+		createFunctionLinkedList("f2", common.IATArray{100_000, 200_000}, []int{2}),
+		createFunctionLinkedList("f3", common.IATArray{}, []int{0}),
+		createFunctionLinkedList("f4", common.IATArray{500_000}, []int{1}),
+	}
+
+	start := time.Now()
+
+	schedulerDone.Add(1)
+	driver.invocationScheduler(
+		functionLinkedLists,
+		schedulerDone,
+		allFunctionsInvoked,
+		&successfulInvocations,
+		&failedInvocations,
+		&invocationsIssued,
+		recordOutputChannel,
+	)
+	schedulerDone.Wait()
+	close(recordOutputChannel)
+
+	if successfulInvocations != 6 || failedInvocations != 0 || invocationsIssued != 6 {
+		t.Errorf("Unexpected invocation counters - %d successful, %d failed, %d issued.",
+			successfulInvocations, failedInvocations, invocationsIssued)
+	}
+
+	var previousStartTime int64
+	for record := range recordOutputChannel {
+		if record.StartTime < previousStartTime {
+			t.Error("Invocations have not been issued in the order of their due time.")
+		}
+
+		previousStartTime = record.StartTime
+	}
+
+	if elapsed := time.Since(start); elapsed < 500*time.Millisecond {
+		t.Errorf("The last invocation has been issued too early - after %v.", elapsed)
+	}
+}
+
+func TestInvocationSchedulerWithBusyWorkers(t *testing.T) {
+	driver := createTestDriver([]int{4})
+	driver.Configuration.LoaderConfiguration.SchedulerWorkerPoolSize = 1
+
+	var successfulInvocations, failedInvocations, invocationsIssued int64
+	schedulerDone, allFunctionsInvoked := &sync.WaitGroup{}, &sync.WaitGroup{}
+	// the workers are kept busy until the records are read
+	recordOutputChannel := make(chan *metric.ExecutionRecord)
+
+	functionLinkedLists := []*list.List{
+		createFunctionLinkedList("f1", common.IATArray{0, 10_000, 10_000}, []int{3}),
+		createFunctionLinkedList("f2", common.IATArray{30_000}, []int{1}),
+	}
+
+	schedulerDone.Add(1)
+	go driver.invocationScheduler(
+		functionLinkedLists,
+		schedulerDone,
+		allFunctionsInvoked,
+		&successfulInvocations,
+		&failedInvocations,
+		&invocationsIssued,
+		recordOutputChannel,
+	)
+
+	time.Sleep(200 * time.Millisecond)
+
+	for i := 0; i < 4; i++ {
+		if record := <-recordOutputChannel; record.SchedulingLag > 100_000 {
+			t.Errorf("Invocation of function %s has waited for a worker - %d μs.", record.Function, record.SchedulingLag)
+		}
+	}
+	schedulerDone.Wait()
+}
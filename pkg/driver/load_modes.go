@@ -0,0 +1,287 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2023 EASL and the vHive community
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package driver
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/vhive-serverless/loader/pkg/common"
+	mc "github.com/vhive-serverless/loader/pkg/metric"
+)
+
+// inFlightLimiter caps the number of outstanding invocations per function and across all the functions. Invocations
+// exceeding the cap are queued on the client side until one of the outstanding invocations returns.
+type inFlightLimiter struct {
+	perFunctionLimit int
+	global           chan struct{}
+
+	perFunction      map[string]chan struct{}
+	perFunctionMutex sync.Mutex
+}
+
+func newInFlightLimiter(perFunctionLimit int, globalLimit int) *inFlightLimiter {
+	limiter := &inFlightLimiter{
+		perFunctionLimit: perFunctionLimit,
+		perFunction:      make(map[string]chan struct{}),
+	}
+
+	if globalLimit > 0 {
+		limiter.global = make(chan struct{}, globalLimit)
+	}
+
+	return limiter
+}
+
+func (l *inFlightLimiter) functionSemaphore(functionName string) chan struct{} {
+	if l.perFunctionLimit <= 0 {
+		return nil
+	}
+
+	l.perFunctionMutex.Lock()
+	defer l.perFunctionMutex.Unlock()
+
+	semaphore, ok := l.perFunction[functionName]
+	if !ok {
+		semaphore = make(chan struct{}, l.perFunctionLimit)
+		l.perFunction[functionName] = semaphore
+	}
+
+	return semaphore
+}
+
+// acquire blocks until the invocation is allowed to be issued and returns the time spent waiting in microseconds. It
+// returns false without holding any slot if the context is cancelled or the experiment is aborted while waiting.
+func (l *inFlightLimiter) acquire(ctx context.Context, abort <-chan struct{}, functionName string) (int64, bool) {
+	start := time.Now()
+
+	// the per-function slot is always taken first so that no global slot is held while waiting
+	semaphore := l.functionSemaphore(functionName)
+	if !takeSlot(ctx, abort, semaphore) {
+		return time.Since(start).Microseconds(), false
+	}
+	if !takeSlot(ctx, abort, l.global) {
+		if semaphore != nil {
+			<-semaphore
+		}
+
+		return time.Since(start).Microseconds(), false
+	}
+
+	return time.Since(start).Microseconds(), true
+}
+
+// takeSlot takes a slot of the semaphore, where nil stands for an unlimited one, unless the context is cancelled or
+// the experiment is aborted first
+func takeSlot(ctx context.Context, abort <-chan struct{}, semaphore chan struct{}) bool {
+	if semaphore == nil {
+		return true
+	}
+
+	select {
+	case semaphore <- struct{}{}:
+		return true
+	default:
+	}
+
+	select {
+	case semaphore <- struct{}{}:
+		return true
+	case <-abort:
+		return false
+	case <-ctx.Done():
+		return false
+	}
+}
+
+func (l *inFlightLimiter) release(functionName string) {
+	if l.global != nil {
+		<-l.global
+	}
+	if semaphore := l.functionSemaphore(functionName); semaphore != nil {
+		<-semaphore
+	}
+}
+
+// closedLoopCursor hands out invocations to the virtual users of a single function until the experiment ends. Unlike
+// invocationCursor, the index of an invocation within the minute (or second) is determined by the time it is issued.
+type closedLoopCursor struct {
+	template    InvocationMetadata
+	granularity common.TraceGranularity
+
+	// experimentEnd Trace time in microseconds at which the virtual users stop issuing invocations
+	experimentEnd int64
+
+	runtimeSpecificationCount int
+	invocationIndex           int
+
+	timeUnitIndex                         int
+	invocationSinceTheBeginningOfTimeUnit int
+
+	phase common.ExperimentPhase
+	mutex sync.Mutex
+}
+
+func (d *Driver) newClosedLoopCursor(template InvocationMetadata, runtimeSpecificationCount int) *closedLoopCursor {
+	cursor := &closedLoopCursor{
+		template:    template,
+		granularity: d.Configuration.TraceGranularity,
+
+		experimentEnd: d.Configuration.ScaledDuration(time.Duration(d.Configuration.TraceDuration) * time.Minute).Microseconds(),
+
+		runtimeSpecificationCount: runtimeSpecificationCount,
+
+		phase: common.ExecutionPhase,
+	}
+
+	if d.Configuration.WithWarmup() {
+		cursor.phase = common.WarmupPhase
+	}
+
+	return cursor
+}
+
+// nextClosedLoopInvocation returns the metadata of the following invocation, or false once the experiment duration has
+// elapsed in trace time, which stands still while the load is paused
+func (d *Driver) nextClosedLoopInvocation(c *closedLoopCursor) (*InvocationMetadata, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	now := d.clock.now()
+	if now >= c.experimentEnd || d.isAborted() {
+		return nil, false
+	}
+
+	timeUnit := d.Configuration.ScaledDuration(time.Minute)
+	if c.granularity == common.SecondGranularity {
+		timeUnit = d.Configuration.ScaledDuration(time.Second)
+	}
+
+	if timeUnitIndex := int(now / timeUnit.Microseconds()); timeUnitIndex != c.timeUnitIndex {
+		c.timeUnitIndex, c.invocationSinceTheBeginningOfTimeUnit = timeUnitIndex, 0
+	}
+	d.announceWarmupEnd(c.timeUnitIndex, &c.phase)
+
+	metadata := c.template
+	metadata.Phase = c.phase
+	metadata.InvocationID = composeInvocationID(c.granularity, c.timeUnitIndex, c.invocationSinceTheBeginningOfTimeUnit)
+	// runtime specifications generated for the trace are reused in a round-robin fashion
+	metadata.IatIndex = c.invocationIndex % c.runtimeSpecificationCount
+
+	c.invocationIndex++
+	c.invocationSinceTheBeginningOfTimeUnit++
+
+	return &metadata, true
+}
+
+// closedLoopDriver emulates a fixed number of virtual users per function, each of which issues an invocation, waits
+// for it to return, thinks for the configured time and repeats until the end of the experiment.
+func (d *Driver) closedLoopDriver(functionLinkedList *list.List, announceFunctionDone *sync.WaitGroup, addInvocationsToGroup *sync.WaitGroup, totalSuccessful *int64, totalFailed *int64, totalIssued *int64, recordOutputChannel chan *mc.ExecutionRecord) {
+	defer announceFunctionDone.Done()
+
+	function := functionLinkedList.Front().Value.(*common.Node).Function
+	runtimeSpecificationCount := len(function.Specification.RuntimeSpecification)
+
+	if runtimeSpecificationCount == 0 {
+		log.Debugf("No runtime specification found for function %s.\n", function.Name)
+		return
+	}
+
+	virtualUsers := d.Configuration.LoaderConfiguration.ClosedLoopVirtualUsers
+	if virtualUsers <= 0 {
+		virtualUsers = common.DefaultClosedLoopVirtualUsers
+	}
+	thinkTime := time.Duration(d.Configuration.LoaderConfiguration.ClosedLoopThinkTimeMs) * time.Millisecond
+
+	// result statistics
+	var successfulInvocations int64
+	var failedInvocations int64
+	var functionsInvoked int64
+
+	waitForInvocations := sync.WaitGroup{}
+	virtualUsersDone := sync.WaitGroup{}
+
+	cursor := d.newClosedLoopCursor(InvocationMetadata{
+		RootFunction:        functionLinkedList,
+		SuccessCount:        &successfulInvocations,
+		FailedCount:         &failedInvocations,
+		FunctionsInvoked:    &functionsInvoked,
+		RecordOutputChannel: recordOutputChannel,
+		AnnounceDoneWG:      &waitForInvocations,
+		AnnounceDoneExe:     addInvocationsToGroup,
+	}, runtimeSpecificationCount)
+
+	if d.Configuration.WithWarmup() {
+		log.Infof("Warmup phase has started.")
+	}
+
+	d.clock.start()
+	d.registerCounters(&successfulInvocations, &failedInvocations, &functionsInvoked)
+
+	for i := 0; i < virtualUsers; i++ {
+		virtualUsersDone.Add(1)
+
+		go func() {
+			defer virtualUsersDone.Done()
+
+			for {
+				if !d.waitWhilePaused() {
+					return
+				}
+
+				metadata, ok := d.nextClosedLoopInvocation(cursor)
+				if !ok {
+					return
+				}
+
+				d.runtimeMonitor.invocationIssued()
+				addInvocationsToGroup.Add(1)
+				waitForInvocations.Add(1)
+				if !d.Configuration.TestMode {
+					d.invokeFunction(metadata)
+				} else {
+					d.invokeFunctionInTestMode(metadata)
+				}
+
+				if !d.interruptibleSleep(d.clock.toWallClock(thinkTime)) {
+					return
+				}
+			}
+		}()
+	}
+
+	virtualUsersDone.Wait()
+	waitForInvocations.Wait()
+
+	log.Debugf("All the virtual users of function %s have completed.\n", function.Name)
+
+	atomic.AddInt64(totalSuccessful, successfulInvocations)
+	atomic.AddInt64(totalFailed, failedInvocations)
+	atomic.AddInt64(totalIssued, functionsInvoked)
+}
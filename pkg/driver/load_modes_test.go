@@ -0,0 +1,193 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2023 EASL and the vHive community
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package driver
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/vhive-serverless/loader/pkg/common"
+)
+
+func TestInFlightLimiter(t *testing.T) {
+	tests := []struct {
+		testName         string
+		perFunctionLimit int
+		globalLimit      int
+		functionNames    []string
+		expectQueueing   bool
+	}{
+		{
+			testName:         "unlimited",
+			perFunctionLimit: 0,
+			globalLimit:      0,
+			functionNames:    []string{"f1", "f1", "f2"},
+			expectQueueing:   false,
+		},
+		{
+			testName:         "per_function_limit",
+			perFunctionLimit: 1,
+			globalLimit:      0,
+			functionNames:    []string{"f1", "f1"},
+			expectQueueing:   true,
+		},
+		{
+			testName:         "per_function_limit_other_function",
+			perFunctionLimit: 1,
+			globalLimit:      0,
+			functionNames:    []string{"f1", "f2"},
+			expectQueueing:   false,
+		},
+		{
+			testName:         "global_limit",
+			perFunctionLimit: 0,
+			globalLimit:      1,
+			functionNames:    []string{"f1", "f2"},
+			expectQueueing:   true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.testName, func(t *testing.T) {
+			limiter := newInFlightLimiter(test.perFunctionLimit, test.globalLimit)
+			holdFor := 100 * time.Millisecond
+
+			// the first invocation is already in flight when the remaining ones are issued
+			limiter.acquire(context.Background(), nil, test.functionNames[0])
+
+			wg := sync.WaitGroup{}
+			delays := make([]int64, len(test.functionNames)-1)
+			for i, functionName := range test.functionNames[1:] {
+				wg.Add(1)
+
+				go func() {
+					defer wg.Done()
+
+					delays[i], _ = limiter.acquire(context.Background(), nil, functionName)
+					limiter.release(functionName)
+				}()
+			}
+
+			time.Sleep(holdFor)
+			limiter.release(test.functionNames[0])
+			wg.Wait()
+
+			for _, delay := range delays {
+				queued := delay >= holdFor.Microseconds()/2
+				if queued != test.expectQueueing {
+					t.Errorf("Unexpected client-side queueing delay of %d μs.", delay)
+				}
+			}
+		})
+	}
+}
+
+func TestInFlightLimiterAborted(t *testing.T) {
+	limiter := newInFlightLimiter(1, 1)
+	limiter.acquire(context.Background(), nil, "f1")
+
+	abort := make(chan struct{})
+	time.AfterFunc(50*time.Millisecond, func() { close(abort) })
+
+	if _, acquired := limiter.acquire(context.Background(), abort, "f2"); acquired {
+		t.Error("Expected the invocation queued for the global slot not to be issued once aborted.")
+	}
+	if len(limiter.functionSemaphore("f2")) != 0 {
+		t.Error("The per-function slot of the skipped invocation has not been released.")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, acquired := limiter.acquire(ctx, nil, "f1"); acquired {
+		t.Error("Expected the invocation not to be issued once the context is cancelled.")
+	}
+
+	limiter.release("f1")
+	if _, acquired := limiter.acquire(context.Background(), abort, "f1"); !acquired {
+		t.Error("Expected a free slot to be taken.")
+	}
+}
+
+func TestClosedLoopCursor(t *testing.T) {
+	driver := createTestDriver([]int{1})
+	driver.Configuration.TraceGranularity = common.MinuteGranularity
+
+	cursor := driver.newClosedLoopCursor(InvocationMetadata{
+		RootFunction: createFunctionLinkedList("f", common.IATArray{0}, []int{1}),
+	}, 2)
+	driver.clock.start()
+
+	for i := 0; i < 5; i++ {
+		metadata, ok := driver.nextClosedLoopInvocation(cursor)
+		if !ok {
+			t.Fatal("Closed-loop cursor has finished too early.")
+		}
+
+		if metadata.InvocationID != fmt.Sprintf("min0.inv%d", i) ||
+			metadata.IatIndex != i%2 ||
+			metadata.Phase != common.ExecutionPhase {
+
+			t.Errorf("Unexpected invocation metadata - ID = %s, IAT index = %d.", metadata.InvocationID, metadata.IatIndex)
+		}
+	}
+
+	cursor.experimentEnd = driver.clock.now()
+	if _, ok := driver.nextClosedLoopInvocation(cursor); ok {
+		t.Error("Closed-loop cursor should not issue invocations after the end of the experiment.")
+	}
+}
+
+func TestClosedLoopCursorWhilePaused(t *testing.T) {
+	driver := createTestDriver([]int{1})
+	driver.Configuration.TraceGranularity = common.SecondGranularity
+	// the experiment takes 100 ms, i.e., a second of the trace takes less than 2 ms
+	driver.Configuration.LoaderConfiguration.TimeScale = 0.1 / 60
+
+	cursor := driver.newClosedLoopCursor(InvocationMetadata{
+		RootFunction: createFunctionLinkedList("f", common.IATArray{0}, []int{1}),
+	}, 1)
+	driver.clock.start()
+	driver.clock.setPaused(true)
+
+	time.Sleep(150 * time.Millisecond)
+
+	metadata, ok := driver.nextClosedLoopInvocation(cursor)
+	if !ok {
+		t.Fatal("Closed-loop cursor has finished while the load is paused.")
+	}
+	if metadata.InvocationID != "sec0.inv0" {
+		t.Errorf("Time has advanced while the load is paused - ID = %s.", metadata.InvocationID)
+	}
+
+	driver.clock.setPaused(false)
+	time.Sleep(150 * time.Millisecond)
+
+	if _, ok = driver.nextClosedLoopInvocation(cursor); ok {
+		t.Error("Closed-loop cursor should not issue invocations after the end of the experiment.")
+	}
+}
@@ -0,0 +1,167 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2023 EASL and the vHive community
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package driver
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	log "github.com/sirupsen/logrus"
+	"github.com/vhive-serverless/loader/pkg/common"
+	mc "github.com/vhive-serverless/loader/pkg/metric"
+)
+
+// loaderMetrics exposes the statistics of the load generator itself in the Prometheus format. All the methods are
+// safe to call on nil metrics, which is the case when the metrics endpoint is disabled.
+type loaderMetrics struct {
+	registry *prometheus.Registry
+
+	issued       *prometheus.CounterVec
+	succeeded    *prometheus.CounterVec
+	failed       *prometheus.CounterVec
+	inFlight     *prometheus.GaugeVec
+	responseTime *prometheus.HistogramVec
+
+	schedulingLag prometheus.Histogram
+}
+
+func newLoaderMetrics() *loaderMetrics {
+	m := &loaderMetrics{
+		registry: prometheus.NewRegistry(),
+
+		issued: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "loader_invocations_issued_total",
+			Help: "Number of invocations issued by the loader.",
+		}, []string{"function", "phase"}),
+		succeeded: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "loader_invocations_succeeded_total",
+			Help: "Number of invocations that have completed successfully.",
+		}, []string{"function", "phase"}),
+		failed: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "loader_invocations_failed_total",
+			Help: "Number of invocations that have failed.",
+		}, []string{"function", "phase"}),
+		inFlight: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "loader_invocations_in_flight",
+			Help: "Number of invocations awaiting a response.",
+		}, []string{"function"}),
+		responseTime: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "loader_response_time_seconds",
+			Help:    "End-to-end latency of invocations as observed by the loader.",
+			Buckets: prometheus.ExponentialBuckets(0.001, 2, 18),
+		}, []string{"function"}),
+
+		schedulingLag: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    "loader_scheduling_lag_seconds",
+			Help:    "Delay between the time an invocation is due according to the trace and the time it is issued.",
+			Buckets: prometheus.ExponentialBuckets(0.0001, 2, 16),
+		}),
+	}
+
+	m.registry.MustRegister(m.issued, m.succeeded, m.failed, m.inFlight, m.responseTime, m.schedulingLag)
+
+	return m
+}
+
+func (m *loaderMetrics) invocationStarted(function string, phase common.ExperimentPhase) {
+	if m == nil {
+		return
+	}
+
+	m.issued.WithLabelValues(function, phase.String()).Inc()
+	m.inFlight.WithLabelValues(function).Inc()
+}
+
+func (m *loaderMetrics) invocationCompleted(function string, phase common.ExperimentPhase, success bool, record *mc.ExecutionRecord) {
+	if m == nil {
+		return
+	}
+
+	m.inFlight.WithLabelValues(function).Dec()
+
+	if !success {
+		m.failed.WithLabelValues(function, phase.String()).Inc()
+		return
+	}
+
+	m.succeeded.WithLabelValues(function, phase.String()).Inc()
+	if record != nil {
+		m.responseTime.WithLabelValues(function).Observe(float64(record.ResponseTime) / 1e6)
+	}
+}
+
+// observeSchedulingLag records the lag of an invocation given in microseconds
+func (m *loaderMetrics) observeSchedulingLag(lag int64) {
+	if m == nil {
+		return
+	}
+
+	m.schedulingLag.Observe(float64(lag) / 1e6)
+}
+
+// startMetricsServer serves the loader metrics on /metrics. Returns nil if the endpoint has not been enabled in the
+// configuration.
+func (d *Driver) startMetricsServer() *http.Server {
+	address := d.Configuration.LoaderConfiguration.LoaderMetricsAddress
+	if address == "" || d.loaderMetrics == nil {
+		return nil
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", promhttp.HandlerFor(d.loaderMetrics.registry, promhttp.HandlerOpts{}))
+
+	server := &http.Server{
+		Addr:    address,
+		Handler: mux,
+	}
+
+	go func() {
+		err := server.ListenAndServe()
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Errorf("Loader metrics server failed - %v", err)
+		}
+	}()
+
+	log.Infof("Loader metrics are exposed on %s/metrics.", address)
+
+	return server
+}
+
+func stopServer(server *http.Server) {
+	if server == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := server.Shutdown(ctx); err != nil {
+		log.Warnf("Failed to shut down the server on %s - %v", server.Addr, err)
+	}
+}
@@ -0,0 +1,81 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2023 EASL and the vHive community
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package driver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/vhive-serverless/loader/pkg/common"
+	mc "github.com/vhive-serverless/loader/pkg/metric"
+)
+
+func TestLoaderMetrics(t *testing.T) {
+	var disabled *loaderMetrics
+	disabled.invocationStarted("f", common.ExecutionPhase)
+	disabled.invocationCompleted("f", common.ExecutionPhase, true, nil)
+	disabled.observeSchedulingLag(100)
+
+	m := newLoaderMetrics()
+
+	m.invocationStarted("f1", common.WarmupPhase)
+	m.invocationStarted("f1", common.ExecutionPhase)
+	m.invocationStarted("f1", common.ExecutionPhase)
+	m.invocationStarted("f2", common.ExecutionPhase)
+
+	m.invocationCompleted("f1", common.WarmupPhase, true, &mc.ExecutionRecord{ExecutionRecordBase: mc.ExecutionRecordBase{ResponseTime: 20_000}})
+	m.invocationCompleted("f1", common.ExecutionPhase, false, &mc.ExecutionRecord{})
+	m.observeSchedulingLag(500)
+
+	if issued := testutil.ToFloat64(m.issued.WithLabelValues("f1", "execution")); issued != 2 {
+		t.Errorf("Unexpected number of issued invocations - %v.", issued)
+	}
+	if succeeded := testutil.ToFloat64(m.succeeded.WithLabelValues("f1", "warmup")); succeeded != 1 {
+		t.Errorf("Unexpected number of successful invocations - %v.", succeeded)
+	}
+	if failed := testutil.ToFloat64(m.failed.WithLabelValues("f1", "execution")); failed != 1 {
+		t.Errorf("Unexpected number of failed invocations - %v.", failed)
+	}
+	if inFlight := testutil.ToFloat64(m.inFlight.WithLabelValues("f1")); inFlight != 1 {
+		t.Errorf("Unexpected number of invocations in flight - %v.", inFlight)
+	}
+
+	recorder := httptest.NewRecorder()
+	promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{}).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	for _, metric := range []string{
+		`loader_invocations_issued_total{function="f2",phase="execution"} 1`,
+		`loader_response_time_seconds_count{function="f1"} 1`,
+		`loader_scheduling_lag_seconds_count 1`,
+	} {
+		if !strings.Contains(recorder.Body.String(), metric) {
+			t.Errorf("Metric %s is not exposed.", metric)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2023 EASL and the vHive community
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package driver
+
+import (
+	"context"
+	"math"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/vhive-serverless/loader/pkg/driver/clients"
+	mc "github.com/vhive-serverless/loader/pkg/metric"
+)
+
+// openWhiskPollInterval Time between two listings of the activations while some of them have not completed yet
+const openWhiskPollInterval = 2 * time.Second
+
+// collectOpenWhiskActivations fetches the records of the activations that have not completed within their invocation
+// in bulk and writes the invocations to the log. Activations still missing once the function timeout has elapsed are
+// reported as timed out.
+func (d *Driver) collectOpenWhiskActivations(logCh chan *mc.ExecutionRecord) {
+	pending := make(map[string]*mc.ExecutionRecord)
+	since := int64(math.MaxInt64)
+
+	for d.AsyncRecords.Length() > 0 {
+		record := d.AsyncRecords.Dequeue()
+
+		pending[record.AsyncResponseID] = record
+		since = min(since, record.StartTime/1000)
+	}
+
+	if len(pending) == 0 {
+		return
+	}
+
+	log.Infof("Fetching the records of %d OpenWhisk activations...", len(pending))
+
+	client := clients.NewOpenWhiskClient(d.Configuration.LoaderConfiguration)
+	deadline := time.Now().Add(time.Duration(d.Configuration.LoaderConfiguration.GRPCFunctionTimeoutSeconds) * time.Second)
+
+	for {
+		// the records are also fetched if the experiment has been cancelled, which only stops the polling
+		activations, err := client.ListActivations(context.Background(), since)
+		if err != nil {
+			log.Errorf("Failed to list OpenWhisk activations - %v", err)
+		}
+
+		for i := range activations {
+			activation := &activations[i]
+
+			record, ok := pending[activation.ActivationID]
+			if !ok || activation.Response == nil {
+				continue
+			}
+			delete(pending, activation.ActivationID)
+
+			clients.FillActivationRecord(record, activation)
+			// the response time spans from issuing the invocation until the activation has completed
+			record.ResponseTime = activation.End*1000 - record.StartTime
+			record.AsyncResponseID = ""
+
+			logCh <- record
+		}
+
+		if len(pending) == 0 || time.Now().After(deadline) || !d.sleepOrCancel(openWhiskPollInterval) {
+			break
+		}
+	}
+
+	for activationID, record := range pending {
+		log.Errorf("Record of OpenWhisk activation %s could not be fetched.", activationID)
+
+		record.FunctionTimeout = true
+		record.AsyncResponseID = ""
+
+		logCh <- record
+	}
+
+	log.Infof("Finished fetching the records of OpenWhisk activations")
+}
+
+// sleepOrCancel returns false if the experiment has been cancelled before the given time has elapsed
+func (d *Driver) sleepOrCancel(duration time.Duration) bool {
+	select {
+	case <-time.After(duration):
+		return true
+	case <-d.invocationContext.Done():
+		return false
+	}
+}
@@ -0,0 +1,89 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2023 EASL and the vHive community
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package driver
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/vhive-serverless/loader/pkg/common"
+	"github.com/vhive-serverless/loader/pkg/driver/clients"
+	mc "github.com/vhive-serverless/loader/pkg/metric"
+)
+
+func TestCollectOpenWhiskActivations(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/namespaces/"+common.OpenWhiskDefaultNamespace+"/activations" || r.URL.Query().Get("since") != "1000" {
+			t.Errorf("Unexpected request %s", r.URL.String())
+		}
+
+		_ = json.NewEncoder(w).Encode([]clients.Activation{
+			{
+				ActivationID: "completed",
+				Start:        1010,
+				End:          1040,
+				Duration:     25,
+				Response:     &clients.ActivationResponse{Status: "success", Success: true},
+			},
+			{ActivationID: "running", Start: 1020},
+			{ActivationID: "unrelated", Start: 1030, Response: &clients.ActivationResponse{Success: true}},
+		})
+	}))
+	defer server.Close()
+
+	driver := createTestDriver([]int{1})
+	driver.Configuration.LoaderConfiguration.OpenWhiskAPIHost = server.URL
+	driver.Configuration.LoaderConfiguration.OpenWhiskAuth = "user:key"
+	driver.Configuration.LoaderConfiguration.GRPCFunctionTimeoutSeconds = 1
+
+	driver.AsyncRecords.Enqueue(&mc.ExecutionRecord{ExecutionRecordBase: mc.ExecutionRecordBase{StartTime: 1_000_000}, AsyncResponseID: "completed"})
+	driver.AsyncRecords.Enqueue(&mc.ExecutionRecord{ExecutionRecordBase: mc.ExecutionRecordBase{StartTime: 1_005_000}, AsyncResponseID: "running"})
+
+	logCh := make(chan *mc.ExecutionRecord, 2)
+	driver.collectOpenWhiskActivations(logCh)
+	close(logCh)
+
+	records := make(map[int64]*mc.ExecutionRecord)
+	for record := range logCh {
+		records[record.StartTime] = record
+	}
+
+	completed, running := records[1_000_000], records[1_005_000]
+	if completed == nil || running == nil {
+		t.Fatalf("Expected both activations to be logged, got %d.", len(records))
+	}
+	if completed.FunctionTimeout || completed.ActualDuration != 25_000 || completed.ResponseTime != 40_000 ||
+		completed.StartType != mc.Hot || completed.AsyncResponseID != "" {
+		t.Errorf("Unexpected record of the completed activation %+v", completed)
+	}
+	if !running.FunctionTimeout || running.AsyncResponseID != "" {
+		t.Errorf("Expected the running activation to time out, got %+v", running)
+	}
+	if driver.AsyncRecords.Length() != 0 {
+		t.Errorf("Expected all the asynchronous records to be consumed.")
+	}
+}
@@ -0,0 +1,214 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2023 EASL and the vHive community
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package driver
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/vhive-serverless/loader/pkg/common"
+	"github.com/vhive-serverless/loader/pkg/config"
+	"github.com/vhive-serverless/loader/pkg/driver/clients"
+	mc "github.com/vhive-serverless/loader/pkg/metric"
+)
+
+// retryPolicy decides whether a failed invocation is attempted again and how long to back off before doing so
+type retryPolicy struct {
+	maxAttempts    int
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+	jitter         float64
+	// retryOn Failure classes that are retried, where nil stands for all of them
+	retryOn map[string]bool
+
+	countAgainstSchedule bool
+	// pendingRetries Number of retries per scheduled function that have not yet been compensated for by skipping
+	// one of its scheduled invocations
+	pendingRetries      map[string]int
+	pendingRetriesMutex sync.Mutex
+}
+
+func newRetryPolicy(cfg *config.LoaderConfiguration) *retryPolicy {
+	policy := &retryPolicy{
+		maxAttempts:    cfg.RetryMaxAttempts,
+		initialBackoff: time.Duration(cfg.RetryInitialBackoffMs) * time.Millisecond,
+		maxBackoff:     time.Duration(cfg.RetryMaxBackoffMs) * time.Millisecond,
+		jitter:         cfg.RetryJitter,
+
+		countAgainstSchedule: cfg.RetriesCountAgainstSchedule,
+		pendingRetries:       make(map[string]int),
+	}
+
+	if policy.maxAttempts <= 0 {
+		policy.maxAttempts = 1
+		if cfg.DAGMode {
+			// a failed function of a DAG used to be retried once, hence the default is kept for compatibility
+			policy.maxAttempts = 2
+		}
+	}
+
+	if policy.jitter < 0 || policy.jitter > 1 {
+		log.Fatal("Retry jitter should be between 0 and 1.")
+	}
+
+	if len(cfg.RetryOn) > 0 {
+		policy.retryOn = make(map[string]bool)
+
+		for _, failureClass := range cfg.RetryOn {
+			switch failureClass {
+			case common.RetryOnConnectionTimeout, common.RetryOnFunctionTimeout, common.RetryOnServerError, common.RetryOnTooManyRequests:
+				policy.retryOn[failureClass] = true
+			default:
+				log.Fatalf("Unsupported failure class to retry on - %s.", failureClass)
+			}
+		}
+	}
+
+	return policy
+}
+
+// failureClass classifies a failed invocation by the status code of the response if there is one, or by the type of
+// the timeout otherwise
+func failureClass(record *mc.ExecutionRecord) string {
+	switch {
+	case record.HttpStatusCode == http.StatusTooManyRequests:
+		return common.RetryOnTooManyRequests
+	case record.HttpStatusCode >= http.StatusInternalServerError:
+		return common.RetryOnServerError
+	case record.ConnectionTimeout:
+		return common.RetryOnConnectionTimeout
+	case record.FunctionTimeout:
+		return common.RetryOnFunctionTimeout
+	default:
+		return ""
+	}
+}
+
+// shouldRetry is called after the given attempt (starting from zero) has failed
+func (p *retryPolicy) shouldRetry(attempt int, record *mc.ExecutionRecord) bool {
+	if attempt+1 >= p.maxAttempts {
+		return false
+	}
+
+	if p.retryOn == nil {
+		return true
+	}
+
+	// gRPC invokers mark a failed call as both connection and function timeout
+	return p.retryOn[failureClass(record)] ||
+		(record.HttpStatusCode == 0 && record.ConnectionTimeout && p.retryOn[common.RetryOnConnectionTimeout]) ||
+		(record.HttpStatusCode == 0 && record.FunctionTimeout && p.retryOn[common.RetryOnFunctionTimeout])
+}
+
+// backoff returns the time to wait after the given attempt has failed, growing exponentially with the number of
+// attempts and randomly reduced by up to the jitter ratio
+func (p *retryPolicy) backoff(attempt int) time.Duration {
+	if p.initialBackoff <= 0 {
+		return 0
+	}
+
+	backoff := float64(p.initialBackoff) * math.Pow(2, float64(attempt))
+	if p.maxBackoff > 0 {
+		backoff = math.Min(backoff, float64(p.maxBackoff))
+	}
+
+	return time.Duration(backoff * (1 - p.jitter*rand.Float64()))
+}
+
+// retryIssued records a retry of an invocation scheduled by the IATs of the given function
+func (p *retryPolicy) retryIssued(scheduledFunction string) {
+	if !p.countAgainstSchedule {
+		return
+	}
+
+	p.pendingRetriesMutex.Lock()
+	defer p.pendingRetriesMutex.Unlock()
+
+	p.pendingRetries[scheduledFunction]++
+}
+
+// skipScheduledInvocation returns true if the next scheduled invocation of the given function should not be issued,
+// since a retry has already been issued in its place
+func (p *retryPolicy) skipScheduledInvocation(scheduledFunction string) bool {
+	if !p.countAgainstSchedule {
+		return false
+	}
+
+	p.pendingRetriesMutex.Lock()
+	defer p.pendingRetriesMutex.Unlock()
+
+	if p.pendingRetries[scheduledFunction] == 0 {
+		return false
+	}
+
+	p.pendingRetries[scheduledFunction]--
+
+	return true
+}
+
+type retryObserverKey struct{}
+
+// retryObserver is called with the record of every failed attempt of an invocation that is retried
+type retryObserver func(record *mc.ExecutionRecord)
+
+// withRetryObserver returns a context through which the retry middleware reports the attempts it retries
+func withRetryObserver(ctx context.Context, observer retryObserver) context.Context {
+	return context.WithValue(ctx, retryObserverKey{}, observer)
+}
+
+// retryMiddleware attempts failed invocations again as decided by the retry policy of the driver, so that the retries
+// pass through all the middlewares wrapped by it. The attempt is set in the record of every attempt.
+func (d *Driver) retryMiddleware() clients.Middleware {
+	return func(next clients.Invoker) clients.Invoker {
+		return clients.InvokerFunc(func(ctx context.Context, function *common.Function, runtimeSpec *common.RuntimeSpecification) (bool, *mc.ExecutionRecord) {
+			observer, _ := ctx.Value(retryObserverKey{}).(retryObserver)
+
+			for attempt := 0; ; attempt++ {
+				success, record := next.Invoke(ctx, function, runtimeSpec)
+				record.Attempt = attempt
+
+				if success || !d.retryPolicy.shouldRetry(attempt, record) {
+					return success, record
+				}
+
+				backoff := d.retryPolicy.backoff(attempt)
+				log.Debugf("Invocation for function %s failed. Retrying invocation in %v.", function.Name, backoff)
+
+				if !d.interruptibleSleep(backoff) {
+					return success, record
+				}
+
+				if observer != nil {
+					observer(record)
+				}
+			}
+		})
+	}
+}
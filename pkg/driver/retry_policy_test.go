@@ -0,0 +1,200 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2023 EASL and the vHive community
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package driver
+
+import (
+	"container/list"
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/vhive-serverless/loader/pkg/common"
+	"github.com/vhive-serverless/loader/pkg/config"
+	"github.com/vhive-serverless/loader/pkg/driver/clients"
+	"github.com/vhive-serverless/loader/pkg/metric"
+)
+
+func TestRetryPolicy(t *testing.T) {
+	tests := []struct {
+		testName    string
+		cfg         config.LoaderConfiguration
+		attempt     int
+		record      metric.ExecutionRecord
+		expectRetry bool
+	}{
+		{
+			testName:    "no_retries_by_default",
+			cfg:         config.LoaderConfiguration{},
+			record:      metric.ExecutionRecord{ExecutionRecordBase: metric.ExecutionRecordBase{ConnectionTimeout: true}},
+			expectRetry: false,
+		},
+		{
+			testName:    "single_retry_in_dag_mode",
+			cfg:         config.LoaderConfiguration{DAGMode: true},
+			record:      metric.ExecutionRecord{ExecutionRecordBase: metric.ExecutionRecordBase{FunctionTimeout: true}},
+			expectRetry: true,
+		},
+		{
+			testName:    "attempts_exhausted",
+			cfg:         config.LoaderConfiguration{RetryMaxAttempts: 3},
+			attempt:     2,
+			record:      metric.ExecutionRecord{ExecutionRecordBase: metric.ExecutionRecordBase{FunctionTimeout: true}},
+			expectRetry: false,
+		},
+		{
+			testName:    "retryable_status_code",
+			cfg:         config.LoaderConfiguration{RetryMaxAttempts: 3, RetryOn: []string{common.RetryOnTooManyRequests}},
+			record:      metric.ExecutionRecord{HttpStatusCode: http.StatusTooManyRequests, ExecutionRecordBase: metric.ExecutionRecordBase{FunctionTimeout: true}},
+			expectRetry: true,
+		},
+		{
+			testName:    "non_retryable_status_code",
+			cfg:         config.LoaderConfiguration{RetryMaxAttempts: 3, RetryOn: []string{common.RetryOnServerError}},
+			record:      metric.ExecutionRecord{HttpStatusCode: http.StatusNotFound, ExecutionRecordBase: metric.ExecutionRecordBase{FunctionTimeout: true}},
+			expectRetry: false,
+		},
+		{
+			testName:    "grpc_failure",
+			cfg:         config.LoaderConfiguration{RetryMaxAttempts: 3, RetryOn: []string{common.RetryOnFunctionTimeout}},
+			record:      metric.ExecutionRecord{ExecutionRecordBase: metric.ExecutionRecordBase{ConnectionTimeout: true, FunctionTimeout: true}},
+			expectRetry: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.testName, func(t *testing.T) {
+			policy := newRetryPolicy(&test.cfg)
+
+			if policy.shouldRetry(test.attempt, &test.record) != test.expectRetry {
+				t.Errorf("Unexpected retry decision after attempt %d.", test.attempt)
+			}
+		})
+	}
+}
+
+func TestRetryBackoff(t *testing.T) {
+	policy := newRetryPolicy(&config.LoaderConfiguration{
+		RetryMaxAttempts:      5,
+		RetryInitialBackoffMs: 100,
+		RetryMaxBackoffMs:     300,
+		RetryJitter:           0.5,
+	})
+
+	expectedMaximum := []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 300 * time.Millisecond, 300 * time.Millisecond}
+	for attempt, maximum := range expectedMaximum {
+		if backoff := policy.backoff(attempt); backoff > maximum || backoff < maximum/2 {
+			t.Errorf("Backoff after attempt %d out of bounds - %v.", attempt, backoff)
+		}
+	}
+}
+
+func TestRetriesCountAgainstSchedule(t *testing.T) {
+	policy := newRetryPolicy(&config.LoaderConfiguration{RetriesCountAgainstSchedule: true})
+
+	policy.retryIssued("f1")
+	policy.retryIssued("f1")
+
+	if policy.skipScheduledInvocation("f2") {
+		t.Error("Retries of a function should not affect the schedule of another one.")
+	}
+	if !policy.skipScheduledInvocation("f1") || !policy.skipScheduledInvocation("f1") {
+		t.Error("Every retry should replace a scheduled invocation.")
+	}
+	if policy.skipScheduledInvocation("f1") {
+		t.Error("No more scheduled invocations should be skipped.")
+	}
+}
+
+func TestInvokeFunctionWithRetries(t *testing.T) {
+	var successCount, failureCount, functionsInvoked int64
+
+	testDriver := createTestDriver([]int{1})
+	testDriver.retryPolicy = newRetryPolicy(&config.LoaderConfiguration{RetryMaxAttempts: 3})
+
+	function := testDriver.Configuration.Functions[0]
+	function.Specification.RuntimeSpecification = []common.RuntimeSpecification{{
+		Runtime: 1000,
+		Memory:  128,
+	}}
+
+	functionList := list.New()
+	functionList.PushBack(&common.Node{Function: function})
+
+	recordOutputChannel := make(chan *metric.ExecutionRecord, 3)
+	announceDone := &sync.WaitGroup{}
+
+	announceDone.Add(1)
+	testDriver.invokeFunction(&InvocationMetadata{
+		RootFunction:        functionList,
+		Phase:               common.ExecutionPhase,
+		InvocationID:        composeInvocationID(common.MinuteGranularity, 0, 0),
+		SuccessCount:        &successCount,
+		FailedCount:         &failureCount,
+		FunctionsInvoked:    &functionsInvoked,
+		RecordOutputChannel: recordOutputChannel,
+		AnnounceDoneWG:      announceDone,
+	})
+	announceDone.Wait()
+	close(recordOutputChannel)
+
+	if successCount != 0 || failureCount != 1 || functionsInvoked != 3 {
+		t.Errorf("Unexpected invocation counters - %d successful, %d failed, %d invoked.", successCount, failureCount, functionsInvoked)
+	}
+
+	attempt := 0
+	for record := range recordOutputChannel {
+		if record.Attempt != attempt {
+			t.Errorf("Unexpected attempt index - got %d, expected %d.", record.Attempt, attempt)
+		}
+
+		attempt++
+	}
+}
+
+func TestRetryMiddleware(t *testing.T) {
+	testDriver := createTestDriver([]int{1})
+	testDriver.retryPolicy = newRetryPolicy(&config.LoaderConfiguration{RetryMaxAttempts: 3})
+
+	calls := 0
+	invoker := clients.Chain(clients.InvokerFunc(func(ctx context.Context, function *common.Function, runtimeSpec *common.RuntimeSpecification) (bool, *metric.ExecutionRecord) {
+		calls++
+		return calls == 2, &metric.ExecutionRecord{ExecutionRecordBase: metric.ExecutionRecordBase{ConnectionTimeout: calls != 2}}
+	}), testDriver.retryMiddleware())
+
+	var retried []*metric.ExecutionRecord
+	ctx := withRetryObserver(context.Background(), func(record *metric.ExecutionRecord) {
+		retried = append(retried, record)
+	})
+
+	success, record := invoker.Invoke(ctx, &common.Function{Name: "f"}, &common.RuntimeSpecification{})
+	if !success || calls != 2 || record.Attempt != 1 {
+		t.Errorf("Unexpected outcome of the retried invocation - success = %t, %d calls, attempt %d.", success, calls, record.Attempt)
+	}
+	if len(retried) != 1 || retried[0].Attempt != 0 || !retried[0].ConnectionTimeout {
+		t.Errorf("Unexpected attempts reported as retried - %+v.", retried)
+	}
+}
@@ -0,0 +1,172 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2023 EASL and the vHive community
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package driver
+
+import (
+	"container/list"
+	"fmt"
+	"os"
+	"sync/atomic"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/vhive-serverless/loader/pkg/common"
+)
+
+// runtimeMonitor counts the invocations issued and failed since the beginning of the experiment and within the current
+// minute. All the methods are safe to call on a nil monitor, which is the case when runtime assertions are disabled.
+type runtimeMonitor struct {
+	requestedPerMinute []int
+	requestedSoFar     int
+
+	issuedTotal int64
+	issued      int64
+	failed      int64
+}
+
+func (d *Driver) newRuntimeMonitor(functionLinkedLists []*list.List) *runtimeMonitor {
+	minutes := d.Configuration.TraceDuration
+	requestedPerMinute := make([]int, minutes)
+
+	// number of trace columns falling into a single minute of the experiment
+	columnsPerMinute := 1
+	if d.Configuration.TraceGranularity == common.SecondGranularity {
+		columnsPerMinute = 60
+	}
+
+	for _, functionLinkedList := range functionLinkedLists {
+		function := functionLinkedList.Front().Value.(*common.Node).Function
+
+		for column, count := range function.Specification.PerMinuteCount {
+			if minute := column / columnsPerMinute; minute < minutes {
+				requestedPerMinute[minute] += count
+			}
+		}
+	}
+
+	m := &runtimeMonitor{
+		requestedPerMinute: requestedPerMinute,
+	}
+
+	// the minutes before the checkpoint of a resumed experiment are assumed to have met the target
+	if d.resumeFrom != nil {
+		for minute := 0; minute < common.MinOf(d.resumeFrom.Minute, minutes); minute++ {
+			m.requestedSoFar += requestedPerMinute[minute]
+		}
+		m.issuedTotal = int64(m.requestedSoFar)
+	}
+
+	return m
+}
+
+func (m *runtimeMonitor) invocationIssued() {
+	if m == nil {
+		return
+	}
+
+	atomic.AddInt64(&m.issuedTotal, 1)
+	atomic.AddInt64(&m.issued, 1)
+}
+
+func (m *runtimeMonitor) invocationFailed() {
+	if m == nil {
+		return
+	}
+
+	atomic.AddInt64(&m.failed, 1)
+}
+
+// assertRuntimeTargets is called at the end of each minute and aborts the experiment if either the loader could not
+// keep up with the requested load or too many invocations have failed
+func (d *Driver) assertRuntimeTargets(minute int) {
+	m := d.runtimeMonitor
+	if m == nil {
+		return
+	}
+
+	issued := int(atomic.SwapInt64(&m.issued, 0))
+	failed := int(atomic.SwapInt64(&m.failed, 0))
+	// failures in DAG branches are not issued by the driver directly
+	failed = common.MinOf(failed, issued)
+
+	// in closed-loop mode, or once the load has been paused or scaled through the control server, the load is no longer
+	// determined by the trace
+	_, _, altered := d.clock.state()
+	if d.Configuration.LoaderConfiguration.LoadMode != common.ClosedLoopMode && !altered && minute < len(m.requestedPerMinute) {
+		// the totals are compared so that invocations slipping over a minute boundary are not counted as missing
+		m.requestedSoFar += m.requestedPerMinute[minute]
+		issuedSoFar := common.MinOf(int(atomic.LoadInt64(&m.issuedTotal)), m.requestedSoFar)
+
+		if !d.isRequestTargetAchieved(m.requestedSoFar, issuedSoFar, common.RequestedVsIssued) {
+			d.abortExperiment(fmt.Sprintf("Minute %d: the loader has issued only %d out of %d requested invocations.",
+				minute, issuedSoFar, m.requestedSoFar))
+			return
+		}
+	}
+
+	if !d.isRequestTargetAchieved(issued, issued-failed, common.IssuedVsFailed) {
+		d.abortExperiment(fmt.Sprintf("Minute %d: %d out of %d issued invocations have failed.", minute, failed, issued))
+	}
+}
+
+func (d *Driver) isAborted() bool {
+	select {
+	case <-d.abort:
+		return true
+	default:
+		return false
+	}
+}
+
+// abortExperiment stops issuing new invocations, while the ones in flight are still awaited and written to the output
+func (d *Driver) abortExperiment(reason string) {
+	d.abortOnce.Do(func() {
+		log.Errorf("Aborting the experiment - %s", reason)
+
+		close(d.abort)
+
+		err := os.WriteFile(d.outputFilenameWithExtension("abort_reason", "txt"), []byte(reason+"\n"), 0644)
+		if err != nil {
+			log.Errorf("Failed to write the abort reason to the output directory - %v", err)
+		}
+	})
+}
+
+// interruptibleSleep returns false if the experiment has been aborted before the given time has elapsed
+func (d *Driver) interruptibleSleep(duration time.Duration) bool {
+	if duration <= 0 {
+		return !d.isAborted()
+	}
+
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-d.abort:
+		return false
+	}
+}
@@ -0,0 +1,170 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2023 EASL and the vHive community
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package driver
+
+import (
+	"container/list"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vhive-serverless/loader/pkg/common"
+)
+
+func TestRuntimeMonitorRequestedInvocations(t *testing.T) {
+	driver := createTestDriver([]int{1})
+	driver.Configuration.TraceDuration = 2
+
+	tests := []struct {
+		testName    string
+		granularity common.TraceGranularity
+		perMinute   [][]int
+		expected    []int
+	}{
+		{
+			testName:    "minute_granularity",
+			granularity: common.MinuteGranularity,
+			perMinute:   [][]int{{1, 2, 3}, {4, 5}},
+			expected:    []int{5, 7},
+		},
+		{
+			testName:    "second_granularity",
+			granularity: common.SecondGranularity,
+			perMinute:   [][]int{append(make([]int, 59), 1, 2)},
+			expected:    []int{1, 2},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.testName, func(t *testing.T) {
+			driver.Configuration.TraceGranularity = test.granularity
+
+			var functionLinkedLists []*list.List
+			for _, perMinuteCount := range test.perMinute {
+				functionLinkedLists = append(functionLinkedLists, createFunctionLinkedList("f", nil, perMinuteCount))
+			}
+
+			monitor := driver.newRuntimeMonitor(functionLinkedLists)
+			for minute, requested := range test.expected {
+				if monitor.requestedPerMinute[minute] != requested {
+					t.Errorf("Unexpected number of requested invocations in minute %d - got %d, expected %d.",
+						minute, monitor.requestedPerMinute[minute], requested)
+				}
+			}
+		})
+	}
+}
+
+func TestAssertRuntimeTargets(t *testing.T) {
+	tests := []struct {
+		testName      string
+		loadMode      string
+		issued        int
+		failed        int
+		expectAborted bool
+	}{
+		{
+			testName:      "targets_achieved",
+			issued:        10,
+			failed:        1,
+			expectAborted: false,
+		},
+		{
+			testName:      "too_few_issued",
+			issued:        5,
+			failed:        0,
+			expectAborted: true,
+		},
+		{
+			testName:      "too_many_failed",
+			issued:        10,
+			failed:        6,
+			expectAborted: true,
+		},
+		{
+			testName:      "closed_loop_ignores_requested",
+			loadMode:      common.ClosedLoopMode,
+			issued:        5,
+			failed:        0,
+			expectAborted: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.testName, func(t *testing.T) {
+			driver := createTestDriver([]int{10})
+			driver.Configuration.LoaderConfiguration.OutputPathPrefix = filepath.Join(t.TempDir(), "test")
+			driver.Configuration.LoaderConfiguration.LoadMode = test.loadMode
+			driver.runtimeMonitor = &runtimeMonitor{requestedPerMinute: []int{10}}
+
+			for i := 0; i < test.issued; i++ {
+				driver.runtimeMonitor.invocationIssued()
+			}
+			for i := 0; i < test.failed; i++ {
+				driver.runtimeMonitor.invocationFailed()
+			}
+
+			driver.assertRuntimeTargets(0)
+
+			if driver.isAborted() != test.expectAborted {
+				t.Fatalf("Unexpected abort state - got %v, expected %v.", driver.isAborted(), test.expectAborted)
+			}
+
+			reason, err := os.ReadFile(driver.outputFilenameWithExtension("abort_reason", "txt"))
+			if test.expectAborted && (err != nil || !strings.HasPrefix(string(reason), "Minute 0")) {
+				t.Errorf("Abort reason has not been written to the output directory - %v.", err)
+			} else if !test.expectAborted && err == nil {
+				t.Error("Abort reason should not be written if the experiment has not been aborted.")
+			}
+		})
+	}
+}
+
+func TestInterruptibleSleep(t *testing.T) {
+	driver := createTestDriver([]int{1})
+	driver.Configuration.LoaderConfiguration.OutputPathPrefix = filepath.Join(t.TempDir(), "test")
+
+	if !driver.interruptibleSleep(time.Millisecond) {
+		t.Error("Sleep should not be interrupted before the experiment is aborted.")
+	}
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		driver.abortExperiment("test")
+	}()
+
+	start := time.Now()
+	if driver.interruptibleSleep(time.Minute) {
+		t.Error("Sleep should be interrupted once the experiment is aborted.")
+	}
+	if time.Since(start) > 10*time.Second {
+		t.Error("Sleep has not been interrupted in time.")
+	}
+
+	// aborting more than once must not panic
+	driver.abortExperiment("test")
+}
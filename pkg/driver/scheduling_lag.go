@@ -0,0 +1,193 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2023 EASL and the vHive community
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package driver
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	mc "github.com/vhive-serverless/loader/pkg/metric"
+)
+
+// schedulingLagMonitor collects the scheduling lag of the invocations issued within each second of the experiment,
+// writes a summary per second and detects whether the loader is overloaded, i.e., cannot issue the invocations on time.
+// All the methods are safe to call on a nil monitor, which is the case when invocations are issued outside an experiment.
+type schedulingLagMonitor struct {
+	lags  []int64
+	mutex sync.Mutex
+
+	// the summary file is created along with the first summary, so that no empty file is left behind
+	filename string
+	offset   int64
+	output   *mc.CSVFile
+	// firstSecond Index of the second the monitor starts at, which is not zero if the experiment has been resumed
+	firstSecond int
+
+	finish chan struct{}
+	done   chan struct{}
+}
+
+func (d *Driver) startSchedulingLagMonitor() *schedulingLagMonitor {
+	m := &schedulingLagMonitor{
+		filename: d.outputFilename("scheduling_lag"),
+		offset:   d.resumeOffset("scheduling_lag"),
+		finish:   make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+
+	if d.resumeFrom != nil {
+		m.firstSecond = int(d.Configuration.ScaledDuration(time.Duration(d.resumeFrom.Minute)*time.Minute) / time.Second)
+	}
+
+	go d.summarizeSchedulingLag(m)
+
+	return m
+}
+
+// observe records the scheduling lag of a single invocation in microseconds
+func (m *schedulingLagMonitor) observe(lag int64) {
+	if m == nil {
+		return
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.lags = append(m.lags, lag)
+}
+
+// stop writes the summary of the last, possibly partial, second and waits for the summary file to be flushed
+func (m *schedulingLagMonitor) stop() {
+	if m == nil {
+		return
+	}
+
+	close(m.finish)
+	<-m.done
+}
+
+// flush writes the summaries to the file and returns the size of the file
+func (m *schedulingLagMonitor) flush() int64 {
+	if m == nil {
+		return 0
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.output == nil {
+		return m.offset
+	}
+
+	return m.output.Flush()
+}
+
+func (d *Driver) summarizeSchedulingLag(m *schedulingLagMonitor) {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for second := m.firstSecond; ; second++ {
+		select {
+		case <-ticker.C:
+			d.writeSchedulingLagSummary(m, second)
+		case <-m.finish:
+			d.writeSchedulingLagSummary(m, second)
+
+			m.mutex.Lock()
+			if m.output != nil {
+				m.output.Close()
+			}
+			m.mutex.Unlock()
+			close(m.done)
+
+			return
+		}
+	}
+}
+
+func (d *Driver) writeSchedulingLagSummary(m *schedulingLagMonitor, second int) {
+	m.mutex.Lock()
+	lags := m.lags
+	m.lags = nil
+	m.mutex.Unlock()
+
+	if len(lags) == 0 {
+		return
+	}
+
+	summary := summarizeLags(lags)
+	summary.Timestamp = time.Now().UnixMicro()
+	summary.Second = second
+
+	m.mutex.Lock()
+	if m.output == nil {
+		m.output = mc.OpenCSVFile(m.filename, m.offset)
+	}
+	output := m.output
+	m.mutex.Unlock()
+
+	output.Write(summary)
+
+	d.assertSchedulingLag(summary)
+}
+
+func summarizeLags(lags []int64) *mc.SchedulingLagSummary {
+	slices.Sort(lags)
+
+	var sum int64
+	for _, lag := range lags {
+		sum += lag
+	}
+
+	return &mc.SchedulingLagSummary{
+		Invocations: len(lags),
+		MeanLag:     sum / int64(len(lags)),
+		MedianLag:   lags[len(lags)/2],
+		P99Lag:      lags[int(math.Ceil(0.99*float64(len(lags))))-1],
+		MaxLag:      lags[len(lags)-1],
+	}
+}
+
+// assertSchedulingLag warns, or aborts the experiment if configured so, when the 99th percentile of the scheduling lag
+// within a second exceeds the configured bound
+func (d *Driver) assertSchedulingLag(summary *mc.SchedulingLagSummary) {
+	bound := int64(d.Configuration.LoaderConfiguration.SchedulingLagBoundMs) * 1000
+	if bound <= 0 || summary.P99Lag <= bound {
+		return
+	}
+
+	reason := fmt.Sprintf("Second %d: the 99th percentile of the scheduling lag is %d μs, exceeding the bound of %d ms - the loader is overloaded.",
+		summary.Second, summary.P99Lag, d.Configuration.LoaderConfiguration.SchedulingLagBoundMs)
+
+	if d.Configuration.LoaderConfiguration.AbortOnSchedulingLag {
+		d.abortExperiment(reason)
+	} else {
+		log.Warn(reason)
+	}
+}
@@ -0,0 +1,136 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2023 EASL and the vHive community
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package driver
+
+import (
+	"os"
+	"testing"
+
+	"github.com/gocarina/gocsv"
+	mc "github.com/vhive-serverless/loader/pkg/metric"
+)
+
+func TestSummarizeLags(t *testing.T) {
+	lags := make([]int64, 0, 100)
+	for i := 100; i > 0; i-- {
+		lags = append(lags, int64(i))
+	}
+
+	summary := summarizeLags(lags)
+	if summary.Invocations != 100 || summary.MeanLag != 50 || summary.MedianLag != 51 || summary.P99Lag != 99 || summary.MaxLag != 100 {
+		t.Errorf("Unexpected scheduling lag summary - %+v.", summary)
+	}
+}
+
+func TestAssertSchedulingLag(t *testing.T) {
+	tests := []struct {
+		testName      string
+		boundMs       int
+		abort         bool
+		p99Lag        int64
+		expectAborted bool
+	}{
+		{
+			testName:      "disabled",
+			boundMs:       0,
+			abort:         true,
+			p99Lag:        1_000_000,
+			expectAborted: false,
+		},
+		{
+			testName:      "within_bound",
+			boundMs:       10,
+			abort:         true,
+			p99Lag:        10_000,
+			expectAborted: false,
+		},
+		{
+			testName:      "exceeded_warn_only",
+			boundMs:       10,
+			abort:         false,
+			p99Lag:        10_001,
+			expectAborted: false,
+		},
+		{
+			testName:      "exceeded_abort",
+			boundMs:       10,
+			abort:         true,
+			p99Lag:        10_001,
+			expectAborted: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.testName, func(t *testing.T) {
+			driver := createTestDriver([]int{1})
+			driver.Configuration.LoaderConfiguration.SchedulingLagBoundMs = test.boundMs
+			driver.Configuration.LoaderConfiguration.AbortOnSchedulingLag = test.abort
+
+			driver.assertSchedulingLag(&mc.SchedulingLagSummary{P99Lag: test.p99Lag})
+
+			if driver.isAborted() != test.expectAborted {
+				t.Errorf("Unexpected abort state - expected %t.", test.expectAborted)
+			}
+			_ = os.Remove(driver.outputFilenameWithExtension("abort_reason", "txt"))
+		})
+	}
+}
+
+func TestSchedulingLagMonitor(t *testing.T) {
+	driver := createTestDriver([]int{1})
+
+	monitor := driver.startSchedulingLagMonitor()
+	monitor.observe(100)
+	monitor.observe(300)
+	monitor.stop()
+
+	filename := driver.outputFilename("scheduling_lag")
+
+	file, err := os.Open(filename)
+	if err != nil {
+		t.Fatalf("Failed to open the scheduling lag summary - %v", err)
+	}
+	var summaries []mc.SchedulingLagSummary
+	if err := gocsv.UnmarshalFile(file, &summaries); err != nil {
+		t.Fatalf("Failed to parse the scheduling lag summary - %v", err)
+	}
+	_ = file.Close()
+	_ = os.Remove(filename)
+
+	if len(summaries) != 1 || summaries[0].Invocations != 2 || summaries[0].MeanLag != 200 || summaries[0].MaxLag != 300 {
+		t.Errorf("Unexpected scheduling lag summary - %+v.", summaries)
+	}
+
+	// no summary file is written if no invocation has been issued
+	driver.startSchedulingLagMonitor().stop()
+	if _, err := os.Stat(filename); err == nil {
+		t.Error("Scheduling lag summary should not be written without any invocations.")
+	}
+
+	// observing after the experiment has ended or without a monitor must not fail
+	var disabled *schedulingLagMonitor
+	disabled.observe(100)
+	disabled.stop()
+}
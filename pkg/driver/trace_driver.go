@@ -26,6 +26,7 @@ package driver
 
 import (
 	"container/list"
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -51,9 +52,37 @@ type Driver struct {
 	SpecificationGenerator *generator.SpecificationGenerator
 	Invoker                clients.Invoker
 
-	AsyncRecords          *common.LockFreeQueue[*mc.ExecutionRecord]
-	readOpenWhiskMetadata sync.Mutex
-	allFunctionsInvoked   sync.WaitGroup
+	AsyncRecords        *common.LockFreeQueue[*mc.ExecutionRecord]
+	asyncCollector      *asyncCollector
+	allFunctionsInvoked sync.WaitGroup
+	inFlightLimiter     *inFlightLimiter
+
+	runtimeMonitor *runtimeMonitor
+	abort          chan struct{}
+	abortOnce      sync.Once
+
+	invocationContext context.Context
+	cancelInvocations context.CancelFunc
+
+	loaderMetrics *loaderMetrics
+	retryPolicy   *retryPolicy
+	schedulingLag *schedulingLagMonitor
+	durationFile  *mc.CSVFile
+	resumeFrom    *checkpoint
+	// recordGate Keeps checkpoints from being taken while the records of an invocation are handed over
+	recordGate sync.RWMutex
+	// flushRequests Asks the collector writing the duration file to flush the records received so far
+	flushRequests chan chan int64
+	// collectorDone Closed once the collector has written all the records and closed the duration file
+	collectorDone chan struct{}
+	// completed Counters of the invocations issued from the trace that have completed along with all of their parts
+	completed invocationCounts
+
+	clock         *experimentClock
+	currentMinute int64
+	counters      []invocationCounters
+	cursors       []*invocationCursor
+	countersMutex sync.Mutex
 }
 
 func NewDriver(driverConfig *config.Configuration) *Driver {
@@ -61,12 +90,28 @@ func NewDriver(driverConfig *config.Configuration) *Driver {
 		Configuration:          driverConfig,
 		SpecificationGenerator: generator.NewSpecificationGenerator(driverConfig.LoaderConfiguration.Seed),
 
-		AsyncRecords:          common.NewLockFreeQueue[*mc.ExecutionRecord](),
-		readOpenWhiskMetadata: sync.Mutex{},
-		allFunctionsInvoked:   sync.WaitGroup{},
+		AsyncRecords:        common.NewLockFreeQueue[*mc.ExecutionRecord](),
+		allFunctionsInvoked: sync.WaitGroup{},
+		inFlightLimiter: newInFlightLimiter(
+			driverConfig.LoaderConfiguration.MaxInFlightPerFunction,
+			driverConfig.LoaderConfiguration.MaxInFlightGlobal,
+		),
+		abort: make(chan struct{}),
+		clock: newExperimentClock(),
+
+		retryPolicy: newRetryPolicy(driverConfig.LoaderConfiguration),
+	}
+	d.invocationContext, d.cancelInvocations = context.WithCancel(context.Background())
+
+	if driverConfig.LoaderConfiguration.AsyncMode {
+		d.asyncCollector = newAsyncCollector(driverConfig.LoaderConfiguration, d.invocationContext.Done())
 	}
 
-	d.Invoker = clients.CreateInvoker(driverConfig.LoaderConfiguration, &d.allFunctionsInvoked, &d.readOpenWhiskMetadata)
+	if driverConfig.LoaderConfiguration.LoaderMetricsAddress != "" {
+		d.loaderMetrics = newLoaderMetrics()
+	}
+
+	d.Invoker = clients.CreateInvoker(driverConfig.LoaderConfiguration, &d.allFunctionsInvoked, d.retryMiddleware())
 
 	return d
 }
@@ -75,7 +120,11 @@ func NewDriver(driverConfig *config.Configuration) *Driver {
 // HELPER METHODS
 // ///////////////////////////////////////
 func (d *Driver) outputFilename(name string) string {
-	return fmt.Sprintf("%s_%s_%d.csv", d.Configuration.LoaderConfiguration.OutputPathPrefix, name, d.Configuration.TraceDuration)
+	return d.outputFilenameWithExtension(name, "csv")
+}
+
+func (d *Driver) outputFilenameWithExtension(name string, extension string) string {
+	return fmt.Sprintf("%s_%s_%d.%s", d.Configuration.LoaderConfiguration.OutputPathPrefix, name, d.Configuration.TraceDuration, extension)
 }
 
 /////////////////////////////////////////
@@ -88,6 +137,11 @@ type InvocationMetadata struct {
 
 	InvocationID string
 	IatIndex     int
+	// ScheduledBy Name of the function whose IATs the invocation has been scheduled by, i.e., the root of a DAG
+	ScheduledBy string
+	// IntendedFireTime Time in microseconds since the epoch the invocation is due according to the trace, or zero if it
+	// is not determined by the trace
+	IntendedFireTime int64
 
 	SuccessCount        *int64
 	FailedCount         *int64
@@ -95,6 +149,9 @@ type InvocationMetadata struct {
 	RecordOutputChannel chan *mc.ExecutionRecord
 	AnnounceDoneWG      *sync.WaitGroup
 	AnnounceDoneExe     *sync.WaitGroup
+
+	// tracked Invocation issued from the trace the records of which are held back until it has completed, or nil
+	tracked *trackedInvocation
 }
 
 func composeInvocationID(timeGranularity common.TraceGranularity, minuteIndex int, invocationIndex int) string {
@@ -114,47 +171,109 @@ func composeInvocationID(timeGranularity common.TraceGranularity, minuteIndex in
 
 func (d *Driver) invokeFunction(metadata *InvocationMetadata) {
 	defer metadata.AnnounceDoneWG.Done()
+	defer d.releaseInvocation(metadata)
 
 	var success bool
 	node := metadata.RootFunction.Front()
 	var record *mc.ExecutionRecord
 	var runtimeSpecifications *common.RuntimeSpecification
 	var branches []*list.List
-	var invocationRetries int
+
+	if metadata.ScheduledBy == "" {
+		metadata.ScheduledBy = node.Value.(*common.Node).Function.Name
+	}
+	// only the first attempt of the first function is determined by the trace
+	intendedFireTime := metadata.IntendedFireTime
+
 	for node != nil {
 		function := node.Value.(*common.Node).Function
 		runtimeSpecifications = &function.Specification.RuntimeSpecification[metadata.IatIndex]
 
-		success, record = d.Invoker.Invoke(function, runtimeSpecifications)
+		actualFireTime := time.Now().UnixMicro()
+		if intendedFireTime == 0 {
+			intendedFireTime = actualFireTime
+		}
+		schedulingLag := actualFireTime - intendedFireTime
+		d.schedulingLag.observe(schedulingLag)
+		d.loaderMetrics.observeSchedulingLag(schedulingLag)
 
-		if !success && (d.Configuration.LoaderConfiguration.DAGMode && invocationRetries == 0) {
-			log.Debugf("Invocation with for function %s with ID %s failed. Retrying Invocation", function.Name, metadata.InvocationID)
-			invocationRetries += 1
-			continue
+		queueingDelay, acquired := d.inFlightLimiter.acquire(d.invocationContext, d.abort, function.Name)
+		if !acquired {
+			log.Debugf("Skipping invocation for function %s with ID %s, as the experiment has ended while it was queued.", function.Name, metadata.InvocationID)
+			break
 		}
-		record.Phase = int(metadata.Phase)
-		record.Instance = fmt.Sprintf("%s%s", node.Value.(*common.Node).DAG, record.Instance)
-		record.InvocationID = metadata.InvocationID
 
-		if !d.Configuration.LoaderConfiguration.AsyncMode || record.AsyncResponseID == "" {
-			metadata.RecordOutputChannel <- record
-		} else {
-			record.TimeToSubmitMs = record.ResponseTime
-			d.AsyncRecords.Enqueue(record)
+		logAttempt := func(record *mc.ExecutionRecord) {
+			record.Phase = int(metadata.Phase)
+			record.Instance = fmt.Sprintf("%s%s", node.Value.(*common.Node).DAG, record.Instance)
+			record.InvocationID = metadata.InvocationID
+			if record.Attempt == 0 {
+				record.ClientQueueingDelay += queueingDelay
+				record.IntendedFireTime = intendedFireTime
+				record.ActualFireTime = actualFireTime
+				record.SchedulingLag = schedulingLag
+			} else {
+				// retries are issued as soon as their backoff has passed
+				record.IntendedFireTime = record.StartTime
+				record.ActualFireTime = record.StartTime
+			}
+			record.Function = function.Name
+			record.RequestedMemory = uint32(runtimeSpecifications.Memory)
+
+			if record.AsyncResponseID == "" {
+				d.logRecord(metadata, record)
+			} else if d.asyncCollector != nil {
+				record.TimeToSubmitMs = record.ResponseTime
+				if metadata.tracked != nil {
+					metadata.tracked.acquire()
+				}
+				d.asyncCollector.collect(record, func(record *mc.ExecutionRecord) {
+					d.logRecord(metadata, record)
+					d.releaseInvocation(metadata)
+				})
+			} else {
+				record.TimeToSubmitMs = record.ResponseTime
+				d.AsyncRecords.Enqueue(record)
+			}
+			atomic.AddInt64(metadata.FunctionsInvoked, 1)
+			metadata.tracked.count(1, 0, 0)
 		}
-		atomic.AddInt64(metadata.FunctionsInvoked, 1)
+
+		// the attempts retried by the retry middleware are logged once they have failed
+		invocationContext := withRetryObserver(clients.WithInvocationID(d.invocationContext, metadata.InvocationID), func(record *mc.ExecutionRecord) {
+			d.loaderMetrics.invocationCompleted(function.Name, metadata.Phase, false, record)
+			logAttempt(record)
+			d.retryPolicy.retryIssued(metadata.ScheduledBy)
+			d.loaderMetrics.invocationStarted(function.Name, metadata.Phase)
+		})
+
+		d.loaderMetrics.invocationStarted(function.Name, metadata.Phase)
+		success, record = d.Invoker.Invoke(invocationContext, function, runtimeSpecifications)
+		d.loaderMetrics.invocationCompleted(function.Name, metadata.Phase, success, record)
+		d.inFlightLimiter.release(function.Name)
+
+		logAttempt(record)
+		intendedFireTime = 0
+
 		if !success {
 			log.Errorf("Invocation with for function %s with ID %s failed.", function.Name, metadata.InvocationID)
 			atomic.AddInt64(metadata.FailedCount, 1)
+			metadata.tracked.count(0, 0, 1)
+			d.runtimeMonitor.invocationFailed()
 			break
 		}
 		atomic.AddInt64(metadata.SuccessCount, 1)
+		metadata.tracked.count(0, 1, 0)
 		branches = node.Value.(*common.Node).Branches
 		for i := 0; i < len(branches); i++ {
 			newMetadataValue := *metadata
 			newMetadata := &newMetadataValue
 			newMetadata.RootFunction = branches[i]
+			newMetadata.IntendedFireTime = 0
 			newMetadata.AnnounceDoneWG.Add(1)
+			if newMetadata.tracked != nil {
+				newMetadata.tracked.acquire()
+			}
 			go d.invokeFunction(newMetadata)
 		}
 
@@ -162,6 +281,37 @@ func (d *Driver) invokeFunction(metadata *InvocationMetadata) {
 	}
 }
 
+// invokeFunctionInTestMode is used in place of invokeFunction from within the Golang testing framework
+func (d *Driver) invokeFunctionInTestMode(metadata *InvocationMetadata) {
+	defer metadata.AnnounceDoneWG.Done()
+	defer d.releaseInvocation(metadata)
+
+	log.Debugf("Test mode invocation fired - ID = %s.\n", metadata.InvocationID)
+
+	actualFireTime := time.Now().UnixMicro()
+	intendedFireTime := metadata.IntendedFireTime
+	if intendedFireTime == 0 {
+		intendedFireTime = actualFireTime
+	}
+	d.schedulingLag.observe(actualFireTime - intendedFireTime)
+
+	d.logRecord(metadata, &mc.ExecutionRecord{
+		ExecutionRecordBase: mc.ExecutionRecordBase{
+			Phase:        int(metadata.Phase),
+			InvocationID: metadata.InvocationID,
+			StartTime:    time.Now().UnixNano(),
+		},
+		IntendedFireTime: intendedFireTime,
+		ActualFireTime:   actualFireTime,
+		SchedulingLag:    actualFireTime - intendedFireTime,
+		Function:         metadata.RootFunction.Front().Value.(*common.Node).Function.Name,
+	})
+
+	atomic.AddInt64(metadata.FunctionsInvoked, 1)
+	atomic.AddInt64(metadata.SuccessCount, 1)
+	metadata.tracked.count(1, 1, 0)
+}
+
 func (d *Driver) functionsDriver(functionLinkedList *list.List, announceFunctionDone *sync.WaitGroup, addInvocationsToGroup *sync.WaitGroup, totalSuccessful *int64, totalFailed *int64, totalIssued *int64, recordOutputChannel chan *mc.ExecutionRecord) {
 	defer announceFunctionDone.Done()
 
@@ -175,82 +325,50 @@ func (d *Driver) functionsDriver(functionLinkedList *list.List, announceFunction
 	}
 
 	// result statistics
-	minuteIndexSearch := common.NewIntervalSearch(function.Specification.PerMinuteCount)
-	interval := minuteIndexSearch.SearchInterval(0)
-	minuteIndexEnd, minuteIndex, invocationSinceTheBeginningOfMinute := interval.End, interval.Value, 0
-
-	IAT := function.Specification.IAT
-	iatIndex, terminationIAT := 0, invocationCount
-
 	var successfulInvocations int64
 	var failedInvocations int64
 	var functionsInvoked int64
-	var currentPhase = common.ExecutionPhase
 
 	waitForInvocations := sync.WaitGroup{}
 
+	cursor := d.newInvocationCursor(InvocationMetadata{
+		RootFunction:        functionLinkedList,
+		SuccessCount:        &successfulInvocations,
+		FailedCount:         &failedInvocations,
+		FunctionsInvoked:    &functionsInvoked,
+		RecordOutputChannel: recordOutputChannel,
+		AnnounceDoneWG:      &waitForInvocations,
+		AnnounceDoneExe:     addInvocationsToGroup,
+	})
+
 	if d.Configuration.WithWarmup() {
-		currentPhase = common.WarmupPhase
 		log.Infof("Warmup phase has started.")
 	}
 
-	startOfExperiment := time.Now()
-	var previousIATSum int64
-
-	for {
-		if iatIndex >= len(IAT) || iatIndex >= terminationIAT {
-			break // end of experiment for this individual function driver
-		}
+	d.clock.start()
+	d.registerCounters(&successfulInvocations, &failedInvocations, &functionsInvoked)
 
-		d.announceWarmupEnd(minuteIndex, &currentPhase)
+	for cursor.hasNext() {
+		d.announceWarmupEnd(cursor.minuteIndex, &cursor.phase)
 
-		iat := time.Duration(IAT[iatIndex]) * time.Microsecond
-
-		schedulingDelay := time.Since(startOfExperiment).Microseconds() - previousIATSum
-		sleepFor := iat.Microseconds() - schedulingDelay
-		time.Sleep(time.Duration(sleepFor) * time.Microsecond)
-
-		previousIATSum += iat.Microseconds()
+		if !d.waitForTraceTime(cursor.dueAt) {
+			break
+		}
+		d.runtimeMonitor.invocationIssued()
+		intendedFireTime := d.clock.wallClockTime(cursor.dueAt).UnixMicro()
+		metadata := cursor.next(d.Configuration.TraceGranularity)
+		metadata.IntendedFireTime = intendedFireTime
+		if d.retryPolicy.skipScheduledInvocation(function.Name) {
+			log.Debugf("Skipping invocation with ID %s of function %s in favour of a retry.", metadata.InvocationID, function.Name)
+			d.releaseInvocation(metadata)
+			continue
+		}
 
+		waitForInvocations.Add(1)
 		if !d.Configuration.TestMode {
-			waitForInvocations.Add(1)
-			go d.invokeFunction(&InvocationMetadata{
-				RootFunction:        functionLinkedList,
-				Phase:               currentPhase,
-				InvocationID:        composeInvocationID(d.Configuration.TraceGranularity, minuteIndex, invocationSinceTheBeginningOfMinute),
-				IatIndex:            iatIndex,
-				SuccessCount:        &successfulInvocations,
-				FailedCount:         &failedInvocations,
-				FunctionsInvoked:    &functionsInvoked,
-				RecordOutputChannel: recordOutputChannel,
-				AnnounceDoneWG:      &waitForInvocations,
-				AnnounceDoneExe:     addInvocationsToGroup,
-			})
+			go d.invokeFunction(metadata)
 		} else {
-			// To be used from within the Golang testing framework
-			invocationID := composeInvocationID(d.Configuration.TraceGranularity, minuteIndex, invocationSinceTheBeginningOfMinute)
-			log.Debugf("Test mode invocation fired - ID = %s.\n", invocationID)
-
-			recordOutputChannel <- &mc.ExecutionRecord{
-				ExecutionRecordBase: mc.ExecutionRecordBase{
-					Phase:        int(currentPhase),
-					InvocationID: invocationID,
-					StartTime:    time.Now().UnixNano(),
-				},
-			}
-			functionsInvoked++
-			successfulInvocations++
-		}
-
-		iatIndex++
-
-		// counter updates
-		invocationSinceTheBeginningOfMinute++
-		if iatIndex > minuteIndexEnd {
-			interval = minuteIndexSearch.SearchInterval(iatIndex)
-			if interval != nil { // otherwise, the experiment will terminate in the next for loop iteration
-				minuteIndexEnd, minuteIndex, invocationSinceTheBeginningOfMinute = interval.End, interval.Value, 0
-			}
+			d.invokeFunctionInTestMode(metadata)
 		}
 	}
 
@@ -260,7 +378,7 @@ func (d *Driver) functionsDriver(functionLinkedList *list.List, announceFunction
 
 	atomic.AddInt64(totalSuccessful, successfulInvocations)
 	atomic.AddInt64(totalFailed, failedInvocations)
-	atomic.AddInt64(totalIssued, int64(functionsInvoked))
+	atomic.AddInt64(totalIssued, functionsInvoked)
 }
 
 func (d *Driver) announceWarmupEnd(minuteIndex int, currentPhase *common.ExperimentPhase) {
@@ -270,8 +388,7 @@ func (d *Driver) announceWarmupEnd(minuteIndex int, currentPhase *common.Experim
 	}
 }
 
-// TODO: currently unused - add issued/requested monitoring feature
-func isRequestTargetAchieved(ideal int, real int, assertType common.RuntimeAssertType) bool {
+func (d *Driver) isRequestTargetAchieved(ideal int, real int, assertType common.RuntimeAssertType) bool {
 	if ideal == 0 {
 		return true
 	}
@@ -284,18 +401,19 @@ func isRequestTargetAchieved(ideal int, real int, assertType common.RuntimeAsser
 
 	switch assertType {
 	case common.RequestedVsIssued:
-		warnBound = common.RequestedVsIssuedWarnThreshold
-		terminationBound = common.RequestedVsIssuedTerminateThreshold
+		warnBound = thresholdOrDefault(d.Configuration.LoaderConfiguration.RequestedVsIssuedWarnThreshold, common.RequestedVsIssuedWarnThreshold)
+		terminationBound = thresholdOrDefault(d.Configuration.LoaderConfiguration.RequestedVsIssuedTerminateThreshold, common.RequestedVsIssuedTerminateThreshold)
 		warnMessage = fmt.Sprintf("Relative difference between requested and issued number of invocations has reached %.2f.", ratio)
 	case common.IssuedVsFailed:
-		warnBound = common.FailedWarnThreshold
-		terminationBound = common.FailedTerminateThreshold
+		warnBound = thresholdOrDefault(d.Configuration.LoaderConfiguration.FailedWarnThreshold, common.FailedWarnThreshold)
+		terminationBound = thresholdOrDefault(d.Configuration.LoaderConfiguration.FailedTerminateThreshold, common.FailedTerminateThreshold)
 		warnMessage = fmt.Sprintf("Percentage of failed invocations within a minute has reached %.2f.", ratio)
 	default:
 		log.Fatal("Invalid type of assertion at runtime.")
 	}
 
-	if ratio < 0 || ratio > 1 {
+	// more invocations than requested may be issued within a minute due to scheduling delays
+	if ratio > 1 {
 		log.Fatalf("Invalid arguments provided to runtime assertion.\n")
 	} else if ratio >= terminationBound {
 		return false
@@ -308,29 +426,49 @@ func isRequestTargetAchieved(ideal int, real int, assertType common.RuntimeAsser
 	return true
 }
 
+func thresholdOrDefault(configured float64, defaultValue float64) float64 {
+	if configured > 0 {
+		return configured
+	}
+
+	return defaultValue
+}
+
 func hasMinuteExpired(t1 time.Time) bool {
 	return time.Since(t1) > time.Minute
 }
 
 func (d *Driver) globalTimekeeper(totalTraceDuration int, signalReady *sync.WaitGroup) {
-	ticker := time.NewTicker(time.Minute)
 	globalTimeCounter := 0
+	if d.resumeFrom != nil {
+		globalTimeCounter = d.resumeFrom.Minute
+	}
 
+	d.clock.start()
 	signalReady.Done()
 
 	for {
-		<-ticker.C
+		// minutes advance in trace time, i.e., they are compressed or stretched along with the IATs and stand still
+		// while the load is paused, so that the minute of a checkpoint matches the progress of the cursors
+		endOfMinute := d.Configuration.ScaledDuration(time.Duration(globalTimeCounter+1) * time.Minute).Microseconds()
+		if !d.waitForTraceTime(endOfMinute) {
+			return
+		}
 
 		log.Debugf("End of minute %d\n", globalTimeCounter)
+		d.assertRuntimeTargets(globalTimeCounter)
+
 		globalTimeCounter++
+		atomic.StoreInt64(&d.currentMinute, int64(globalTimeCounter))
+		if d.Configuration.LoaderConfiguration.EnableCheckpointing {
+			d.writeCheckpoint(globalTimeCounter)
+		}
 		if globalTimeCounter >= totalTraceDuration {
 			break
 		}
 
 		log.Debugf("Start of minute %d\n", globalTimeCounter)
 	}
-
-	ticker.Stop()
 }
 
 func (d *Driver) startBackgroundProcesses(allRecordsWritten *sync.WaitGroup) (*sync.WaitGroup, chan *mc.ExecutionRecord, chan int64, chan int) {
@@ -350,7 +488,8 @@ func (d *Driver) startBackgroundProcesses(allRecordsWritten *sync.WaitGroup) (*s
 
 	globalMetricsCollector := make(chan *mc.ExecutionRecord)
 	totalIssuedChannel := make(chan int64)
-	go mc.CreateGlobalMetricsCollector(d.outputFilename("duration"), globalMetricsCollector, auxiliaryProcessBarrier, allRecordsWritten, totalIssuedChannel)
+	d.durationFile = mc.OpenCSVFile(d.outputFilename("duration"), d.resumeOffset("duration"))
+	d.startCollector(globalMetricsCollector, auxiliaryProcessBarrier, allRecordsWritten, totalIssuedChannel)
 
 	traceDurationInMinutes := d.Configuration.TraceDuration
 	go d.globalTimekeeper(traceDurationInMinutes, auxiliaryProcessBarrier)
@@ -358,6 +497,17 @@ func (d *Driver) startBackgroundProcesses(allRecordsWritten *sync.WaitGroup) (*s
 	return auxiliaryProcessBarrier, globalMetricsCollector, totalIssuedChannel, finishCh
 }
 
+// startCollector writes the records received from the collector channel to the duration file in the background
+func (d *Driver) startCollector(collector chan *mc.ExecutionRecord, signalReady *sync.WaitGroup, signalEverythingWritten *sync.WaitGroup, totalIssuedChannel chan int64) {
+	d.flushRequests, d.collectorDone = make(chan chan int64), make(chan struct{})
+
+	go func() {
+		defer close(d.collectorDone)
+
+		mc.CreateGlobalMetricsCollector(d.durationFile, collector, d.flushRequests, signalReady, signalEverythingWritten, totalIssuedChannel)
+	}()
+}
+
 func (d *Driver) internalRun() {
 	var successfulInvocations int64
 	var failedInvocations int64
@@ -368,17 +518,35 @@ func (d *Driver) internalRun() {
 	allRecordsWritten := sync.WaitGroup{}
 	allRecordsWritten.Add(1)
 
-	backgroundProcessesInitializationBarrier, globalMetricsCollector, totalIssuedChannel, scraperFinishCh := d.startBackgroundProcesses(&allRecordsWritten)
-	backgroundProcessesInitializationBarrier.Wait()
-
+	var functionLinkedLists []*list.List
 	if d.Configuration.LoaderConfiguration.DAGMode {
 		functions := d.Configuration.Functions
-		dagLists := generator.GenerateDAGs(d.Configuration.LoaderConfiguration, functions, false)
+		functionLinkedLists = generator.GenerateDAGs(d.Configuration.LoaderConfiguration, functions, false)
 		log.Infof("Starting DAG invocation driver\n")
-		for i := range len(dagLists) {
+	} else {
+		log.Infof("Starting function invocation driver\n")
+		for _, function := range d.Configuration.Functions {
+			functionLinkedList := list.New()
+			functionLinkedList.PushBack(&common.Node{Function: function, Depth: 0})
+			functionLinkedLists = append(functionLinkedLists, functionLinkedList)
+		}
+	}
+
+	if d.Configuration.LoaderConfiguration.EnableRuntimeAssertions {
+		d.runtimeMonitor = d.newRuntimeMonitor(functionLinkedLists)
+	}
+
+	d.schedulingLag = d.startSchedulingLagMonitor()
+
+	backgroundProcessesInitializationBarrier, globalMetricsCollector, totalIssuedChannel, scraperFinishCh := d.startBackgroundProcesses(&allRecordsWritten)
+	backgroundProcessesInitializationBarrier.Wait()
+
+	if d.Configuration.LoaderConfiguration.LoadMode == common.ClosedLoopMode {
+		log.Infof("Issuing invocations in closed loop\n")
+		for i := range len(functionLinkedLists) {
 			allIndividualDriversCompleted.Add(1)
-			go d.functionsDriver(
-				dagLists[i],
+			go d.closedLoopDriver(
+				functionLinkedLists[i],
 				&allIndividualDriversCompleted,
 				&allFunctionsInvoked,
 				&successfulInvocations,
@@ -388,13 +556,24 @@ func (d *Driver) internalRun() {
 			)
 		}
 	} else {
-		log.Infof("Starting function invocation driver\n")
-		for _, function := range d.Configuration.Functions {
+		switch d.Configuration.LoaderConfiguration.InvocationScheduler {
+		case "", common.PerFunctionScheduler:
+			for i := range len(functionLinkedLists) {
+				allIndividualDriversCompleted.Add(1)
+				go d.functionsDriver(
+					functionLinkedLists[i],
+					&allIndividualDriversCompleted,
+					&allFunctionsInvoked,
+					&successfulInvocations,
+					&failedInvocations,
+					&invocationsIssued,
+					globalMetricsCollector,
+				)
+			}
+		case common.CentralizedScheduler:
 			allIndividualDriversCompleted.Add(1)
-			functionLinkedList := list.New()
-			functionLinkedList.PushBack(&common.Node{Function: function, Depth: 0})
-			go d.functionsDriver(
-				functionLinkedList,
+			go d.invocationScheduler(
+				functionLinkedLists,
 				&allIndividualDriversCompleted,
 				&allFunctionsInvoked,
 				&successfulInvocations,
@@ -402,19 +581,20 @@ func (d *Driver) internalRun() {
 				&invocationsIssued,
 				globalMetricsCollector,
 			)
+		default:
+			log.Fatal("Unsupported invocation scheduler.")
 		}
 	}
 	allIndividualDriversCompleted.Wait()
+	d.schedulingLag.stop()
+
 	if atomic.LoadInt64(&successfulInvocations)+atomic.LoadInt64(&failedInvocations) != 0 {
 		log.Debugf("Waiting for all the invocations record to be written.\n")
 
-		if d.Configuration.LoaderConfiguration.AsyncMode {
-			sleepFor := time.Duration(d.Configuration.LoaderConfiguration.AsyncWaitToCollectMin) * time.Minute
-
-			log.Infof("Sleeping for %v...", sleepFor)
-			time.Sleep(sleepFor)
-
-			d.writeAsyncRecordsToLog(globalMetricsCollector)
+		if d.asyncCollector != nil {
+			d.asyncCollector.wait()
+		} else if d.Configuration.LoaderConfiguration.Platform == "OpenWhisk" {
+			d.collectOpenWhiskActivations(globalMetricsCollector)
 		}
 		totalIssuedChannel <- atomic.LoadInt64(&invocationsIssued)
 		scraperFinishCh <- 0 // Ask the scraper to finish metrics collection
@@ -435,6 +615,12 @@ func (d *Driver) internalRun() {
 func (d *Driver) GenerateSpecification() {
 	log.Info("Generating IAT and runtime specifications for all the functions")
 
+	if d.Configuration.TimeScale() != 1 {
+		log.Infof("Replaying the trace with a time scale of %.3f.", d.Configuration.TimeScale())
+		d.SpecificationGenerator.SetTimeScale(d.Configuration.TimeScale(), d.Configuration.LoaderConfiguration.ScaleRuntime)
+	}
+	d.SpecificationGenerator.SetArrivalModels(d.Configuration.LoaderConfiguration.TriggerArrivalModels)
+
 	for i, function := range d.Configuration.Functions {
 		// Equalising all the InvocationStats to the first function
 		if d.Configuration.LoaderConfiguration.DAGMode {
@@ -488,21 +674,72 @@ func (d *Driver) ReadOrWriteFileSpecification(writeIATsToFile bool, readIATsFrom
 	}
 }
 
-func (d *Driver) RunExperiment() {
+// RunExperiment deploys the functions, issues the invocations and cleans up the deployment. If the context gets
+// cancelled, no new invocations are issued and the results collected so far are written to the output files.
+func (d *Driver) RunExperiment(ctx context.Context) {
 	if d.Configuration.WithWarmup() {
 		trace.DoStaticTraceProfiling(d.Configuration.Functions)
 	}
 
 	trace.ApplyResourceLimits(d.Configuration.Functions, d.Configuration.LoaderConfiguration.CPULimit)
 
+	if d.Configuration.LoaderConfiguration.EnableCheckpointing && d.resumeFrom == nil {
+		d.writeSpecifications()
+	}
+
 	deployer := deployment.CreateDeployer(d.Configuration)
 	deployer.Deploy(d.Configuration)
 
+	// Clean up
+	defer deployer.Clean()
+	defer clients.CloseInvoker(d.Invoker)
+
+	if ctx.Err() != nil {
+		log.Warnf("The experiment has been cancelled before issuing any invocation.")
+		return
+	}
+
 	go failure.ScheduleFailure(d.Configuration.LoaderConfiguration.Platform, d.Configuration.FailureConfiguration)
 
+	experimentDone := make(chan struct{})
+	defer close(experimentDone)
+
+	go d.watchForCancellation(ctx, experimentDone)
+
+	controlServer := d.startControlServer()
+	defer stopServer(controlServer)
+
+	metricsServer := d.startMetricsServer()
+	defer stopServer(metricsServer)
+
 	// Generate load
 	d.internalRun()
+}
 
-	// Clean up
-	deployer.Clean()
+// watchForCancellation stops issuing new invocations once the context gets cancelled and cancels the invocations
+// still in flight if they have not completed within the grace period
+func (d *Driver) watchForCancellation(ctx context.Context, experimentDone <-chan struct{}) {
+	select {
+	case <-ctx.Done():
+	case <-experimentDone:
+		return
+	}
+
+	gracePeriod := time.Duration(d.Configuration.LoaderConfiguration.GracefulShutdownTimeoutSeconds) * time.Second
+	if gracePeriod <= 0 {
+		gracePeriod = common.DefaultGracefulShutdownTimeoutSeconds * time.Second
+	}
+
+	log.Warnf("The experiment has been cancelled. Waiting up to %v for the invocations in flight...", gracePeriod)
+	d.abortExperiment("The experiment has been cancelled.")
+
+	timer := time.NewTimer(gracePeriod)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		log.Warnf("Cancelling the invocations still in flight.")
+		d.cancelInvocations()
+	case <-experimentDone:
+	}
 }
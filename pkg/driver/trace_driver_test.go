@@ -26,9 +26,11 @@ package driver
 
 import (
 	"container/list"
+	"context"
 	"fmt"
 	"log"
 	"os"
+	"path/filepath"
 	"sync"
 	"testing"
 	"time"
@@ -267,7 +269,7 @@ func TestGlobalMetricsCollector(t *testing.T) {
 	collectorReady.Add(1)
 	collectorFinished.Add(1)
 
-	go metric.CreateGlobalMetricsCollector(driver.outputFilename("duration"), inputChannel, collectorReady, collectorFinished, totalIssuedChannel)
+	go metric.CreateGlobalMetricsCollector(metric.OpenCSVFile(driver.outputFilename("duration"), 0), inputChannel, nil, collectorReady, collectorFinished, totalIssuedChannel)
 	collectorReady.Wait()
 
 	bogusRecord := &metric.ExecutionRecord{
@@ -351,6 +353,7 @@ func TestDriverCompletely(t *testing.T) {
 		withWarmup            bool
 		traceGranularity      common.TraceGranularity
 		invocationStats       []int
+		invocationScheduler   string
 		expectedInvocations   int
 	}{
 		{
@@ -404,6 +407,14 @@ func TestDriverCompletely(t *testing.T) {
 			invocationStats:       []int{0, 5},
 			expectedInvocations:   5,
 		},
+		{
+			testName:              "centralized_scheduler",
+			experimentDurationMin: 1,
+			invocationStats:       []int{5},
+			traceGranularity:      common.MinuteGranularity,
+			invocationScheduler:   common.CentralizedScheduler,
+			expectedInvocations:   5,
+		},
 	}
 
 	for _, test := range tests {
@@ -422,9 +433,10 @@ func TestDriverCompletely(t *testing.T) {
 			}
 			driver.Configuration.TraceDuration = test.experimentDurationMin
 			driver.Configuration.TraceGranularity = test.traceGranularity
+			driver.Configuration.LoaderConfiguration.InvocationScheduler = test.invocationScheduler
 
 			driver.GenerateSpecification()
-			driver.RunExperiment()
+			driver.RunExperiment(context.Background())
 
 			f, err := os.Open(driver.outputFilename("duration"))
 			if err != nil {
@@ -484,6 +496,71 @@ func TestDriverCompletely(t *testing.T) {
 	}
 }
 
+func TestDriverCancellation(t *testing.T) {
+	driver := createTestDriver([]int{30})
+	driver.Configuration.LoaderConfiguration.OutputPathPrefix = filepath.Join(t.TempDir(), "test")
+	driver.Configuration.TraceGranularity = common.MinuteGranularity
+	driver.GenerateSpecification()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(5*time.Second, cancel)
+
+	start := time.Now()
+	driver.RunExperiment(ctx)
+
+	if elapsed := time.Since(start); elapsed > 30*time.Second {
+		t.Errorf("The experiment has not been stopped after cancellation - it took %v.", elapsed)
+	}
+
+	f, err := os.Open(driver.outputFilename("duration"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var records []metric.ExecutionRecordBase
+	if err = gocsv.UnmarshalFile(f, &records); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(records) == 0 || len(records) >= 30 {
+		t.Errorf("Unexpected number of records written after cancellation - %d.", len(records))
+	}
+
+	if _, err = os.Stat(driver.outputFilenameWithExtension("abort_reason", "txt")); err != nil {
+		t.Errorf("Reason for stopping the experiment has not been written - %v.", err)
+	}
+}
+
+func TestWatchForCancellation(t *testing.T) {
+	driver := createTestDriver([]int{1})
+	driver.Configuration.LoaderConfiguration.OutputPathPrefix = filepath.Join(t.TempDir(), "test")
+	driver.Configuration.LoaderConfiguration.GracefulShutdownTimeoutSeconds = 1
+
+	ctx, cancel := context.WithCancel(context.Background())
+	experimentDone := make(chan struct{})
+	defer close(experimentDone)
+
+	go driver.watchForCancellation(ctx, experimentDone)
+	cancel()
+
+	select {
+	case <-driver.abort:
+	case <-time.After(time.Second):
+		t.Fatal("No new invocations should be issued once the experiment is cancelled.")
+	}
+
+	if driver.invocationContext.Err() != nil {
+		t.Error("Invocations in flight should not be cancelled before the grace period expires.")
+	}
+
+	select {
+	case <-driver.invocationContext.Done():
+	case <-time.After(5 * time.Second):
+		t.Error("Invocations in flight have not been cancelled after the grace period.")
+	}
+}
+
 func TestHasMinuteExpired(t *testing.T) {
 	if !hasMinuteExpired(time.Now().Add(-2 * time.Minute)) {
 		t.Error("Time should have expired.")
@@ -495,27 +572,29 @@ func TestHasMinuteExpired(t *testing.T) {
 }
 
 func TestRequestedVsIssued(t *testing.T) {
-	if !isRequestTargetAchieved(100, 100*(1-common.RequestedVsIssuedWarnThreshold+0.05), common.RequestedVsIssued) {
+	driver := createTestDriver([]int{1})
+
+	if !driver.isRequestTargetAchieved(100, 100*(1-common.RequestedVsIssuedWarnThreshold+0.05), common.RequestedVsIssued) {
 		t.Error("Unexpected value received.")
 	}
 
-	if !isRequestTargetAchieved(100, 100*(1-common.RequestedVsIssuedWarnThreshold-0.05), common.RequestedVsIssued) {
+	if !driver.isRequestTargetAchieved(100, 100*(1-common.RequestedVsIssuedWarnThreshold-0.05), common.RequestedVsIssued) {
 		t.Error("Unexpected value received.")
 	}
 
-	if isRequestTargetAchieved(100, 100*(1-common.RequestedVsIssuedWarnThreshold-0.15), common.RequestedVsIssued) {
+	if driver.isRequestTargetAchieved(100, 100*(1-common.RequestedVsIssuedWarnThreshold-0.15), common.RequestedVsIssued) {
 		t.Error("Unexpected value received.")
 	}
 
-	if isRequestTargetAchieved(100, 100*(common.FailedWarnThreshold-0.1), common.IssuedVsFailed) {
+	if driver.isRequestTargetAchieved(100, 100*(common.FailedWarnThreshold-0.1), common.IssuedVsFailed) {
 		t.Error("Unexpected value received.")
 	}
 
-	if isRequestTargetAchieved(100, 100*(common.FailedWarnThreshold+0.05), common.IssuedVsFailed) {
+	if driver.isRequestTargetAchieved(100, 100*(common.FailedWarnThreshold+0.05), common.IssuedVsFailed) {
 		t.Error("Unexpected value received.")
 	}
 
-	if isRequestTargetAchieved(100, 100*(common.FailedTerminateThreshold-0.1), common.IssuedVsFailed) {
+	if driver.isRequestTargetAchieved(100, 100*(common.FailedTerminateThreshold-0.1), common.IssuedVsFailed) {
 		t.Error("Unexpected value received.")
 	}
 }
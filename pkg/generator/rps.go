@@ -178,3 +178,166 @@ func createRuntimeSpecification(count int, runtime, memory int) common.RuntimeSp
 
 	return result
 }
+
+// rpsSliceMicroseconds Resolution at which the request rate of load-profile stages is evaluated
+const rpsSliceMicroseconds = 1_000.0
+
+func validateRpsStages(stages []config.RpsStage) {
+	for i, stage := range stages {
+		switch stage.Type {
+		case common.RpsStageConstant, common.RpsStageRamp, common.RpsStageStep, common.RpsStageSpike, common.RpsStageSine:
+		default:
+			logrus.Fatalf("Unsupported type of RPS stage %d: '%s'.", i, stage.Type)
+		}
+
+		if stage.DurationSeconds <= 0 || stage.StartRps < 0 || stage.TargetRps < 0 {
+			logrus.Fatalf("RPS stage %d must have a positive duration and non-negative rates.", i)
+		}
+		if stage.Type == common.RpsStageSine && stage.PeriodSeconds <= 0 {
+			logrus.Fatalf("Sine RPS stage %d must have a positive period.", i)
+		}
+	}
+}
+
+// stageRps returns the request rate of the stage the given number of seconds after its beginning
+func stageRps(stage config.RpsStage, elapsed float64) float64 {
+	elapsed = math.Min(elapsed, float64(stage.DurationSeconds))
+	progress := elapsed / float64(stage.DurationSeconds)
+
+	switch stage.Type {
+	case common.RpsStageRamp:
+		return stage.StartRps + (stage.TargetRps-stage.StartRps)*progress
+	case common.RpsStageStep:
+		// the first step issues StartRps and the last one TargetRps, while a single step issues TargetRps
+		steps := common.MaxOf(stage.Steps, 1)
+		if steps == 1 {
+			return stage.TargetRps
+		}
+		step := common.MinOf(int(progress*float64(steps)), steps-1)
+
+		return stage.StartRps + (stage.TargetRps-stage.StartRps)*float64(step)/float64(steps-1)
+	case common.RpsStageSpike:
+		if elapsed >= float64(stage.SpikeStartSeconds) && elapsed < float64(stage.SpikeStartSeconds+stage.SpikeDurationSeconds) {
+			return stage.TargetRps
+		}
+
+		return stage.StartRps
+	case common.RpsStageSine:
+		return stage.StartRps + (stage.TargetRps-stage.StartRps)*(1-math.Cos(2*math.Pi*elapsed/float64(stage.PeriodSeconds)))/2
+	default:
+		return stage.TargetRps
+	}
+}
+
+// rpsAt returns the request rate at the given time in microseconds, where the rate at the end of the last stage is kept
+// until the end of the experiment
+func rpsAt(stages []config.RpsStage, t float64) float64 {
+	elapsed := t / 1_000_000
+
+	for i, stage := range stages {
+		if elapsed < float64(stage.DurationSeconds) || i == len(stages)-1 {
+			return stageRps(stage, elapsed)
+		}
+
+		elapsed -= float64(stage.DurationSeconds)
+	}
+
+	return 0
+}
+
+// generateStagedArrivals returns the times in microseconds at which a share of the load given by the stages arrives.
+// The rate is considered constant within each slice, in which the arrivals are spread evenly.
+func generateStagedArrivals(experimentDuration int, stages []config.RpsStage, share float64) []float64 {
+	var arrivals []float64
+
+	// expected number of arrivals since the beginning of the experiment
+	accumulated := 0.0
+	totalExperimentDurationUs := float64(experimentDuration) * 60_000_000
+
+	for t := 0.0; t < totalExperimentDurationUs; t += rpsSliceMicroseconds {
+		rate := share * rpsAt(stages, t) / 1_000_000 // per μs
+		if rate <= 0 {
+			continue
+		}
+
+		next := accumulated + rate*rpsSliceMicroseconds
+		for k := math.Ceil(accumulated); k < next; k++ {
+			// arrivals are rounded to whole microseconds, which is the precision of the driver
+			if arrival := math.Round(t + (k-accumulated)/rate); arrival < totalExperimentDurationUs {
+				arrivals = append(arrivals, arrival)
+			}
+		}
+
+		accumulated = next
+	}
+
+	return arrivals
+}
+
+func arrivalsToIAT(arrivals []float64) common.IATArray {
+	iat := make(common.IATArray, 0, len(arrivals))
+
+	previous := 0.0
+	for _, arrival := range arrivals {
+		iat = append(iat, arrival-previous)
+		previous = arrival
+	}
+
+	return iat
+}
+
+// GenerateStagedWarmStartFunction generates the IATs of a single function receiving the given share of the load
+// described by the stages
+func GenerateStagedWarmStartFunction(experimentDuration int, stages []config.RpsStage, share float64) (common.IATArray, []int) {
+	validateRpsStages(stages)
+
+	iat := arrivalsToIAT(generateStagedArrivals(experimentDuration, stages, share))
+	if len(iat) == 0 {
+		return nil, countNumberOfInvocationsPerMinute(experimentDuration, nil)
+	}
+
+	return iat, countNumberOfInvocationsPerMinute(experimentDuration, iat)
+}
+
+// GenerateStagedColdStartFunctions spreads the given share of the load described by the stages over functions, such
+// that every invocation hits a function that has not been invoked for at least the cooldown period
+func GenerateStagedColdStartFunctions(experimentDuration int, stages []config.RpsStage, share float64, cooldownSeconds int) ([]common.IATArray, [][]int) {
+	validateRpsStages(stages)
+
+	cooldown := float64(cooldownSeconds) * 1_000_000
+
+	var functionArrivals [][]float64
+	// indices of the functions ordered by the time of their last invocation
+	var leastRecentlyUsed []int
+
+	for _, arrival := range generateStagedArrivals(experimentDuration, stages, share) {
+		function := len(functionArrivals)
+
+		if len(leastRecentlyUsed) > 0 {
+			candidate := leastRecentlyUsed[0]
+			if last := functionArrivals[candidate][len(functionArrivals[candidate])-1]; arrival-last >= cooldown {
+				function = candidate
+				leastRecentlyUsed = leastRecentlyUsed[1:]
+			}
+		}
+
+		if function == len(functionArrivals) {
+			functionArrivals = append(functionArrivals, nil)
+		}
+
+		functionArrivals[function] = append(functionArrivals[function], arrival)
+		leastRecentlyUsed = append(leastRecentlyUsed, function)
+	}
+
+	var functions []common.IATArray
+	var countResult [][]int
+
+	for _, arrivals := range functionArrivals {
+		iat := arrivalsToIAT(arrivals)
+
+		functions = append(functions, iat)
+		countResult = append(countResult, countNumberOfInvocationsPerMinute(experimentDuration, iat))
+	}
+
+	return functions, countResult
+}
@@ -2,6 +2,7 @@ package generator
 
 import (
 	"github.com/vhive-serverless/loader/pkg/common"
+	"github.com/vhive-serverless/loader/pkg/config"
 	"math"
 	"testing"
 )
@@ -404,3 +405,125 @@ func TestColdStartMatrix(t *testing.T) {
 		})
 	}
 }
+
+func TestStagedRps(t *testing.T) {
+	stages := []config.RpsStage{
+		{Type: common.RpsStageRamp, DurationSeconds: 10, StartRps: 0, TargetRps: 10},
+		{Type: common.RpsStageStep, DurationSeconds: 10, StartRps: 10, TargetRps: 30, Steps: 2},
+		{Type: common.RpsStageSpike, DurationSeconds: 10, StartRps: 5, TargetRps: 50, SpikeStartSeconds: 4, SpikeDurationSeconds: 2},
+		{Type: common.RpsStageSine, DurationSeconds: 20, StartRps: 10, TargetRps: 20, PeriodSeconds: 20},
+	}
+
+	tests := []struct {
+		time        float64 // seconds
+		expectedRps float64
+	}{
+		{time: 0, expectedRps: 0},
+		{time: 5, expectedRps: 5},
+		{time: 12, expectedRps: 10},
+		{time: 17, expectedRps: 30},
+		{time: 21, expectedRps: 5},
+		{time: 25, expectedRps: 50},
+		{time: 30, expectedRps: 10},
+		{time: 40, expectedRps: 20},
+		// the rate at the end of the last stage is kept
+		{time: 100, expectedRps: 10},
+	}
+
+	for _, test := range tests {
+		if rps := rpsAt(stages, test.time*1_000_000); math.Abs(rps-test.expectedRps) > 1e-9 {
+			t.Errorf("Unexpected rate after %.0f s - got %f, expected %f.", test.time, rps, test.expectedRps)
+		}
+	}
+}
+
+func TestStepStageRps(t *testing.T) {
+	tests := []struct {
+		testName    string
+		steps       int
+		expectedRps []float64 // at every second of the stage
+	}{
+		{
+			testName:    "single_step",
+			steps:       1,
+			expectedRps: []float64{30, 30, 30, 30},
+		},
+		{
+			testName:    "two_steps",
+			steps:       2,
+			expectedRps: []float64{10, 10, 30, 30},
+		},
+		{
+			testName:    "three_steps",
+			steps:       3,
+			expectedRps: []float64{10, 20, 20, 30},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.testName, func(t *testing.T) {
+			stage := config.RpsStage{Type: common.RpsStageStep, DurationSeconds: 4, StartRps: 10, TargetRps: 30, Steps: test.steps}
+
+			for second, expectedRps := range test.expectedRps {
+				if rps := stageRps(stage, float64(second)+0.5); math.Abs(rps-expectedRps) > 1e-9 {
+					t.Errorf("Unexpected rate after %d.5 s - got %f, expected %f.", second, rps, expectedRps)
+				}
+			}
+		})
+	}
+}
+
+func TestStagedWarmStartFunction(t *testing.T) {
+	stages := []config.RpsStage{
+		{Type: common.RpsStageRamp, DurationSeconds: 60, StartRps: 0, TargetRps: 20},
+		{Type: common.RpsStageConstant, DurationSeconds: 60, TargetRps: 20},
+	}
+
+	iat, count := GenerateStagedWarmStartFunction(2, stages, 0.5)
+
+	// a linear ramp to 10 RPS over a minute amounts to 300 invocations, followed by a minute at 10 RPS
+	if len(count) != 2 || math.Abs(float64(count[0]-300)) > 1 || math.Abs(float64(count[1]-600)) > 1 {
+		t.Errorf("Unexpected number of invocations per minute - %v.", count)
+	}
+	if len(iat) != count[0]+count[1] {
+		t.Errorf("Number of IATs does not match the number of invocations - %d.", len(iat))
+	}
+
+	// the rate increases over the ramp, hence the IATs decrease
+	if iat[10] <= iat[200] {
+		t.Errorf("IATs do not follow the ramp - %f, %f.", iat[10], iat[200])
+	}
+	if math.Abs(iat[len(iat)-1]-100_000) > 1 {
+		t.Errorf("Unexpected IAT at a constant rate of 10 RPS - %f.", iat[len(iat)-1])
+	}
+}
+
+func TestStagedColdStartFunctions(t *testing.T) {
+	stages := []config.RpsStage{
+		{Type: common.RpsStageStep, DurationSeconds: 60, StartRps: 0, TargetRps: 4, Steps: 2},
+	}
+	cooldownSeconds := 10
+
+	functions, count := GenerateStagedColdStartFunctions(1, stages, 0.5, cooldownSeconds)
+
+	total := 0
+	for i, iat := range functions {
+		// the first IAT is the offset of the first invocation
+		for j := 1; j < len(iat); j++ {
+			if iat[j] < float64(cooldownSeconds)*1_000_000 {
+				t.Errorf("Function %d has been invoked before the end of the cooldown period - %f.", i, iat[j])
+			}
+		}
+
+		total += count[i][0]
+	}
+
+	// no invocations for 30 seconds and 2 RPS for another 30 seconds
+	if total != 60 {
+		t.Errorf("Unexpected number of cold-start invocations - %d.", total)
+	}
+	// the pool of functions grows with the rate
+	if len(functions) != 20 {
+		t.Errorf("Unexpected number of cold-start functions - %d.", len(functions))
+	}
+}
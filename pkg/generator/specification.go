@@ -25,7 +25,9 @@
 package generator
 
 import (
+	"math"
 	"math/rand"
+	"strings"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/vhive-serverless/loader/pkg/common"
@@ -34,12 +36,67 @@ import (
 type SpecificationGenerator struct {
 	iatRand  *rand.Rand
 	specRand *rand.Rand
+
+	timeScale    float64
+	scaleRuntime bool
+
+	// arrivalModels Arrival model per trigger, the configured IAT distribution applies to the other triggers
+	arrivalModels map[string]common.ArrivalModel
+}
+
+// defaultArrivalModels Timers fire on fixed schedules, while queue, event and storage triggers deliver their messages
+// in batches
+var defaultArrivalModels = map[string]common.ArrivalModel{
+	"timer":   {Model: common.PeriodicArrivals},
+	"http":    {Model: common.DistributionArrivals},
+	"queue":   {Model: common.BurstArrivals, BurstSize: common.DefaultBurstSize},
+	"event":   {Model: common.BurstArrivals, BurstSize: common.DefaultBurstSize},
+	"storage": {Model: common.BurstArrivals, BurstSize: common.DefaultBurstSize},
 }
 
 func NewSpecificationGenerator(seed int64) *SpecificationGenerator {
+	arrivalModels := make(map[string]common.ArrivalModel)
+	for trigger, model := range defaultArrivalModels {
+		arrivalModels[trigger] = model
+	}
+
 	return &SpecificationGenerator{
 		iatRand:  rand.New(rand.NewSource(seed)),
 		specRand: rand.New(rand.NewSource(seed)),
+
+		timeScale: 1,
+
+		arrivalModels: arrivalModels,
+	}
+}
+
+// SetTimeScale makes the generator compress (timeScale < 1) or stretch (timeScale > 1) the IAT timeline and,
+// optionally, the runtime of the generated invocations
+func (s *SpecificationGenerator) SetTimeScale(timeScale float64, scaleRuntime bool) {
+	if timeScale <= 0 {
+		log.Fatal("Time scale has to be positive.")
+	}
+
+	s.timeScale = timeScale
+	s.scaleRuntime = scaleRuntime
+}
+
+// SetArrivalModels overrides the arrival models of the given triggers
+func (s *SpecificationGenerator) SetArrivalModels(arrivalModels map[string]common.ArrivalModel) {
+	for trigger, model := range arrivalModels {
+		switch model.Model {
+		case common.PeriodicArrivals, common.DistributionArrivals:
+		case common.BurstArrivals:
+			if model.BurstSize == 0 {
+				model.BurstSize = common.DefaultBurstSize
+			} else if model.BurstSize < 0 {
+				log.Fatalf("Burst size of the %s trigger has to be positive.", trigger)
+			}
+		default:
+			log.Fatalf("Unsupported arrival model %s of the %s trigger.", model.Model, trigger)
+		}
+
+		s.arrivalModels[strings.ToLower(trigger)] = model
 	}
 }
 
@@ -47,6 +104,25 @@ func NewSpecificationGenerator(seed int64) *SpecificationGenerator {
 // IAT GENERATION
 //////////////////////////////////////////////////
 
+// arrivalProcess returns the IAT distribution, the shift and the burst size the invocations of a function with the
+// given trigger are generated with
+func (s *SpecificationGenerator) arrivalProcess(trigger string, iatDistribution common.IatDistribution, shiftIAT bool) (common.IatDistribution, bool, int) {
+	model, ok := s.arrivalModels[trigger]
+	if !ok {
+		return iatDistribution, shiftIAT, 1
+	}
+
+	switch model.Model {
+	case common.PeriodicArrivals:
+		// aligned with the beginning of the minute, as cron schedules are
+		return common.Equidistant, false, 1
+	case common.BurstArrivals:
+		return iatDistribution, shiftIAT, model.BurstSize
+	default:
+		return iatDistribution, shiftIAT, 1
+	}
+}
+
 // generateIATPerGranularity generates IAT for one minute based on given number of invocations and the given distribution
 func (s *SpecificationGenerator) generateIATPerGranularity(numberOfInvocations int, iatDistribution common.IatDistribution, shiftIAT bool, granularity common.TraceGranularity) ([]float64, float64) {
 	if numberOfInvocations == 0 {
@@ -131,6 +207,34 @@ func (s *SpecificationGenerator) generateIATPerGranularity(numberOfInvocations i
 	return iatResult, totalDuration
 }
 
+// generateBurstIATPerGranularity draws bursts of at most burstSize invocations from the given distribution. The
+// invocations of a burst arrive at once, i.e., with an IAT of zero.
+func (s *SpecificationGenerator) generateBurstIATPerGranularity(numberOfInvocations int, burstSize int, iatDistribution common.IatDistribution, shiftIAT bool, granularity common.TraceGranularity) ([]float64, float64) {
+	numberOfBursts := (numberOfInvocations + burstSize - 1) / burstSize
+
+	burstIAT, totalDuration := s.generateIATPerGranularity(numberOfBursts, iatDistribution, shiftIAT, granularity)
+	if numberOfInvocations == 0 {
+		return burstIAT, totalDuration
+	}
+
+	// the first element is the time until the first burst and every other one the time after a burst
+	iatResult := []float64{burstIAT[0]}
+	for i := 1; i < len(burstIAT); i++ {
+		// invocations are split evenly across the bursts
+		size := numberOfInvocations / numberOfBursts
+		if i <= numberOfInvocations%numberOfBursts {
+			size++
+		}
+
+		for j := 1; j < size; j++ {
+			iatResult = append(iatResult, 0)
+		}
+		iatResult = append(iatResult, burstIAT[i])
+	}
+
+	return iatResult, totalDuration
+}
+
 func getBlankTimeUnit(granularity common.TraceGranularity) float64 {
 	if granularity == common.MinuteGranularity {
 		return 60_000_000
@@ -141,7 +245,7 @@ func getBlankTimeUnit(granularity common.TraceGranularity) float64 {
 
 // GenerateIAT generates IAT according to the given distribution. Number of minutes is the length of invocationsPerMinute array
 func (s *SpecificationGenerator) generateIAT(invocationsPerMinute []int, iatDistribution common.IatDistribution,
-	shiftIAT bool, granularity common.TraceGranularity) (common.IATArray, []int, common.ProbabilisticDuration) {
+	shiftIAT bool, granularity common.TraceGranularity, burstSize int) (common.IATArray, []int, common.ProbabilisticDuration) {
 
 	var IAT = []float64{0.0}
 	var perMinuteCount []int
@@ -149,7 +253,13 @@ func (s *SpecificationGenerator) generateIAT(invocationsPerMinute []int, iatDist
 
 	numberOfMinutes := len(invocationsPerMinute)
 	for i := 0; i < numberOfMinutes; i++ {
-		minuteIAT, duration := s.generateIATPerGranularity(invocationsPerMinute[i], iatDistribution, shiftIAT, granularity)
+		var minuteIAT []float64
+		var duration float64
+		if burstSize > 1 {
+			minuteIAT, duration = s.generateBurstIATPerGranularity(invocationsPerMinute[i], burstSize, iatDistribution, shiftIAT, granularity)
+		} else {
+			minuteIAT, duration = s.generateIATPerGranularity(invocationsPerMinute[i], iatDistribution, shiftIAT, granularity)
+		}
 
 		IAT[len(IAT)-1] += minuteIAT[0]
 		IAT = append(IAT, minuteIAT[1:]...)
@@ -166,13 +276,25 @@ func (s *SpecificationGenerator) GenerateInvocationData(function *common.Functio
 	invocationsPerMinute := function.InvocationStats.Invocations
 
 	// Generating IAT
-	iat, perMinuteCount, rawDuration := s.generateIAT(invocationsPerMinute, iatDistribution, shiftIAT, granularity)
+	iatDistribution, shiftIAT, burstSize := s.arrivalProcess(function.InvocationStats.Trigger, iatDistribution, shiftIAT)
+	iat, perMinuteCount, rawDuration := s.generateIAT(invocationsPerMinute, iatDistribution, shiftIAT, granularity, burstSize)
+	// scaling all the IATs by the same factor preserves the order of invocations and the minute they belong to
+	if s.timeScale != 1 {
+		for i := 0; i < len(iat); i++ {
+			iat[i] *= s.timeScale
+		}
+	}
 
 	// Generating runtime specifications
 	var runtimeArray common.RuntimeSpecificationArray
 	for i := 0; i < len(perMinuteCount); i++ {
 		for j := 0; j < perMinuteCount[i]; j++ {
-			runtimeArray = append(runtimeArray, s.generateExecutionSpecs(function))
+			spec := s.generateExecutionSpecs(function)
+			if s.scaleRuntime {
+				spec.Runtime = scaleRuntime(spec.Runtime, s.timeScale)
+			}
+
+			runtimeArray = append(runtimeArray, spec)
 		}
 	}
 
@@ -188,6 +310,12 @@ func (s *SpecificationGenerator) GenerateInvocationData(function *common.Functio
 // RUNTIME AND MEMORY GENERATION
 //////////////////////////////////////////////////
 
+func scaleRuntime(runtime int, timeScale float64) int {
+	scaled := int(math.Round(float64(runtime) * timeScale))
+
+	return common.MinOf(common.MaxExecTimeMilli, common.MaxOf(common.MinExecTimeMilli, scaled))
+}
+
 // Choose a random number in between. Not thread safe.
 func randIntBetween(gen *rand.Rand, min, max float64) int {
 	intMin, intMax := int(min), int(max)
@@ -258,6 +386,24 @@ func GenerateMemorySpec(gen *rand.Rand, memQtl float64, memStats *common.Functio
 	return memory
 }
 
+// GeneratePayloadSpec is not thread safe as it could cause non-repeatable spec generation
+func GeneratePayloadSpec(gen *rand.Rand, qtl float64, pct0, pct25, pct50, pct75, pct100 float64) (size int) {
+	switch {
+	case qtl == 0:
+		size = int(pct0)
+	case qtl <= 0.25:
+		size = randIntBetween(gen, pct0, pct25)
+	case qtl <= 0.50:
+		size = randIntBetween(gen, pct25, pct50)
+	case qtl <= 0.75:
+		size = randIntBetween(gen, pct50, pct75)
+	case qtl < 1:
+		size = randIntBetween(gen, pct75, pct100)
+	}
+
+	return common.MinOf(common.MaxPayloadSizeBytes, common.MaxOf(0, size))
+}
+
 func (s *SpecificationGenerator) generateExecutionSpecs(function *common.Function) common.RuntimeSpecification {
 	runStats, memStats := function.RuntimeStats, function.MemoryStats
 	if runStats.Count <= 0 || memStats.Count <= 0 {
@@ -268,8 +414,18 @@ func (s *SpecificationGenerator) generateExecutionSpecs(function *common.Functio
 	runtime := common.MinOf(common.MaxExecTimeMilli, common.MaxOf(common.MinExecTimeMilli, GenerateExecuteSpec(s.specRand, runQtl, runStats)))
 	memory := common.MinOf(common.MaxMemQuotaMib, common.MaxOf(common.MinMemQuotaMib, GenerateMemorySpec(s.specRand, memQtl, memStats)))
 
-	return common.RuntimeSpecification{
+	spec := common.RuntimeSpecification{
 		Runtime: runtime,
 		Memory:  memory,
 	}
+
+	// the quantiles are only drawn when payloads are modelled, so that the other specifications remain reproducible
+	if p := function.PayloadStats; p != nil {
+		spec.RequestSize = GeneratePayloadSpec(s.specRand, s.specRand.Float64(), p.RequestPercentile0,
+			p.RequestPercentile25, p.RequestPercentile50, p.RequestPercentile75, p.RequestPercentile100)
+		spec.ResponseSize = GeneratePayloadSpec(s.specRand, s.specRand.Float64(), p.ResponsePercentile0,
+			p.ResponsePercentile25, p.ResponsePercentile50, p.ResponsePercentile75, p.ResponsePercentile100)
+	}
+
+	return spec
 }
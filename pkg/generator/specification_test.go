@@ -29,6 +29,7 @@ import (
 	"math"
 	"os"
 	"os/exec"
+	"reflect"
 	"strconv"
 	"sync"
 	"testing"
@@ -836,3 +837,150 @@ func TestGenerateExecutionSpecifications(t *testing.T) {
 		})
 	}
 }
+
+func TestGenerateInvocationDataWithTimeScale(t *testing.T) {
+	var seed int64 = 123456789
+
+	function := testFunction
+	function.InvocationStats = &common.FunctionInvocationStats{Invocations: []int{5, 0, 3}}
+
+	reference := NewSpecificationGenerator(seed).GenerateInvocationData(&function, common.Exponential, true, common.MinuteGranularity)
+
+	sg := NewSpecificationGenerator(seed)
+	sg.SetTimeScale(0.1, true)
+	scaled := sg.GenerateInvocationData(&function, common.Exponential, true, common.MinuteGranularity)
+
+	if len(scaled.IAT) != len(reference.IAT) {
+		t.Fatalf("Unexpected number of IATs - got %d, expected %d.", len(scaled.IAT), len(reference.IAT))
+	}
+	for i := 0; i < len(reference.IAT); i++ {
+		if math.Abs(scaled.IAT[i]-reference.IAT[i]*0.1) > 1e-6 {
+			t.Errorf("IAT %d has not been scaled - got %f, reference %f.", i, scaled.IAT[i], reference.IAT[i])
+		}
+	}
+
+	// invocations still belong to the same minutes of the trace
+	for i := 0; i < len(reference.PerMinuteCount); i++ {
+		if scaled.PerMinuteCount[i] != reference.PerMinuteCount[i] {
+			t.Errorf("Unexpected invocation count in minute %d - got %d, expected %d.", i, scaled.PerMinuteCount[i], reference.PerMinuteCount[i])
+		}
+	}
+
+	for i := 0; i < len(reference.RuntimeSpecification); i++ {
+		expected := scaleRuntime(reference.RuntimeSpecification[i].Runtime, 0.1)
+		if scaled.RuntimeSpecification[i].Runtime != expected || scaled.RuntimeSpecification[i].Memory != reference.RuntimeSpecification[i].Memory {
+			t.Errorf("Unexpected runtime specification %d - got %v, expected runtime %d.", i, scaled.RuntimeSpecification[i], expected)
+		}
+	}
+}
+
+func TestGenerateExecutionSpecificationsWithPayload(t *testing.T) {
+	function := testFunction
+	function.InvocationStats = &common.FunctionInvocationStats{
+		Invocations: []int{100},
+	}
+	function.PayloadStats = &common.FunctionPayloadStats{
+		RequestPercentile0:    10,
+		RequestPercentile25:   100,
+		RequestPercentile50:   1_000,
+		RequestPercentile75:   10_000,
+		RequestPercentile100:  100_000,
+		ResponsePercentile0:   0,
+		ResponsePercentile25:  0,
+		ResponsePercentile50:  50,
+		ResponsePercentile75:  500,
+		ResponsePercentile100: 2 * common.MaxPayloadSizeBytes,
+	}
+
+	spec := NewSpecificationGenerator(123456789).GenerateInvocationData(&function, common.Equidistant, false, common.MinuteGranularity)
+	reference := NewSpecificationGenerator(123456789).GenerateInvocationData(&function, common.Equidistant, false, common.MinuteGranularity)
+
+	for i, runtimeSpec := range spec.RuntimeSpecification {
+		if runtimeSpec != reference.RuntimeSpecification[i] {
+			t.Errorf("Payload sizes are not reproducible - got %v and %v.", runtimeSpec, reference.RuntimeSpecification[i])
+		}
+		if runtimeSpec.RequestSize < 10 || runtimeSpec.RequestSize > 100_000 {
+			t.Errorf("Request size %d outside of the distribution.", runtimeSpec.RequestSize)
+		}
+		if runtimeSpec.ResponseSize < 0 || runtimeSpec.ResponseSize > common.MaxPayloadSizeBytes {
+			t.Errorf("Response size %d outside of the distribution.", runtimeSpec.ResponseSize)
+		}
+	}
+
+	function.PayloadStats = nil
+	for _, runtimeSpec := range NewSpecificationGenerator(123456789).GenerateInvocationData(&function, common.Equidistant, false, common.MinuteGranularity).RuntimeSpecification {
+		if runtimeSpec.RequestSize != 0 || runtimeSpec.ResponseSize != 0 {
+			t.Errorf("Payload sizes generated for a function without payload statistics - %v.", runtimeSpec)
+		}
+	}
+}
+
+func TestGenerateInvocationDataWithTriggers(t *testing.T) {
+	function := testFunction
+
+	// timers fire at the beginning of the minute and evenly within it, whatever the configured distribution
+	function.InvocationStats = &common.FunctionInvocationStats{Trigger: "timer", Invocations: []int{3, 0, 1}}
+	spec := NewSpecificationGenerator(123456789).GenerateInvocationData(&function, common.Exponential, true, common.MinuteGranularity)
+
+	expected := []float64{0, 20_000_000, 20_000_000, 80_000_000}
+	if len(spec.IAT) != len(expected) {
+		t.Fatalf("Unexpected IATs of a timer - got %v, expected %v.", spec.IAT, expected)
+	}
+	for i := range expected {
+		if math.Abs(spec.IAT[i]-expected[i]) > 1e-6 {
+			t.Errorf("Unexpected IATs of a timer - got %v, expected %v.", spec.IAT, expected)
+			break
+		}
+	}
+
+	// queue messages arrive in bursts of at most 16 invocations
+	function.InvocationStats = &common.FunctionInvocationStats{Trigger: "queue", Invocations: []int{40, 5}}
+	spec = NewSpecificationGenerator(123456789).GenerateInvocationData(&function, common.Exponential, false, common.MinuteGranularity)
+
+	if len(spec.IAT) != 45 || spec.PerMinuteCount[0] != 40 || spec.PerMinuteCount[1] != 5 || len(spec.RuntimeSpecification) != 45 {
+		t.Fatalf("Unexpected number of invocations - %d IATs, %v per minute.", len(spec.IAT), spec.PerMinuteCount)
+	}
+
+	// bursts of 14, 13 and 13 invocations in the first minute and one burst of 5 in the second minute
+	var burstSizes []int
+	for i := 0; i < len(spec.IAT); i++ {
+		if i == 0 || spec.IAT[i] > 0 {
+			burstSizes = append(burstSizes, 0)
+		}
+		burstSizes[len(burstSizes)-1]++
+	}
+	if !reflect.DeepEqual(burstSizes, []int{14, 13, 13, 5}) {
+		t.Errorf("Unexpected burst sizes %v.", burstSizes)
+	}
+
+	// the configured distribution applies to overridden and unknown triggers
+	sg := NewSpecificationGenerator(123456789)
+	sg.SetArrivalModels(map[string]common.ArrivalModel{
+		"Timer": {Model: common.DistributionArrivals},
+		"queue": {Model: common.BurstArrivals, BurstSize: 5},
+	})
+
+	function.InvocationStats = &common.FunctionInvocationStats{Trigger: "timer", Invocations: []int{3}}
+	if spec = sg.GenerateInvocationData(&function, common.Exponential, false, common.MinuteGranularity); spec.IAT[1] == 20_000_000 {
+		t.Errorf("Timer has not been overridden - got %v.", spec.IAT)
+	}
+
+	function.InvocationStats = &common.FunctionInvocationStats{Trigger: "queue", Invocations: []int{10}}
+	spec = sg.GenerateInvocationData(&function, common.Exponential, false, common.MinuteGranularity)
+	zeroIATs := 0
+	for _, iat := range spec.IAT[1:] {
+		if iat == 0 {
+			zeroIATs++
+		}
+	}
+	if zeroIATs != 8 {
+		t.Errorf("Expected two bursts of five invocations, got %v.", spec.IAT)
+	}
+
+	function.InvocationStats = &common.FunctionInvocationStats{Trigger: "orchestration", Invocations: []int{3}}
+	reference := NewSpecificationGenerator(123456789).GenerateInvocationData(&function, common.Exponential, false, common.MinuteGranularity)
+	function.InvocationStats = &common.FunctionInvocationStats{Invocations: []int{3}}
+	if spec = NewSpecificationGenerator(123456789).GenerateInvocationData(&function, common.Exponential, false, common.MinuteGranularity); !reflect.DeepEqual(spec.IAT, reference.IAT) {
+		t.Errorf("Unknown triggers should follow the configured distribution - got %v, expected %v.", reference.IAT, spec.IAT)
+	}
+}
@@ -1,12 +1,15 @@
 package metric
 
 import (
+	"bufio"
 	"encoding/csv"
 	"github.com/gocarina/gocsv"
 	log "github.com/sirupsen/logrus"
 	"github.com/vhive-serverless/loader/pkg/common"
+	"io"
 	"math"
 	"os"
+	"reflect"
 	"sync"
 )
 
@@ -25,7 +28,103 @@ func RunCSVWriter(records chan interface{}, filename string, writerDone *sync.Wa
 	writerDone.Done()
 }
 
-func CreateGlobalMetricsCollector(filename string, collector chan *ExecutionRecord,
+// CSVFile writes records to a CSV file one at a time, so that the file can be flushed at a record boundary at any point
+// of the experiment and appended to once an interrupted experiment is resumed
+type CSVFile struct {
+	mutex sync.Mutex
+
+	file   *os.File
+	buffer *bufio.Writer
+	writer *gocsv.SafeCSVWriter
+
+	empty  bool
+	closed bool
+	offset int64
+}
+
+// OpenCSVFile creates the file or, if the offset is positive, keeps its content up to the offset and appends to it
+func OpenCSVFile(filename string, offset int64) *CSVFile {
+	log.Debugf("Starting writer for %s", filename)
+
+	flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	if offset > 0 {
+		flags = os.O_CREATE | os.O_WRONLY
+	} else {
+		offset = 0
+	}
+
+	file, err := os.OpenFile(filename, flags, 0644)
+	common.Check(err)
+
+	if offset > 0 {
+		common.Check(file.Truncate(offset))
+		_, err = file.Seek(offset, io.SeekStart)
+		common.Check(err)
+	}
+
+	// records are buffered here, as the CSV writer is flushed after every record
+	buffer := bufio.NewWriter(file)
+
+	return &CSVFile{
+		file:   file,
+		buffer: buffer,
+		writer: gocsv.NewSafeCSVWriter(csv.NewWriter(buffer)),
+
+		empty:  offset == 0,
+		offset: offset,
+	}
+}
+
+func (f *CSVFile) Write(record interface{}) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	records := reflect.Append(reflect.MakeSlice(reflect.SliceOf(reflect.TypeOf(record)), 0, 1), reflect.ValueOf(record))
+
+	var err error
+	if f.empty {
+		err = gocsv.MarshalCSV(records.Interface(), f.writer)
+	} else {
+		err = gocsv.MarshalCSVWithoutHeaders(records.Interface(), f.writer)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	f.empty = false
+}
+
+// Flush writes the buffered records to the file and returns the size of the file
+func (f *CSVFile) Flush() int64 {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if !f.closed {
+		common.Check(f.buffer.Flush())
+
+		offset, err := f.file.Seek(0, io.SeekCurrent)
+		common.Check(err)
+		f.offset = offset
+	}
+
+	return f.offset
+}
+
+func (f *CSVFile) Close() {
+	f.Flush()
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if !f.closed {
+		f.closed = true
+		common.Check(f.file.Close())
+	}
+}
+
+// CreateGlobalMetricsCollector writes the records received from the collector channel to the output file. A channel
+// received from flushRequests is sent the size of the file once all the records received so far have been flushed.
+func CreateGlobalMetricsCollector(output *CSVFile, collector chan *ExecutionRecord, flushRequests chan chan int64,
 	signalReady *sync.WaitGroup, signalEverythingWritten *sync.WaitGroup, totalIssuedChannel chan int64) {
 
 	// NOTE: totalNumberOfInvocations is initialized to MaxInt64 not to allow collector to complete before
@@ -35,30 +134,22 @@ func CreateGlobalMetricsCollector(filename string, collector chan *ExecutionReco
 	var totalNumberOfInvocations int64 = math.MaxInt64
 	var currentlyWritten int64
 
-	file, err := os.Create(filename)
-	common.Check(err)
-	defer file.Close()
-
 	signalReady.Done()
 
-	records := make(chan interface{}, 100)
-	writerDone := sync.WaitGroup{}
-	writerDone.Add(1)
-	go RunCSVWriter(records, filename, &writerDone)
-
 	for {
 		select {
 		case record := <-collector:
-			records <- record
+			output.Write(record)
 
 			currentlyWritten++
 		case record := <-totalIssuedChannel:
 			totalNumberOfInvocations = record
+		case reply := <-flushRequests:
+			reply <- output.Flush()
 		}
 
 		if currentlyWritten == totalNumberOfInvocations {
-			close(records)
-			writerDone.Wait()
+			output.Close()
 			(*signalEverythingWritten).Done()
 
 			return
@@ -81,6 +81,48 @@ type ExecutionRecord struct {
 	UserCodeExecutionMs int64  `csv:"userCodeExecutionMs"`
 
 	TimeToGetResponseMs int64 `csv:"timeToGetResponseMs"`
+	// CompletionTime Time in microseconds since the epoch at which an asynchronous invocation has completed
+	CompletionTime int64 `csv:"completionTime"`
+
+	// ClientQueueingDelay Time in microseconds the invocation waited for an in-flight slot in the loader
+	ClientQueueingDelay int64 `csv:"clientQueueingDelay"`
+
+	// HttpStatusCode Status code of the response, or zero if no HTTP response has been received
+	HttpStatusCode int `csv:"httpStatusCode"`
+	// Attempt Index of the attempt, where zero stands for the first try and any higher value for a retry
+	Attempt int `csv:"attempt"`
+
+	// Scheduling times in microseconds since the epoch and the lag between them in microseconds
+	IntendedFireTime int64 `csv:"intendedFireTime"`
+	ActualFireTime   int64 `csv:"actualFireTime"`
+	SchedulingLag    int64 `csv:"schedulingLag"`
+
+	// Function Name of the invoked function, and RequestedMemory the memory requested for the invocation in MiB
+	Function        string `csv:"function"`
+	RequestedMemory uint32 `csv:"requestedMemory"`
+
+	// BytesSent and BytesReceived Size of the request and the response in bytes
+	BytesSent     int64 `csv:"bytesSent"`
+	BytesReceived int64 `csv:"bytesReceived"`
+
+	// StartType Whether the invocation has been served by a cold or a warm instance, or empty if the platform does not
+	// tell, and InitTime the time in microseconds the instance took to start in case of a cold start
+	StartType StartType `csv:"startType"`
+	InitTime  int64     `csv:"initTime"`
+	// WaitTime Time in microseconds the invocation has been queued within the platform, if the platform reports it
+	WaitTime int64 `csv:"waitTime"`
+}
+
+type SchedulingLagSummary struct {
+	Timestamp   int64 `csv:"timestamp"`
+	Second      int   `csv:"second"`
+	Invocations int   `csv:"invocations"`
+
+	// Measurements in microseconds
+	MeanLag   int64 `csv:"meanLag"`
+	MedianLag int64 `csv:"medianLag"`
+	P99Lag    int64 `csv:"p99Lag"`
+	MaxLag    int64 `csv:"maxLag"`
 }
 
 type DeploymentScale struct {
@@ -76,6 +76,16 @@ func createMemoryMap(runtime *[]common.FunctionMemoryStats) map[string]*common.F
 	return result
 }
 
+func createPayloadMap(payload *[]common.FunctionPayloadStats) map[string]*common.FunctionPayloadStats {
+	result := make(map[string]*common.FunctionPayloadStats)
+
+	for i := 0; i < len(*payload); i++ {
+		result[(*payload)[i].HashFunction] = &(*payload)[i]
+	}
+
+	return result
+}
+
 func createDirigentMetadataMap(metadata *[]common.DirigentMetadata) map[string]*common.DirigentMetadata {
 	result := make(map[string]*common.DirigentMetadata)
 
@@ -86,11 +96,12 @@ func createDirigentMetadataMap(metadata *[]common.DirigentMetadata) map[string]*
 	return result
 }
 
-func (p *AzureTraceParser) extractFunctions(invocations *[]common.FunctionInvocationStats, runtime *[]common.FunctionRuntimeStats, memory *[]common.FunctionMemoryStats) []*common.Function {
+func (p *AzureTraceParser) extractFunctions(invocations *[]common.FunctionInvocationStats, runtime *[]common.FunctionRuntimeStats, memory *[]common.FunctionMemoryStats, payload *[]common.FunctionPayloadStats) []*common.Function {
 	var result []*common.Function
 
 	runtimeByHashFunction := createRuntimeMap(runtime)
 	memoryByHashFunction := createMemoryMap(memory)
+	payloadByHashFunction := createPayloadMap(payload)
 
 	gen := rand.New(rand.NewSource(time.Now().UnixNano()))
 
@@ -103,6 +114,7 @@ func (p *AzureTraceParser) extractFunctions(invocations *[]common.FunctionInvoca
 			InvocationStats: &invocationStats,
 			RuntimeStats:    runtimeByHashFunction[invocationStats.HashFunction],
 			MemoryStats:     memoryByHashFunction[invocationStats.HashFunction],
+			PayloadStats:    payloadByHashFunction[invocationStats.HashFunction],
 
 			ColdStartBusyLoopMs: generator.ComputeBusyLoopPeriod(generator.GenerateMemorySpec(gen, gen.Float64(), memoryByHashFunction[invocationStats.HashFunction])),
 		}
@@ -117,12 +129,14 @@ func (p *AzureTraceParser) Parse() []*common.Function {
 	invocationPath := p.DirectoryPath + "/invocations.csv"
 	runtimePath := p.DirectoryPath + "/durations.csv"
 	memoryPath := p.DirectoryPath + "/memory.csv"
+	payloadPath := p.DirectoryPath + "/payload.csv"
 
 	invocationTrace := parseInvocationTrace(invocationPath, p.duration)
 	runtimeTrace := parseRuntimeTrace(runtimePath)
 	memoryTrace := parseMemoryTrace(memoryPath)
+	payloadTrace := parsePayloadTrace(payloadPath)
 
-	return p.extractFunctions(invocationTrace, runtimeTrace, memoryTrace)
+	return p.extractFunctions(invocationTrace, runtimeTrace, memoryTrace, payloadTrace)
 }
 
 func parseInvocationTrace(traceFile string, traceDuration int) *[]common.FunctionInvocationStats {
@@ -144,7 +158,7 @@ func parseInvocationTrace(traceFile string, traceDuration int) *[]common.Functio
 	reader := csv.NewReader(csvfile)
 
 	rowID := -1
-	hashOwnerIndex, hashAppIndex, hashFunctionIndex, invocationColumnIndex := -1, -1, -1, -1
+	hashOwnerIndex, hashAppIndex, hashFunctionIndex, triggerIndex, invocationColumnIndex := -1, -1, -1, -1, -1
 
 	for {
 		record, err := reader.Read()
@@ -166,7 +180,8 @@ func parseInvocationTrace(traceFile string, traceDuration int) *[]common.Functio
 					hashAppIndex = i
 				case "hashfunction":
 					hashFunctionIndex = i
-				case "trigger": //! Unused field.
+				case "trigger":
+					triggerIndex = i
 					invocationColumnIndex = i + 1
 				}
 			}
@@ -195,11 +210,16 @@ func parseInvocationTrace(traceFile string, traceDuration int) *[]common.Functio
 				totalInvocations[minute] = totalInvocations[minute] + num
 			}
 
+			trigger := ""
+			if triggerIndex != -1 {
+				trigger = strings.ToLower(record[triggerIndex])
+			}
+
 			result = append(result, common.FunctionInvocationStats{
 				HashOwner:    record[hashOwnerIndex],
 				HashApp:      record[hashAppIndex],
 				HashFunction: record[hashFunctionIndex],
-				Trigger:      record[invocationColumnIndex-1],
+				Trigger:      trigger,
 				Invocations:  invocations,
 			})
 		}
@@ -245,3 +265,26 @@ func parseMemoryTrace(traceFile string) *[]common.FunctionMemoryStats {
 
 	return &memory
 }
+
+// parsePayloadTrace reads the payload sizes of the functions, which are optional and only modelled if the trace
+// contains the file
+func parsePayloadTrace(traceFile string) *[]common.FunctionPayloadStats {
+	var payload []common.FunctionPayloadStats
+
+	f, err := os.Open(traceFile)
+	if os.IsNotExist(err) {
+		return &payload
+	} else if err != nil {
+		log.Fatal("Failed to open trace payload specification file.")
+	}
+	defer f.Close()
+
+	log.Infof("Parsing function payload trace: %s", traceFile)
+
+	err = gocsv.UnmarshalFile(f, &payload)
+	if err != nil {
+		log.Fatal("Failed to parse trace payload specification.")
+	}
+
+	return &payload
+}
@@ -27,6 +27,8 @@ package trace
 import (
 	"github.com/vhive-serverless/loader/pkg/common"
 	"math"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 )
@@ -64,6 +66,18 @@ func TestParseInvocationTrace(t *testing.T) {
 	}
 }
 
+func TestParseInvocationTraceWithoutTrigger(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "invocations.csv")
+	if err := os.WriteFile(path, []byte("HashOwner,HashApp,HashFunction,1,2\nowner,app,function,3,4\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	function := (*parseInvocationTrace(path, 2))[0]
+	if function.Trigger != "" || function.Invocations[0] != 3 || function.Invocations[1] != 4 {
+		t.Errorf("Unexpected data has been read - %+v", function)
+	}
+}
+
 func TestParseRuntimeTrace(t *testing.T) {
 	runtimeTrace := *parseRuntimeTrace("test_data/durations.csv")
 
@@ -119,6 +133,31 @@ func TestParseMemoryTrace(t *testing.T) {
 	}
 }
 
+func TestParsePayloadTrace(t *testing.T) {
+	payloadTrace := *parsePayloadTrace("test_data/payload.csv")
+
+	if len(payloadTrace) != 1 {
+		t.Error("Invalid payload trace provided.")
+	}
+
+	function := payloadTrace[0]
+
+	if function.HashFunction != "c13acdc7567b225971cef2416a3a2b03c8a4d8d154df48afe75834e2f5c59ddf" ||
+		!floatEqual(function.RequestPercentile0, 100) ||
+		!floatEqual(function.RequestPercentile50, 300) ||
+		!floatEqual(function.RequestPercentile100, 500) ||
+		!floatEqual(function.ResponsePercentile0, 1000) ||
+		!floatEqual(function.ResponsePercentile75, 4000) ||
+		!floatEqual(function.ResponsePercentile100, 5000) {
+
+		t.Error("Unexpected data has been read.")
+	}
+
+	if len(*parsePayloadTrace("test_data/missing.csv")) != 0 {
+		t.Error("Payload sizes read from a missing file.")
+	}
+}
+
 func TestParserWrapper(t *testing.T) {
 	parser := NewAzureParser("test_data", 10)
 	functions := parser.Parse()
@@ -129,7 +168,8 @@ func TestParserWrapper(t *testing.T) {
 	if !strings.HasPrefix(functions[0].Name, common.FunctionNamePrefix) ||
 		functions[0].InvocationStats == nil ||
 		functions[0].RuntimeStats == nil ||
-		functions[0].MemoryStats == nil {
+		functions[0].MemoryStats == nil ||
+		functions[0].PayloadStats == nil {
 
 		t.Error("Unexpected results.")
 	}
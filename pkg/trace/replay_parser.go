@@ -0,0 +1,203 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2023 EASL and the vHive community
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package trace
+
+import (
+	"encoding/csv"
+	"os"
+	"slices"
+	"sort"
+
+	"github.com/gocarina/gocsv"
+	log "github.com/sirupsen/logrus"
+	"github.com/vhive-serverless/loader/pkg/common"
+	"github.com/vhive-serverless/loader/pkg/generator"
+	mc "github.com/vhive-serverless/loader/pkg/metric"
+)
+
+// ScheduledInvocation is a single entry of a replay schedule file
+type ScheduledInvocation struct {
+	Function string `csv:"function"`
+	// Offset Start time in microseconds relative to the beginning of the experiment
+	Offset int64 `csv:"offset"`
+	// Runtime Requested runtime in milliseconds
+	Runtime int `csv:"runtime"`
+	// Memory Requested memory in MiB
+	Memory int `csv:"memory"`
+}
+
+// ReplayParser reads the invocations issued by a previous experiment, either from its `*_duration_*.csv` output file
+// or from a dedicated schedule file, and turns them into functions whose specification reproduces them one by one
+type ReplayParser struct {
+	FilePath string
+
+	duration int
+}
+
+func NewReplayParser(filePath string, totalDuration int) *ReplayParser {
+	return &ReplayParser{
+		FilePath: filePath,
+
+		duration: totalDuration,
+	}
+}
+
+func (p *ReplayParser) Parse() []*common.Function {
+	log.Infof("Parsing replay schedule %s (duration: %d min)", p.FilePath, p.duration)
+
+	file, err := os.Open(p.FilePath)
+	if err != nil {
+		log.Fatalf("Failed to open replay schedule %s - %v", p.FilePath, err)
+	}
+	defer file.Close()
+
+	header, err := csv.NewReader(file).Read()
+	if err != nil {
+		log.Fatalf("Failed to read the header of replay schedule %s - %v", p.FilePath, err)
+	}
+	if _, err = file.Seek(0, 0); err != nil {
+		log.Fatal(err)
+	}
+
+	var schedule []ScheduledInvocation
+	if slices.Contains(header, "invocationID") {
+		schedule = parseExecutionRecords(file)
+	} else if err = gocsv.UnmarshalFile(file, &schedule); err != nil {
+		log.Fatalf("Failed to parse replay schedule %s - %v", p.FilePath, err)
+	}
+
+	return p.extractFunctions(schedule)
+}
+
+// parseExecutionRecords converts the output of a previous experiment to a schedule, where retries are left out, as the
+// loader issues them on its own according to the retry policy
+func parseExecutionRecords(file *os.File) []ScheduledInvocation {
+	var records []mc.ExecutionRecord
+	if err := gocsv.UnmarshalFile(file, &records); err != nil {
+		log.Fatalf("Failed to parse execution records - %v", err)
+	}
+
+	var schedule []ScheduledInvocation
+	var start int64 = -1
+
+	for _, record := range records {
+		if record.Attempt != 0 {
+			continue
+		}
+		if record.Function == "" {
+			log.Fatal("Execution records do not contain the name of the invoked function. Replaying output of an older loader version requires a schedule file.")
+		}
+
+		// the time the invocation was due is preferred, so that the lag of the previous experiment is not replayed
+		startTime := record.IntendedFireTime
+		if startTime == 0 {
+			startTime = record.StartTime
+		}
+		if start == -1 || startTime < start {
+			start = startTime
+		}
+
+		schedule = append(schedule, ScheduledInvocation{
+			Function: record.Function,
+			Offset:   startTime,
+			Runtime:  int(record.RequestedDuration / 1e3),
+			Memory:   int(record.RequestedMemory),
+		})
+	}
+
+	for i := range schedule {
+		schedule[i].Offset -= start
+	}
+
+	return schedule
+}
+
+func (p *ReplayParser) extractFunctions(schedule []ScheduledInvocation) []*common.Function {
+	sort.SliceStable(schedule, func(i, j int) bool {
+		return schedule[i].Offset < schedule[j].Offset
+	})
+
+	var result []*common.Function
+	functionByName := make(map[string]*common.Function)
+	previousOffset := make(map[string]int64)
+	dropped := 0
+
+	for _, invocation := range schedule {
+		minute := int(invocation.Offset / (60 * common.OneSecondInMicroseconds))
+		if invocation.Offset < 0 || minute >= p.duration {
+			dropped++
+			continue
+		}
+
+		function, ok := functionByName[invocation.Function]
+		if !ok {
+			function = &common.Function{
+				Name: invocation.Function,
+
+				InvocationStats: &common.FunctionInvocationStats{
+					HashFunction: invocation.Function,
+					Invocations:  make([]int, p.duration),
+				},
+				RuntimeStats: &common.FunctionRuntimeStats{HashFunction: invocation.Function},
+				MemoryStats:  &common.FunctionMemoryStats{HashFunction: invocation.Function},
+
+				Specification: &common.FunctionSpecification{
+					PerMinuteCount: make([]int, p.duration),
+				},
+			}
+
+			functionByName[invocation.Function] = function
+			result = append(result, function)
+		}
+
+		spec := function.Specification
+		spec.IAT = append(spec.IAT, float64(invocation.Offset-previousOffset[invocation.Function]))
+		spec.PerMinuteCount[minute]++
+		spec.RuntimeSpecification = append(spec.RuntimeSpecification, common.RuntimeSpecification{
+			Runtime: invocation.Runtime,
+			Memory:  invocation.Memory,
+		})
+		previousOffset[invocation.Function] = invocation.Offset
+
+		function.InvocationStats.Invocations[minute]++
+		function.RuntimeStats.Count++
+		function.RuntimeStats.Average += (float64(invocation.Runtime) - function.RuntimeStats.Average) / function.RuntimeStats.Count
+		function.RuntimeStats.Maximum = max(function.RuntimeStats.Maximum, float64(invocation.Runtime))
+		function.RuntimeStats.Percentile100 = function.RuntimeStats.Maximum
+		function.MemoryStats.Count++
+		function.MemoryStats.Average += (float64(invocation.Memory) - function.MemoryStats.Average) / function.MemoryStats.Count
+		function.MemoryStats.Percentile100 = max(function.MemoryStats.Percentile100, float64(invocation.Memory))
+	}
+
+	for _, function := range result {
+		function.ColdStartBusyLoopMs = generator.ComputeBusyLoopPeriod(int(function.MemoryStats.Percentile100))
+	}
+
+	if dropped > 0 {
+		log.Warnf("%d invocations of the replay schedule fall outside of the experiment duration and have been dropped.", dropped)
+	}
+
+	return result
+}
@@ -0,0 +1,107 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2023 EASL and the vHive community
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package trace
+
+import (
+	"testing"
+
+	"github.com/vhive-serverless/loader/pkg/common"
+)
+
+func TestReplayParser(t *testing.T) {
+	tests := []struct {
+		testName          string
+		path              string
+		duration          int
+		expectedFunctions []string
+		expectedIAT       [][]float64
+		expectedPerMinute [][]int
+		expectedSpecs     [][]common.RuntimeSpecification
+	}{
+		{
+			testName:          "schedule_file",
+			path:              "test_data/replay_schedule.csv",
+			duration:          2,
+			expectedFunctions: []string{"f1", "f2"},
+			expectedIAT:       [][]float64{{0, 1_000_000, 60_000_000}, {500_000}},
+			expectedPerMinute: [][]int{{2, 1}, {1, 0}},
+			expectedSpecs: [][]common.RuntimeSpecification{
+				{{Runtime: 100, Memory: 128}, {Runtime: 150, Memory: 128}, {Runtime: 100, Memory: 256}},
+				{{Runtime: 200, Memory: 256}},
+			},
+		},
+		{
+			testName:          "duration_file",
+			path:              "test_data/replay_duration.csv",
+			duration:          1,
+			expectedFunctions: []string{"f1", "f2"},
+			expectedIAT:       [][]float64{{0, 1_000_000}, {500_000}},
+			expectedPerMinute: [][]int{{2}, {1}},
+			expectedSpecs: [][]common.RuntimeSpecification{
+				{{Runtime: 100, Memory: 128}, {Runtime: 150, Memory: 128}},
+				{{Runtime: 200, Memory: 256}},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.testName, func(t *testing.T) {
+			functions := NewReplayParser(test.path, test.duration).Parse()
+
+			if len(functions) != len(test.expectedFunctions) {
+				t.Fatalf("Unexpected number of functions - got %d, expected %d.", len(functions), len(test.expectedFunctions))
+			}
+
+			for i, function := range functions {
+				spec := function.Specification
+
+				if function.Name != test.expectedFunctions[i] {
+					t.Errorf("Unexpected function name - got %s, expected %s.", function.Name, test.expectedFunctions[i])
+				}
+
+				if len(spec.IAT) != len(test.expectedIAT[i]) {
+					t.Fatalf("Unexpected IATs of function %s - %v.", function.Name, spec.IAT)
+				}
+				for j := range spec.IAT {
+					if !floatEqual(spec.IAT[j], test.expectedIAT[i][j]) {
+						t.Errorf("Unexpected IATs of function %s - %v.", function.Name, spec.IAT)
+					}
+				}
+
+				for j := range spec.PerMinuteCount {
+					if spec.PerMinuteCount[j] != test.expectedPerMinute[i][j] || function.InvocationStats.Invocations[j] != test.expectedPerMinute[i][j] {
+						t.Errorf("Unexpected per-minute count of function %s - %v.", function.Name, spec.PerMinuteCount)
+					}
+				}
+
+				for j := range spec.RuntimeSpecification {
+					if spec.RuntimeSpecification[j] != test.expectedSpecs[i][j] {
+						t.Errorf("Unexpected runtime specification of function %s - %v.", function.Name, spec.RuntimeSpecification)
+					}
+				}
+			}
+		})
+	}
+}
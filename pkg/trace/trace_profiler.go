@@ -57,6 +57,34 @@ func ApplyResourceLimits(functions []*common.Function, CPULimit string) {
 	}
 }
 
+// ApplyPayloadSizes overrides the payload sizes read from the trace with the ones from the configuration. An entry
+// without HashFunction is the default for the functions that have no payload sizes otherwise.
+func ApplyPayloadSizes(functions []*common.Function, payloadSizes []common.FunctionPayloadStats) {
+	var defaultSizes *common.FunctionPayloadStats
+	byHashFunction := make(map[string]*common.FunctionPayloadStats)
+
+	for i := 0; i < len(payloadSizes); i++ {
+		if payloadSizes[i].HashFunction == "" {
+			defaultSizes = &payloadSizes[i]
+		} else {
+			byHashFunction[payloadSizes[i].HashFunction] = &payloadSizes[i]
+		}
+	}
+
+	for _, function := range functions {
+		if function.InvocationStats != nil {
+			if sizes, ok := byHashFunction[function.InvocationStats.HashFunction]; ok {
+				function.PayloadStats = sizes
+				continue
+			}
+		}
+
+		if function.PayloadStats == nil {
+			function.PayloadStats = defaultSizes
+		}
+	}
+}
+
 // ConvertMemoryToCpu Google Cloud Function conversion table used from https://cloud.google.com/functions/pricing
 func ConvertMemoryToCpu(memoryRequest int) int {
 	var cpuRequest float32
@@ -108,3 +108,25 @@ func TestStaticTraceProfiling(t *testing.T) {
 		})
 	}
 }
+
+func TestApplyPayloadSizes(t *testing.T) {
+	fromTrace := &common.FunctionPayloadStats{HashFunction: "traced", RequestPercentile100: 1}
+
+	functions := []*common.Function{
+		{Name: "overridden", InvocationStats: &common.FunctionInvocationStats{HashFunction: "overridden"}},
+		{Name: "traced", InvocationStats: &common.FunctionInvocationStats{HashFunction: "traced"}, PayloadStats: fromTrace},
+		{Name: "default", InvocationStats: &common.FunctionInvocationStats{HashFunction: "default"}},
+	}
+
+	ApplyPayloadSizes(functions, []common.FunctionPayloadStats{
+		{RequestPercentile100: 2},
+		{HashFunction: "overridden", RequestPercentile100: 3},
+	})
+
+	if functions[0].PayloadStats.RequestPercentile100 != 3 ||
+		functions[1].PayloadStats != fromTrace ||
+		functions[2].PayloadStats.RequestPercentile100 != 2 {
+
+		t.Error("Unexpected payload sizes applied.")
+	}
+}
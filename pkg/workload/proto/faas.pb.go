@@ -24,6 +24,8 @@ type FaasRequest struct {
 	Message              string   `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
 	RuntimeInMilliSec    uint32   `protobuf:"varint,2,opt,name=runtimeInMilliSec,proto3" json:"runtimeInMilliSec,omitempty"`
 	MemoryInMebiBytes    uint32   `protobuf:"varint,3,opt,name=memoryInMebiBytes,proto3" json:"memoryInMebiBytes,omitempty"`
+	Payload              []byte   `protobuf:"bytes,4,opt,name=payload,proto3" json:"payload,omitempty"`
+	ResponseSizeInBytes  uint32   `protobuf:"varint,5,opt,name=responseSizeInBytes,proto3" json:"responseSizeInBytes,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -75,10 +77,27 @@ func (m *FaasRequest) GetMemoryInMebiBytes() uint32 {
 	return 0
 }
 
+func (m *FaasRequest) GetPayload() []byte {
+	if m != nil {
+		return m.Payload
+	}
+	return nil
+}
+
+func (m *FaasRequest) GetResponseSizeInBytes() uint32 {
+	if m != nil {
+		return m.ResponseSizeInBytes
+	}
+	return 0
+}
+
 type FaasReply struct {
 	Message              string   `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
 	DurationInMicroSec   uint32   `protobuf:"varint,2,opt,name=durationInMicroSec,proto3" json:"durationInMicroSec,omitempty"`
 	MemoryUsageInKb      uint32   `protobuf:"varint,3,opt,name=memoryUsageInKb,proto3" json:"memoryUsageInKb,omitempty"`
+	Payload              []byte   `protobuf:"bytes,4,opt,name=payload,proto3" json:"payload,omitempty"`
+	FirstRequest         bool     `protobuf:"varint,5,opt,name=firstRequest,proto3" json:"firstRequest,omitempty"`
+	UptimeInMicroSec     uint64   `protobuf:"varint,6,opt,name=uptimeInMicroSec,proto3" json:"uptimeInMicroSec,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -130,6 +149,27 @@ func (m *FaasReply) GetMemoryUsageInKb() uint32 {
 	return 0
 }
 
+func (m *FaasReply) GetPayload() []byte {
+	if m != nil {
+		return m.Payload
+	}
+	return nil
+}
+
+func (m *FaasReply) GetFirstRequest() bool {
+	if m != nil {
+		return m.FirstRequest
+	}
+	return false
+}
+
+func (m *FaasReply) GetUptimeInMicroSec() uint64 {
+	if m != nil {
+		return m.UptimeInMicroSec
+	}
+	return 0
+}
+
 func init() {
 	proto.RegisterType((*FaasRequest)(nil), "faas.FaasRequest")
 	proto.RegisterType((*FaasReply)(nil), "faas.FaasReply")
@@ -138,21 +178,26 @@ func init() {
 func init() { proto.RegisterFile("server/faas.proto", fileDescriptor_4886c8193ee7bbe7) }
 
 var fileDescriptor_4886c8193ee7bbe7 = []byte{
-	// 256 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x7c, 0x90, 0xbf, 0x4b, 0xc3, 0x40,
-	0x14, 0xc7, 0x8d, 0x8a, 0xb5, 0x4f, 0xa4, 0xe4, 0xa6, 0xe0, 0x54, 0xe3, 0x92, 0x41, 0x73, 0xa0,
-	0xa3, 0x5b, 0x41, 0x21, 0x48, 0x97, 0x88, 0x8b, 0xdb, 0x25, 0x7d, 0xb6, 0x07, 0xf7, 0x23, 0xbe,
-	0xbb, 0x13, 0x33, 0x39, 0xf8, 0x8f, 0x4b, 0x7a, 0x8a, 0xc5, 0x4a, 0xb7, 0x77, 0xef, 0xfb, 0x8e,
-	0xef, 0x87, 0x0f, 0xa4, 0x0e, 0xe9, 0x0d, 0x89, 0xbf, 0x08, 0xe1, 0xca, 0x8e, 0xac, 0xb7, 0xec,
-	0x70, 0x98, 0xf3, 0xcf, 0x04, 0x4e, 0xee, 0x85, 0x70, 0x35, 0xbe, 0x06, 0x74, 0x9e, 0x65, 0x30,
-	0xd2, 0xe8, 0x9c, 0x58, 0x62, 0x96, 0x4c, 0x93, 0x62, 0x5c, 0xff, 0x3c, 0xd9, 0x25, 0xa4, 0x14,
-	0x8c, 0x97, 0x1a, 0x2b, 0x33, 0x97, 0x4a, 0xc9, 0x47, 0x6c, 0xb3, 0xfd, 0x69, 0x52, 0x9c, 0xd6,
-	0xdb, 0xc1, 0x70, 0xad, 0x51, 0x5b, 0xea, 0x2b, 0x33, 0xc7, 0x46, 0xce, 0x7a, 0x8f, 0x2e, 0x3b,
-	0x88, 0xd7, 0x5b, 0x41, 0xfe, 0x01, 0xe3, 0x08, 0xd1, 0xa9, 0x7e, 0x07, 0x42, 0x09, 0x6c, 0x11,
-	0x48, 0x78, 0x69, 0xcd, 0x50, 0xd5, 0x92, 0xfd, 0x65, 0xf8, 0x27, 0x61, 0x05, 0x4c, 0x62, 0xd7,
-	0xd3, 0xf0, 0xbd, 0x32, 0x0f, 0xcd, 0x37, 0xc2, 0xdf, 0xf5, 0xf5, 0x2d, 0x1c, 0xdf, 0xbd, 0x63,
-	0x1b, 0xbc, 0x25, 0xc6, 0x61, 0x14, 0x67, 0x64, 0x69, 0xb9, 0x16, 0xb6, 0x21, 0xe8, 0x6c, 0xb2,
-	0xb9, 0xea, 0x54, 0x9f, 0xef, 0xcd, 0x2e, 0x9e, 0xcf, 0x97, 0xd2, 0xaf, 0x42, 0x53, 0xb6, 0x56,
-	0x73, 0xf4, 0xab, 0x2b, 0x14, 0x4e, 0x71, 0x65, 0xc5, 0x02, 0x89, 0x47, 0xf3, 0xcd, 0xd1, 0xda,
-	0xfa, 0xcd, 0x57, 0x00, 0x00, 0x00, 0xff, 0xff, 0x29, 0xf8, 0x44, 0xf1, 0x8a, 0x01, 0x00, 0x00,
+	// 323 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x7c, 0x52, 0xcd, 0x4e, 0xf3, 0x30,
+	0x10, 0xfc, 0xfc, 0x51, 0xfa, 0xb3, 0x14, 0x95, 0x9a, 0x4b, 0xc4, 0x29, 0x84, 0x4b, 0x84, 0x20,
+	0x41, 0x70, 0xe4, 0x56, 0x09, 0xa4, 0x08, 0xf5, 0x92, 0x8a, 0x0b, 0x37, 0x27, 0xdd, 0xb6, 0x96,
+	0x12, 0x3b, 0xd8, 0x0e, 0x22, 0xbc, 0x24, 0xcf, 0xc1, 0x5b, 0xa0, 0xc4, 0xad, 0x28, 0xa4, 0xea,
+	0x6d, 0x77, 0x66, 0xbd, 0x9e, 0xd9, 0x5d, 0x18, 0x6b, 0x54, 0x6f, 0xa8, 0xc2, 0x05, 0x63, 0x3a,
+	0x28, 0x94, 0x34, 0x92, 0x76, 0xea, 0xd8, 0xfb, 0x24, 0x70, 0xf4, 0xc8, 0x98, 0x8e, 0xf1, 0xb5,
+	0x44, 0x6d, 0xa8, 0x03, 0xbd, 0x1c, 0xb5, 0x66, 0x4b, 0x74, 0x88, 0x4b, 0xfc, 0x41, 0xbc, 0x49,
+	0xe9, 0x15, 0x8c, 0x55, 0x29, 0x0c, 0xcf, 0x31, 0x12, 0x53, 0x9e, 0x65, 0x7c, 0x86, 0xa9, 0xf3,
+	0xdf, 0x25, 0xfe, 0x71, 0xdc, 0x26, 0xea, 0xea, 0x1c, 0x73, 0xa9, 0xaa, 0x48, 0x4c, 0x31, 0xe1,
+	0x93, 0xca, 0xa0, 0x76, 0x0e, 0x6c, 0x75, 0x8b, 0xa8, 0x7f, 0x2d, 0x58, 0x95, 0x49, 0x36, 0x77,
+	0x3a, 0x2e, 0xf1, 0x87, 0xf1, 0x26, 0xa5, 0x37, 0x70, 0xaa, 0x50, 0x17, 0x52, 0x68, 0x9c, 0xf1,
+	0x0f, 0x8c, 0x84, 0xed, 0x74, 0xd8, 0x74, 0xda, 0x45, 0x79, 0x5f, 0x04, 0x06, 0xd6, 0x51, 0x91,
+	0x55, 0x7b, 0xfc, 0x04, 0x40, 0xe7, 0xa5, 0x62, 0x86, 0x4b, 0x51, 0xeb, 0x4e, 0x95, 0xfc, 0x31,
+	0xb4, 0x83, 0xa1, 0x3e, 0x8c, 0xac, 0xf0, 0xe7, 0xfa, 0x79, 0x24, 0x9e, 0x92, 0xb5, 0x9f, 0xbf,
+	0xf0, 0x1e, 0x37, 0x1e, 0x0c, 0x17, 0x5c, 0x69, 0xb3, 0x9e, 0x76, 0x63, 0xa3, 0x1f, 0xff, 0xc2,
+	0xe8, 0x25, 0x9c, 0x94, 0xc5, 0x66, 0x9a, 0x6b, 0x55, 0x5d, 0x97, 0xf8, 0x9d, 0xb8, 0x85, 0xdf,
+	0xde, 0x43, 0xff, 0xe1, 0x1d, 0xd3, 0xd2, 0x48, 0x45, 0x43, 0xe8, 0xd9, 0x18, 0xe9, 0x38, 0x68,
+	0xf6, 0xbc, 0xb5, 0xd7, 0xb3, 0xd1, 0x36, 0x54, 0x64, 0x95, 0xf7, 0x6f, 0x72, 0xf1, 0x72, 0xbe,
+	0xe4, 0x66, 0x55, 0x26, 0x41, 0x2a, 0xf3, 0x10, 0xcd, 0xea, 0x1a, 0x99, 0xce, 0xc2, 0x5a, 0x28,
+	0xaa, 0xd0, 0x1e, 0x4c, 0xd2, 0x6d, 0x8e, 0xe5, 0xee, 0x7b, 0x00, 0xd2, 0x25, 0xfb, 0xc4, 0x41,
+	0x02, 0x00, 0x00,
 }
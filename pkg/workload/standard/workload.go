@@ -26,12 +26,18 @@ package standard
 
 import "C"
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
 	"net"
+	"net/http"
 	"os"
 	"os/signal"
 	"strconv"
+	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 
@@ -45,7 +51,7 @@ import (
 
 var hostname string
 var IterationsMultiplier int
-var serverSideCode FunctionType
+var readEnvironmentOnce sync.Once
 
 type FunctionType int
 
@@ -54,18 +60,64 @@ const (
 	EmptyFunction FunctionType = 1
 )
 
+// instanceState tracks the lifetime of the instance, so that the loader can tell cold starts from warm ones
+type instanceState struct {
+	startTime time.Time
+	served    atomic.Bool
+}
+
+func newInstanceState() *instanceState {
+	return &instanceState{startTime: time.Now()}
+}
+
+// arrival reports whether the invocation is the first one served by the instance, and the uptime of the instance in
+// microseconds when the invocation has arrived
+func (i *instanceState) arrival() (bool, uint64) {
+	return !i.served.Swap(true), uint64(time.Since(i.startTime).Microseconds())
+}
+
 type funcServer struct {
 	proto.UnimplementedExecutorServer
+
+	functionType FunctionType
+	instance     *instanceState
+}
+
+func newFuncServer(functionType FunctionType) *funcServer {
+	return &funcServer{
+		functionType: functionType,
+		instance:     newInstanceState(),
+	}
 }
 
 func (s *funcServer) Execute(_ context.Context, req *proto.FaasRequest) (*proto.FaasReply, error) {
+	firstRequest, uptime := s.instance.arrival()
+	msg, duration := execute(s.functionType, req.RuntimeInMilliSec)
+
+	return &proto.FaasReply{
+		Message:            msg,
+		DurationInMicroSec: duration,
+		MemoryUsageInKb:    req.MemoryInMebiBytes * 1024,
+		Payload:            responsePayload(int(req.ResponseSizeInBytes)),
+		FirstRequest:       firstRequest,
+		UptimeInMicroSec:   uptime,
+	}, nil
+}
+
+// responsePayload returns a payload of the size requested by the loader, capped at the maximum payload size
+func responsePayload(size int) []byte {
+	return bytes.Repeat([]byte{'x'}, util.MinOf(util.MaxOf(size, 0), util.MaxPayloadSizeBytes))
+}
+
+// execute runs the function and returns its message and execution time in microseconds
+func execute(functionType FunctionType, runtimeMilliseconds uint32) (string, uint32) {
 	var msg string
 	start := time.Now()
 
-	if serverSideCode == TraceFunction {
+	if functionType == TraceFunction {
 		// Minimum execution time is AWS billing granularity - 1ms,
 		// as defined in SpecificationGenerator::generateExecutionSpecs
-		timeLeftMilliseconds := req.RuntimeInMilliSec
+		timeLeftMilliseconds := runtimeMilliseconds
 		/*toAllocate := util.Mib2b(req.MemoryInMebiBytes - ContainerImageSizeMB)
 		if toAllocate < 0 {
 			toAllocate = 0
@@ -81,11 +133,7 @@ func (s *funcServer) Execute(_ context.Context, req *proto.FaasRequest) (*proto.
 		msg = fmt.Sprintf("OK - EMPTY - %s", hostname)
 	}
 
-	return &proto.FaasReply{
-		Message:            msg,
-		DurationInMicroSec: uint32(time.Since(start).Microseconds()),
-		MemoryUsageInKb:    req.MemoryInMebiBytes * 1024,
-	}, nil
+	return msg, uint32(time.Since(start).Microseconds())
 }
 
 func readEnvironmentalVariables() {
@@ -108,7 +156,6 @@ func readEnvironmentalVariables() {
 
 func StartGRPCServer(serverAddress string, serverPort int, functionType FunctionType, zipkinUrl string) {
 	readEnvironmentalVariables()
-	serverSideCode = functionType
 
 	if tracing.IsTracingEnabled() {
 		log.Infof("Zipkin URL: %s\n", zipkinUrl)
@@ -141,7 +188,79 @@ func StartGRPCServer(serverAddress string, serverPort int, functionType Function
 	}()
 
 	reflection.Register(grpcServer) // gRPC Server Reflection is used by gRPC CLI
-	proto.RegisterExecutorServer(grpcServer, &funcServer{})
+	proto.RegisterExecutorServer(grpcServer, newFuncServer(functionType))
 	err = grpcServer.Serve(lis)
 	util.Check(err)
 }
+
+// NewGRPCServer returns a gRPC server running the function, which the caller has to start serving
+func NewGRPCServer(functionType FunctionType) *grpc.Server {
+	readEnvironmentOnce.Do(readEnvironmentalVariables)
+
+	grpcServer := grpc.NewServer()
+	reflection.Register(grpcServer)
+	proto.RegisterExecutorServer(grpcServer, newFuncServer(functionType))
+
+	return grpcServer
+}
+
+// httpReply mirrors the response the HTTP client of the loader expects from Dirigent
+type httpReply struct {
+	Status        string `json:"Status"`
+	Function      string `json:"Function"`
+	MachineName   string `json:"MachineName"`
+	ExecutionTime int64  `json:"ExecutionTime"`
+	FirstRequest  bool   `json:"FirstRequest"`
+	Uptime        int64  `json:"Uptime"`
+	Payload       string `json:"Payload,omitempty"`
+}
+
+// NewHTTPHandler returns an HTTP handler running the function, reading the runtime and memory from the headers set
+// by the HTTP client of the loader. The instance name is reported back to the loader in the response, padded to the
+// size requested in the response_size header.
+func NewHTTPHandler(functionType FunctionType, instanceName string) http.Handler {
+	readEnvironmentOnce.Do(readEnvironmentalVariables)
+	instance := newInstanceState()
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		runtime, err := strconv.Atoi(r.Header.Get("requested_cpu"))
+		if err != nil || runtime < 0 {
+			http.Error(w, "Invalid requested_cpu header.", http.StatusBadRequest)
+			return
+		}
+
+		responseSize := 0
+		if header := r.Header.Get("response_size"); header != "" {
+			if responseSize, err = strconv.Atoi(header); err != nil {
+				http.Error(w, "Invalid response_size header.", http.StatusBadRequest)
+				return
+			}
+		}
+
+		// the request payload models the input of the function, which is read but not used
+		if _, err = io.Copy(io.Discard, r.Body); err != nil {
+			http.Error(w, "Failed to read the request body.", http.StatusBadRequest)
+			return
+		}
+
+		firstRequest, uptime := instance.arrival()
+		msg, duration := execute(functionType, uint32(runtime))
+		if msg == "" {
+			msg = "OK"
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		err = json.NewEncoder(w).Encode(httpReply{
+			Status:        msg,
+			Function:      instanceName,
+			MachineName:   hostname,
+			ExecutionTime: int64(duration),
+			FirstRequest:  firstRequest,
+			Uptime:        int64(uptime),
+			Payload:       string(responsePayload(responseSize)),
+		})
+		if err != nil {
+			log.Warnf("Failed to write the HTTP response - %v", err)
+		}
+	})
+}
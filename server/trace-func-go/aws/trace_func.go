@@ -41,11 +41,19 @@ import (
 // https://serverless.com/framework/docs/providers/aws/events/apigateway/#lambda-proxy-integration
 type Response events.APIGatewayProxyResponse
 
+// instanceStart and served let the loader tell cold starts apart, as Lambda runs a single invocation per instance at once
+var (
+	instanceStart = time.Now()
+	served        bool
+)
+
 // Handler is our lambda handler invoked by the `lambda.Start` function call
 func Handler(_ context.Context, event events.LambdaFunctionURLRequest) (Response, error) {
 	var buf bytes.Buffer
 
 	start := time.Now()
+	firstRequest, uptime := !served, start.Sub(instanceStart).Microseconds()
+	served = true
 
 	// Obtain payload from the request
 	var req struct {
@@ -64,6 +72,8 @@ func Handler(_ context.Context, event events.LambdaFunctionURLRequest) (Response
 	body, err := json.Marshal(map[string]interface{}{
 		"DurationInMicroSec": uint32(time.Since(start).Microseconds()),
 		"MemoryUsageInKb":    req.MemoryInMebiBytes * 1024,
+		"FirstRequest":       firstRequest,
+		"Uptime":             uptime,
 	})
 	if err != nil {
 		return Response{StatusCode: 400}, err